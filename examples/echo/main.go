@@ -48,7 +48,8 @@ func main() {
 
 	// 2. Create a Migrator using the plain *sql.DB.
 	//    go-migration has zero Echo-specific dependencies.
-	m := migrator.New(db)
+	//    WithLocker serializes Up() across replicas that all migrate on boot.
+	m := migrator.New(db, migrator.WithLocker(migrator.NewPostgresLocker(db, "migrations")))
 	_ = m.Register("20240201120000_create_posts", &CreatePostsTable{})
 
 	// 3. Run pending migrations.
@@ -47,7 +47,8 @@ func main() {
 
 	// 2. Create a Migrator using the plain *sql.DB.
 	//    go-migration has zero Fiber-specific dependencies.
-	m := migrator.New(db)
+	//    WithLocker serializes Up() across replicas that all migrate on boot.
+	m := migrator.New(db, migrator.WithLocker(migrator.NewPostgresLocker(db, "migrations")))
 	_ = m.Register("20240301120000_create_orders", &CreateOrdersTable{})
 
 	// 3. Run pending migrations.
@@ -48,7 +48,8 @@ func main() {
 
 	// 2. Create a Migrator using the plain *sql.DB.
 	//    This is identical to framework-based usage — no adapters needed.
-	m := migrator.New(db)
+	//    WithLocker serializes Up() across replicas that all migrate on boot.
+	m := migrator.New(db, migrator.WithLocker(migrator.NewPostgresLocker(db, "migrations")))
 	_ = m.Register("20240401120000_create_products", &CreateProductsTable{})
 
 	// 3. Run pending migrations.
@@ -0,0 +1,265 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// AutoMigration generates a migration file whose Up applies diff and whose
+// Down reverses it, and returns the full filepath. Warnings in the diff are
+// written as comments at the top of Up.
+func (g *Generator) AutoMigration(description string, diff *schema.SchemaDiff) (string, error) {
+	filename, migrationName := g.migrationFilename(description)
+
+	content, err := templateFS.ReadFile("templates/migration_auto.go.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("read auto migration template: %w", err)
+	}
+
+	tmpl, err := template.New("migration_auto.go.tmpl").Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("parse auto migration template: %w", err)
+	}
+
+	data := templateData{
+		StructName:    toStructName(description),
+		MigrationName: migrationName,
+		Up:            renderUp(diff),
+		Down:          renderDown(diff),
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("format generated migration: %w", err)
+	}
+
+	if err := os.MkdirAll(g.outputDir, 0o755); err != nil {
+		return "", fmt.Errorf("create output dir: %w", err)
+	}
+	outPath := filepath.Join(g.outputDir, filename)
+	if err := os.WriteFile(outPath, src, 0o644); err != nil {
+		return "", fmt.Errorf("create file %s: %w", outPath, err)
+	}
+
+	return outPath, nil
+}
+
+// renderUp renders the Builder calls that apply diff.
+func renderUp(diff *schema.SchemaDiff) string {
+	var b strings.Builder
+	for _, w := range diff.Warnings {
+		fmt.Fprintf(&b, "\t// Warning: %s\n", w)
+	}
+	if len(diff.Warnings) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, td := range diff.Tables {
+		if td.Create != nil {
+			var body []string
+			for _, col := range td.Create.Columns() {
+				body = append(body, renderColumn(col))
+			}
+			for _, idx := range td.Create.Indexes() {
+				body = append(body, renderIndex(td.Table, idx))
+			}
+			for _, fk := range td.Create.ForeignKeys() {
+				body = append(body, renderForeignKey(td.Table, fk))
+			}
+			writeCall(&b, "Create", td.Table, body)
+			continue
+		}
+
+		var body []string
+		for _, fk := range td.DropForeignKeys {
+			body = append(body, fmt.Sprintf("bp.DropForeign(%q)", fk.Name))
+		}
+		for _, idx := range td.DropIndexes {
+			body = append(body, fmt.Sprintf("bp.DropIndex(%q)", idx.Name))
+		}
+		for _, col := range td.DropColumns {
+			body = append(body, fmt.Sprintf("bp.DropColumn(%q)", col.Name))
+		}
+		for _, change := range td.ChangeColumns {
+			body = append(body, renderColumn(change.To))
+		}
+		for _, col := range td.AddColumns {
+			body = append(body, renderColumn(col))
+		}
+		for _, idx := range td.AddIndexes {
+			body = append(body, renderIndex(td.Table, idx))
+		}
+		for _, fk := range td.AddForeignKeys {
+			body = append(body, renderForeignKey(td.Table, fk))
+		}
+		writeCall(&b, "Alter", td.Table, body)
+	}
+	return b.String()
+}
+
+// renderDown renders the Builder calls that reverse diff, undoing the
+// tables in reverse order.
+func renderDown(diff *schema.SchemaDiff) string {
+	var b strings.Builder
+	for i := len(diff.Tables) - 1; i >= 0; i-- {
+		td := diff.Tables[i]
+		if td.Create != nil {
+			fmt.Fprintf(&b, "\tif err := s.DropIfExists(%q); err != nil {\n\t\treturn err\n\t}\n", td.Table)
+			continue
+		}
+
+		var body []string
+		for _, fk := range td.AddForeignKeys {
+			body = append(body, fmt.Sprintf("bp.DropForeign(%q)", fk.Name))
+		}
+		for _, idx := range td.AddIndexes {
+			body = append(body, fmt.Sprintf("bp.DropIndex(%q)", idx.Name))
+		}
+		for _, col := range td.AddColumns {
+			body = append(body, fmt.Sprintf("bp.DropColumn(%q)", col.Name))
+		}
+		for _, change := range td.ChangeColumns {
+			body = append(body, renderColumn(change.From))
+		}
+		for _, col := range td.DropColumns {
+			body = append(body, renderColumn(col))
+		}
+		for _, idx := range td.DropIndexes {
+			body = append(body, renderIndex(td.Table, idx))
+		}
+		for _, fk := range td.DropForeignKeys {
+			body = append(body, renderForeignKey(td.Table, fk))
+		}
+		writeCall(&b, "Alter", td.Table, body)
+	}
+	return b.String()
+}
+
+// writeCall writes a Create or Alter call whose error is returned.
+func writeCall(b *strings.Builder, method, table string, body []string) {
+	fmt.Fprintf(b, "\tif err := s.%s(%q, func(bp *schema.Blueprint) {\n", method, table)
+	for _, line := range body {
+		fmt.Fprintf(b, "\t\t%s\n", line)
+	}
+	b.WriteString("\t}); err != nil {\n\t\treturn err\n\t}\n")
+}
+
+// renderColumn renders the Blueprint call that declares col.
+func renderColumn(col schema.ColumnDefinition) string {
+	if col.Name == "id" && col.Type == schema.TypeBigInteger && col.IsPrimary &&
+		col.IsAutoIncrement && col.IsUnsigned && !col.IsNullable && !col.IsUnique && col.DefaultValue == nil {
+		return "bp.ID()"
+	}
+
+	var call string
+	switch col.Type {
+	case schema.TypeString:
+		call = fmt.Sprintf("bp.String(%q, %d)", col.Name, col.Length)
+	case schema.TypeChar:
+		call = fmt.Sprintf("bp.Char(%q, %d)", col.Name, col.Length)
+	case schema.TypeDecimal:
+		call = fmt.Sprintf("bp.Decimal(%q, %d, %d)", col.Name, col.Precision, col.Scale)
+	case schema.TypeEnum:
+		call = fmt.Sprintf("bp.Enum(%q, %#v)", col.Name, col.AllowedValues)
+	default:
+		call = fmt.Sprintf("bp.%s(%q)", columnMethods[col.Type], col.Name)
+	}
+
+	if col.IsNullable {
+		call += ".Nullable()"
+	}
+	switch v := col.DefaultValue.(type) {
+	case nil:
+	case schema.RawExpression:
+		call += fmt.Sprintf(".DefaultRaw(%q)", v.Expression)
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		call += fmt.Sprintf(".Default(%#v)", v)
+	default:
+		call += fmt.Sprintf(".Default(%q)", fmt.Sprint(v))
+	}
+	if col.IsPrimary {
+		call += ".Primary()"
+	}
+	if col.IsUnique {
+		call += ".Unique()"
+	}
+	if col.IsUnsigned {
+		call += ".Unsigned()"
+	}
+	if col.IsAutoIncrement {
+		call += ".AutoIncrement()"
+	}
+	if col.IsChange {
+		call += ".Change()"
+	}
+	return call
+}
+
+// columnMethods maps the column types that take only a name to their
+// Blueprint method.
+var columnMethods = map[schema.ColumnType]string{
+	schema.TypeText:       "Text",
+	schema.TypeInteger:    "Integer",
+	schema.TypeBigInteger: "BigInteger",
+	schema.TypeBoolean:    "Boolean",
+	schema.TypeTimestamp:  "Timestamp",
+	schema.TypeDate:       "Date",
+	schema.TypeFloat:      "Float",
+	schema.TypeUUID:       "UUID",
+	schema.TypeJSON:       "JSON",
+	schema.TypeBinary:     "Binary",
+	schema.TypeLongText:   "LongText",
+	schema.TypeMediumText: "MediumText",
+	schema.TypeTinyInt:    "TinyInt",
+	schema.TypeSmallInt:   "SmallInt",
+}
+
+// indexMethods maps index types to their Blueprint method and name prefix.
+var indexMethods = map[schema.IndexType][2]string{
+	schema.IndexRegular:  {"Index", "idx"},
+	schema.IndexUnique:   {"UniqueIndex", "uniq"},
+	schema.IndexFulltext: {"FulltextIndex", "ft"},
+	schema.IndexSpatial:  {"SpatialIndex", "sp"},
+}
+
+// renderIndex renders the Blueprint call that declares idx, overriding the
+// name when it does not follow the convention.
+func renderIndex(table string, idx schema.IndexDefinition) string {
+	m := indexMethods[idx.Type]
+	quoted := make([]string, len(idx.Columns))
+	for i, c := range idx.Columns {
+		quoted[i] = fmt.Sprintf("%q", c)
+	}
+	call := fmt.Sprintf("bp.%s(%s)", m[0], strings.Join(quoted, ", "))
+	if idx.Name != m[1]+"_"+table+"_"+strings.Join(idx.Columns, "_") {
+		call += fmt.Sprintf(".Name = %q", idx.Name)
+	}
+	return call
+}
+
+// renderForeignKey renders the Blueprint call that declares fk, overriding
+// the name when it does not follow the convention.
+func renderForeignKey(table string, fk schema.ForeignKeyDefinition) string {
+	call := fmt.Sprintf("bp.Foreign(%q).References(%q).On(%q)", fk.Column, fk.RefColumn, fk.RefTable)
+	if fk.OnDelete != "" {
+		call += fmt.Sprintf(".OnDeleteAction(%q)", fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		call += fmt.Sprintf(".OnUpdateAction(%q)", fk.OnUpdate)
+	}
+	if fk.Name != "fk_"+table+"_"+fk.Column {
+		call += fmt.Sprintf(".Name = %q", fk.Name)
+	}
+	return call
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoMigration(t *testing.T) {
+	g, dir := fixedTimeGenerator(t)
+
+	posts := schema.NewBlueprint("posts")
+	posts.ID()
+	posts.String("title", 200).Default("untitled")
+	posts.BigInteger("user_id")
+	posts.Index("title").Name = "posts_title"
+	posts.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
+
+	diff := &schema.SchemaDiff{
+		Tables: []schema.TableDiff{
+			{Table: "posts", Create: posts},
+			{
+				Table:       "users",
+				AddColumns:  []schema.ColumnDefinition{{Name: "age", Type: schema.TypeInteger, IsNullable: true}},
+				DropColumns: []schema.ColumnDefinition{{Name: "legacy", Type: schema.TypeText, DefaultValue: schema.Raw("'x'")}},
+				DropIndexes: []schema.IndexDefinition{{Name: "uniq_users_legacy", Columns: []string{"legacy"}, Type: schema.IndexUnique}},
+				ChangeColumns: []schema.ColumnChange{{
+					From: schema.ColumnDefinition{Name: "bio", Type: schema.TypeString, Length: 100, IsNullable: true, IsChange: true},
+					To:   schema.ColumnDefinition{Name: "bio", Type: schema.TypeText, IsChange: true},
+				}},
+			},
+		},
+		Warnings: []string{"users.legacy: restored as text"},
+	}
+
+	path, err := g.AutoMigration("sync_schema", diff)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2024_07_15_103045_1234_sync_schema.go"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	src := string(content)
+
+	assert.Contains(t, src, "type SyncSchema struct{}")
+	assert.Contains(t, src, `migrator.AutoRegister("2024_07_15_103045_1234_sync_schema", &SyncSchema{})`)
+	assert.Contains(t, src, "// Warning: users.legacy: restored as text")
+	assert.Contains(t, src, `if err := s.Create("posts", func(bp *schema.Blueprint) {`)
+	assert.Contains(t, src, "\t\tbp.ID()\n")
+	assert.Contains(t, src, `bp.String("title", 200).Default("untitled")`)
+	assert.Contains(t, src, `bp.Index("title").Name = "posts_title"`)
+	assert.Contains(t, src, `bp.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")`)
+	assert.Contains(t, src, `bp.DropIndex("uniq_users_legacy")`)
+	assert.Contains(t, src, `bp.DropColumn("legacy")`)
+	assert.Contains(t, src, `bp.Integer("age").Nullable()`)
+	assert.Contains(t, src, `bp.Text("bio").Change()`)
+
+	// Down restores the dropped structures and undoes the table changes
+	// in reverse order.
+	assert.Contains(t, src, `bp.Text("legacy").DefaultRaw("'x'")`)
+	assert.Contains(t, src, `bp.UniqueIndex("legacy")`)
+	assert.Contains(t, src, `bp.DropColumn("age")`)
+	assert.Contains(t, src, `bp.String("bio", 100).Nullable().Change()`)
+	assert.Contains(t, src, `s.DropIfExists("posts")`)
+	assert.Less(t, strings.Index(src, `s.Alter("users"`), strings.Index(src, `s.DropIfExists("posts")`))
+}
+
+func TestRenderColumn(t *testing.T) {
+	tests := []struct {
+		col  schema.ColumnDefinition
+		want string
+	}{
+		{schema.ColumnDefinition{Name: "id", Type: schema.TypeBigInteger, IsPrimary: true, IsAutoIncrement: true, IsUnsigned: true}, "bp.ID()"},
+		{schema.ColumnDefinition{Name: "price", Type: schema.TypeDecimal, Precision: 8, Scale: 2}, `bp.Decimal("price", 8, 2)`},
+		{schema.ColumnDefinition{Name: "state", Type: schema.TypeEnum, AllowedValues: []string{"a", "b"}}, `bp.Enum("state", []string{"a", "b"})`},
+		{schema.ColumnDefinition{Name: "active", Type: schema.TypeBoolean, DefaultValue: true}, `bp.Boolean("active").Default(true)`},
+		{schema.ColumnDefinition{Name: "code", Type: schema.TypeChar, Length: 3, IsUnique: true}, `bp.Char("code", 3).Unique()`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, renderColumn(tt.col))
+	}
+}
@@ -30,6 +30,8 @@ type templateData struct {
 	StructName    string
 	TableName     string
 	MigrationName string // e.g., "2024_02_15_120405_4827_create_users_table"
+	Up            string // rendered Up body for auto migrations
+	Down          string // rendered Down body for auto migrations
 }
 
 // Generator generates migration and seeder files from templates.
@@ -55,10 +57,7 @@ func NewGenerator(outputDir string) *Generator {
 // The opts parameter controls whether the template includes pre-populated
 // Schema_Builder calls for --create or --table flags.
 func (g *Generator) Migration(description string, opts MigrationOptions) (string, error) {
-	timestamp := g.nowFunc().Format("2006_01_02_150405")
-	random := fmt.Sprintf("%04d", g.randFunc(10000))
-	filename := fmt.Sprintf("%s_%s_%s.go", timestamp, random, description)
-	migrationName := strings.TrimSuffix(filename, ".go")
+	filename, migrationName := g.migrationFilename(description)
 	structName := toStructName(description)
 
 	tmplName := "templates/migration.go.tmpl"
@@ -100,6 +99,15 @@ func (g *Generator) Migration(description string, opts MigrationOptions) (string
 	return outPath, nil
 }
 
+// migrationFilename returns the filename and migration name for a new
+// migration with the given description.
+func (g *Generator) migrationFilename(description string) (string, string) {
+	timestamp := g.nowFunc().Format("2006_01_02_150405")
+	random := fmt.Sprintf("%04d", g.randFunc(10000))
+	filename := fmt.Sprintf("%s_%s_%s.go", timestamp, random, description)
+	return filename, strings.TrimSuffix(filename, ".go")
+}
+
 // Seeder generates a seeder file and returns the full filepath.
 // The filename follows the pattern description_seeder.go.
 func (g *Generator) Seeder(description string) (string, error) {
@@ -64,7 +64,8 @@ type Logger interface {
 	Error(msg string, args ...any)
 }
 
-// ConsoleLogger writes log entries to os.Stdout with level filtering.
+// ConsoleLogger writes log entries to os.Stdout, or another writer, with
+// level filtering.
 type ConsoleLogger struct {
 	level LogLevel
 	out   io.Writer
@@ -77,8 +78,9 @@ func NewConsoleLogger(level LogLevel) *ConsoleLogger {
 	return &ConsoleLogger{level: level, out: os.Stdout}
 }
 
-// newConsoleLoggerWithWriter is an internal constructor used for testing.
-func newConsoleLoggerWithWriter(level LogLevel, w io.Writer) *ConsoleLogger {
+// NewConsoleLoggerTo is like NewConsoleLogger but writes to w, such as
+// os.Stderr when stdout carries the command's output.
+func NewConsoleLoggerTo(level LogLevel, w io.Writer) *ConsoleLogger {
 	return &ConsoleLogger{level: level, out: w}
 }
 
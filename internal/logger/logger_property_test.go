@@ -46,7 +46,7 @@ func TestProperty37_MigrationExecutionLoggedWithRequiredFields(t *testing.T) {
 			result := resultGenerator().Draw(t, "result")
 
 			var buf bytes.Buffer
-			lg := newConsoleLoggerWithWriter(LevelDebug, &buf)
+			lg := NewConsoleLoggerTo(LevelDebug, &buf)
 
 			// Simulate a migration log entry the way the migrator would log it.
 			lg.Info("migration %s %s %s", name, direction, result)
@@ -112,7 +112,7 @@ func TestProperty38_LogLevelFiltering(t *testing.T) {
 			marker := rapid.StringMatching(`[a-z]{8,16}`).Draw(t, "marker")
 
 			var buf bytes.Buffer
-			lg := newConsoleLoggerWithWriter(configuredLevel, &buf)
+			lg := NewConsoleLoggerTo(configuredLevel, &buf)
 
 			logAtLevel(lg, messageLevel, marker)
 
@@ -163,7 +163,7 @@ func TestProperty38_LogLevelFiltering(t *testing.T) {
 			configuredLevel := levelGenerator().Draw(t, "configuredLevel")
 
 			var buf bytes.Buffer
-			lg := newConsoleLoggerWithWriter(configuredLevel, &buf)
+			lg := NewConsoleLoggerTo(configuredLevel, &buf)
 
 			// Log one message at every level with a unique marker per level.
 			markers := make(map[LogLevel]string, len(allLevels))
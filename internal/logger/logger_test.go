@@ -49,7 +49,7 @@ func TestParseLevel(t *testing.T) {
 
 func TestConsoleLoggerOutputsAtCorrectLevels(t *testing.T) {
 	var buf bytes.Buffer
-	cl := newConsoleLoggerWithWriter(LevelInfo, &buf)
+	cl := NewConsoleLoggerTo(LevelInfo, &buf)
 
 	cl.Info("hello %s", "world")
 	output := buf.String()
@@ -59,7 +59,7 @@ func TestConsoleLoggerOutputsAtCorrectLevels(t *testing.T) {
 
 func TestConsoleLoggerLevelFiltering(t *testing.T) {
 	var buf bytes.Buffer
-	cl := newConsoleLoggerWithWriter(LevelInfo, &buf)
+	cl := NewConsoleLoggerTo(LevelInfo, &buf)
 
 	cl.Debug("should be suppressed")
 	assert.Empty(t, buf.String(), "debug message should be suppressed at info level")
@@ -78,7 +78,7 @@ func TestConsoleLoggerLevelFiltering(t *testing.T) {
 
 func TestConsoleLoggerDebugLevel(t *testing.T) {
 	var buf bytes.Buffer
-	cl := newConsoleLoggerWithWriter(LevelDebug, &buf)
+	cl := NewConsoleLoggerTo(LevelDebug, &buf)
 
 	cl.Debug("debug msg")
 	assert.Contains(t, buf.String(), "[DEBUG]")
@@ -87,7 +87,7 @@ func TestConsoleLoggerDebugLevel(t *testing.T) {
 
 func TestConsoleLoggerErrorLevelSuppressesLower(t *testing.T) {
 	var buf bytes.Buffer
-	cl := newConsoleLoggerWithWriter(LevelError, &buf)
+	cl := NewConsoleLoggerTo(LevelError, &buf)
 
 	cl.Debug("no")
 	cl.Info("no")
@@ -100,7 +100,7 @@ func TestConsoleLoggerErrorLevelSuppressesLower(t *testing.T) {
 
 func TestConsoleLoggerFormatIncludesTimestamp(t *testing.T) {
 	var buf bytes.Buffer
-	cl := newConsoleLoggerWithWriter(LevelDebug, &buf)
+	cl := NewConsoleLoggerTo(LevelDebug, &buf)
 
 	cl.Info("test")
 	// RFC3339 timestamps contain "T" and either "Z" or "+"/"-"
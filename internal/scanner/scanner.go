@@ -3,6 +3,8 @@
 package scanner
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -64,3 +66,58 @@ func ScanSeeders(dir string) ([]string, error) {
 	sort.Strings(files)
 	return files, nil
 }
+
+// sqlMigrationPattern matches SQL migration files named
+// <timestamp>_<description>.up.sql or .down.sql, with the timestamp in
+// either YYYYMMDDHHMMSS or YYYY_MM_DD_HHMMSS_RRRR form.
+var sqlMigrationPattern = regexp.MustCompile(`^((?:\d{14}|\d{4}_\d{2}_\d{2}_\d{6}_\d{4})_[a-z][a-z0-9_]*)\.(up|down)\.sql$`)
+
+// SQLMigration is a pair of SQL migration files sharing a migration name.
+// Up and Down are paths within the scanned file system; Down is empty when
+// the migration has no .down.sql file.
+type SQLMigration struct {
+	Name string
+	Up   string
+	Down string
+}
+
+// ScanSQLMigrations scans the root of fsys for <name>.up.sql and
+// <name>.down.sql files and returns them paired by name, sorted by name.
+// A .down.sql file without a matching .up.sql file is an error.
+func ScanSQLMigrations(fsys fs.FS) ([]SQLMigration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*SQLMigration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := sqlMigrationPattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		m, ok := byName[match[1]]
+		if !ok {
+			m = &SQLMigration{Name: match[1]}
+			byName[match[1]] = m
+		}
+		if match[2] == "up" {
+			m.Up = e.Name()
+		} else {
+			m.Down = e.Name()
+		}
+	}
+
+	migrations := make([]SQLMigration, 0, len(byName))
+	for _, m := range byName {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%s: no matching .up.sql file", m.Down)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Name < migrations[j].Name })
+	return migrations, nil
+}
@@ -101,3 +101,36 @@ func TestScanMigrations_SortOrder(t *testing.T) {
 	}
 	assert.Equal(t, expected, files)
 }
+
+func TestScanSQLMigrations(t *testing.T) {
+	dir := t.TempDir()
+
+	touch(t, dir, "2024_05_01_120000_0001_add_index.up.sql")
+	touch(t, dir, "2024_05_01_120000_0001_add_index.down.sql")
+	touch(t, dir, "20240101120000_seed_roles.up.sql")
+	// Not SQL migrations
+	touch(t, dir, "20240101120000_create_users.go")
+	touch(t, dir, "schema.sql")
+	touch(t, dir, "notes.up.sql")
+
+	migrations, err := ScanSQLMigrations(os.DirFS(dir))
+	require.NoError(t, err)
+
+	expected := []SQLMigration{
+		{Name: "20240101120000_seed_roles", Up: "20240101120000_seed_roles.up.sql"},
+		{
+			Name: "2024_05_01_120000_0001_add_index",
+			Up:   "2024_05_01_120000_0001_add_index.up.sql",
+			Down: "2024_05_01_120000_0001_add_index.down.sql",
+		},
+	}
+	assert.Equal(t, expected, migrations)
+}
+
+func TestScanSQLMigrations_DownWithoutUp(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, dir, "20240101120000_orphan.down.sql")
+
+	_, err := ScanSQLMigrations(os.DirFS(dir))
+	assert.ErrorContains(t, err, "no matching .up.sql file")
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+
 	"github.com/andrianprasetya/go-migration/pkg/cli/commands"
 	"github.com/spf13/cobra"
 )
@@ -47,3 +49,12 @@ func (a *App) Run(args []string) error {
 	}
 	return a.root.Execute()
 }
+
+// RunContext is like Run but executes the root command with ctx, which
+// command handlers receive through cmd.Context().
+func (a *App) RunContext(ctx context.Context, args []string) error {
+	if args != nil {
+		a.root.SetArgs(args)
+	}
+	return a.root.ExecuteContext(ctx)
+}
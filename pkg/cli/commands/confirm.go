@@ -3,6 +3,7 @@ package commands
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -10,9 +11,10 @@ import (
 
 // confirm prompts the user with the given message and reads a response from
 // cmd.InOrStdin(). It returns true if the user enters "y" or "yes"
-// (case-insensitive), and false for any other input.
+// (case-insensitive), and false for any other input. With --output=json or
+// yaml the prompt is written to stderr to keep stdout parseable.
 func confirm(cmd *cobra.Command, message string) (bool, error) {
-	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N]: ", message)
+	fmt.Fprintf(promptWriter(cmd), "%s [y/N]: ", message)
 
 	reader := bufio.NewReader(cmd.InOrStdin())
 	line, err := reader.ReadString('\n')
@@ -23,3 +25,12 @@ func confirm(cmd *cobra.Command, message string) (bool, error) {
 	input := strings.TrimSpace(strings.ToLower(line))
 	return input == "y" || input == "yes", nil
 }
+
+// promptWriter returns where prompts are written: stdout, or stderr with
+// --output=json or yaml.
+func promptWriter(cmd *cobra.Command) io.Writer {
+	if format, _ := cmd.Flags().GetString("output"); format == OutputJSON || format == OutputYAML {
+		return cmd.ErrOrStderr()
+	}
+	return cmd.OutOrStdout()
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -12,12 +13,24 @@ import (
 // stubMigrator is a minimal MigratorRunner for tests that need a non-nil Migrator.
 type stubMigrator struct{}
 
-func (stubMigrator) Up() error                              { return nil }
-func (stubMigrator) Rollback(int) error                     { return nil }
-func (stubMigrator) Reset() error                           { return nil }
-func (stubMigrator) Refresh() error                         { return nil }
-func (stubMigrator) Fresh() error                           { return nil }
-func (stubMigrator) Status() ([]MigrationStatusInfo, error) { return nil, nil }
+func (stubMigrator) Up(context.Context) error                              { return nil }
+func (stubMigrator) Rollback(context.Context, int) error                   { return nil }
+func (stubMigrator) Reset(context.Context) error                           { return nil }
+func (stubMigrator) Refresh(context.Context) error                         { return nil }
+func (stubMigrator) Fresh(context.Context) error                           { return nil }
+func (stubMigrator) MigrateTo(context.Context, string) error               { return nil }
+func (stubMigrator) RollbackTo(context.Context, string) error              { return nil }
+func (stubMigrator) Status(context.Context) ([]MigrationStatusInfo, error) { return nil, nil }
+func (stubMigrator) DumpSchema(context.Context, string) ([]string, error)  { return nil, nil }
+func (stubMigrator) UpTenants(context.Context) ([]TenantReportInfo, error) { return nil, nil }
+func (stubMigrator) StatusTenants(context.Context) ([]TenantStatusInfo, error) {
+	return nil, nil
+}
+func (stubMigrator) Plan(context.Context, string, string) (*PlanInfo, error) { return nil, nil }
+func (stubMigrator) Lint(context.Context, []string) ([]LintViolationInfo, error) {
+	return nil, nil
+}
+func (stubMigrator) Unlock(context.Context) (bool, error) { return false, nil }
 
 func TestConfirm_AcceptsY(t *testing.T) {
 	cmd := &cobra.Command{}
@@ -1,23 +1,39 @@
 package commands
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/andrianprasetya/go-migration/internal/generator"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
 	"github.com/andrianprasetya/go-migration/pkg/seeder"
+	"github.com/spf13/cobra"
 )
 
 // MigratorRunner defines the migration operations needed by CLI commands.
 // It is satisfied by *migrator.Migrator (via MigratorAdapter) but defined
 // here as an interface to avoid an import cycle between commands and migrator.
+//
+// Every method receives the command's context, which is cancelled when the
+// process is interrupted so that in-flight migrations roll back cleanly.
 type MigratorRunner interface {
-	Up() error
-	Rollback(steps int) error
-	Reset() error
-	Refresh() error
-	Fresh() error
-	Status() ([]MigrationStatusInfo, error)
+	Up(ctx context.Context) error
+	Rollback(ctx context.Context, steps int) error
+	Reset(ctx context.Context) error
+	Refresh(ctx context.Context) error
+	Fresh(ctx context.Context) error
+	MigrateTo(ctx context.Context, name string) error
+	RollbackTo(ctx context.Context, name string) error
+	Status(ctx context.Context) ([]MigrationStatusInfo, error)
+	DumpSchema(ctx context.Context, path string) ([]string, error)
+	UpTenants(ctx context.Context) ([]TenantReportInfo, error)
+	StatusTenants(ctx context.Context) ([]TenantStatusInfo, error)
+	Plan(ctx context.Context, direction, target string) (*PlanInfo, error)
+	Lint(ctx context.Context, disabled []string) ([]LintViolationInfo, error)
+	// Unlock releases a migration lock left behind by a crashed process
+	// and reports whether one was held.
+	Unlock(ctx context.Context) (bool, error)
 }
 
 // MigrationStatusInfo holds the status of a single migration.
@@ -27,6 +43,74 @@ type MigrationStatusInfo struct {
 	Applied   bool
 	Batch     int
 	AppliedAt *time.Time
+	Modified  bool
+	// ChecksumError describes why the checksum of an applied migration
+	// could not be computed, or is empty.
+	ChecksumError string
+	// Phase is the last phase a phased migration completed, or empty.
+	Phase string
+	// Partial is true for phased migrations that have not yet run all
+	// of their phases.
+	Partial bool
+	// Connection is the name of the connection the migration runs on.
+	Connection string
+}
+
+// TenantReportInfo holds the outcome of migrating one tenant.
+// It mirrors migrator.TenantReport without importing the package.
+type TenantReportInfo struct {
+	Tenant   string
+	Applied  []string
+	Duration time.Duration
+	Err      error
+}
+
+// TenantStatusInfo summarizes the migrations of one tenant.
+type TenantStatusInfo struct {
+	Tenant  string
+	Applied int
+	Pending int
+	Err     error
+}
+
+// PlanInfo describes what migrating up or rolling back would do.
+// It mirrors migrator.Plan without importing the package, and is printed
+// as is by migrate:plan --output=json|yaml.
+type PlanInfo struct {
+	// Direction is "up" or "down".
+	Direction string `json:"direction" yaml:"direction"`
+	// Target is the migration the plan stops at, or empty.
+	Target     string                 `json:"target,omitempty" yaml:"target,omitempty"`
+	Migrations []PlannedMigrationInfo `json:"migrations" yaml:"migrations"`
+}
+
+// PlannedMigrationInfo is one migration of a PlanInfo.
+type PlannedMigrationInfo struct {
+	Name       string `json:"name" yaml:"name"`
+	Connection string `json:"connection,omitempty" yaml:"connection,omitempty"`
+	// Batch is the batch the migration would be recorded in (up) or is
+	// recorded in (down).
+	Batch         int  `json:"batch" yaml:"batch"`
+	Transactional bool `json:"transactional" yaml:"transactional"`
+	// Baseline is true for a schema dump loaded before the migrations;
+	// Name is then its path.
+	Baseline   bool     `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Phases     []string `json:"phases,omitempty" yaml:"phases,omitempty"`
+	Statements []string `json:"statements" yaml:"statements"`
+	// Destructive is true when a statement drops a table or column or
+	// deletes rows.
+	Destructive bool     `json:"destructive,omitempty" yaml:"destructive,omitempty"`
+	Warnings    []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// LintViolationInfo is a risky statement found by migrate:lint.
+// It mirrors migrator.LintViolation without importing the package.
+type LintViolationInfo struct {
+	Rule       string `json:"rule" yaml:"rule"`
+	Migration  string `json:"migration" yaml:"migration"`
+	Connection string `json:"connection,omitempty" yaml:"connection,omitempty"`
+	Statement  string `json:"statement" yaml:"statement"`
+	Message    string `json:"message" yaml:"message"`
 }
 
 // TrackerCreator creates a migration tracker for the given DB.
@@ -43,4 +127,33 @@ type CommandContext struct {
 	Seeder         *seeder.Runner
 	Generator      *generator.Generator
 	TrackerEnsurer TrackerCreator
+
+	// MigrationDir is the directory holding migration files and
+	// SchemaDumpPath the default baseline written by schema:dump.
+	MigrationDir   string
+	SchemaDumpPath string
+
+	// DiffSchema compares the declared tables against the database; it is
+	// set only when make:migration runs with --auto.
+	DiffSchema func(ctx context.Context) (*schema.SchemaDiff, error)
+
+	// Events records the migrations applied or rolled back while the
+	// command runs, for --output=json|yaml.
+	Events *EventRecorder
+	// DescribeError classifies an error for --output=json|yaml; without
+	// it every error is reported with class "error" and exit code 1.
+	DescribeError func(err error) ErrorInfo
+
+	// Production is true when the configured environment is production;
+	// destructive commands then ask for confirmation first.
+	Production bool
+}
+
+// commandContext returns the context attached to cmd, or
+// context.Background() when the command is executed without one.
+func commandContext(cmd *cobra.Command) context.Context {
+	if c := cmd.Context(); c != nil {
+		return c
+	}
+	return context.Background()
 }
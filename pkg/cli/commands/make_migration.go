@@ -10,7 +10,8 @@ import (
 // NewMakeMigrationCommand creates the "make:migration" command.
 // It generates a new migration file from a template with the correct
 // timestamp prefix and struct scaffolding. Supports --create and --table
-// flags for pre-populated schema builder calls.
+// flags for pre-populated schema builder calls, and --auto to generate the
+// calls from the difference between the declared schema and the database.
 func NewMakeMigrationCommand(getCtx func() *CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "make:migration [name]",
@@ -31,6 +32,17 @@ func NewMakeMigrationCommand(getCtx func() *CommandContext) *cobra.Command {
 				return fmt.Errorf("invalid --table flag: %w", err)
 			}
 
+			auto, err := cmd.Flags().GetBool("auto")
+			if err != nil {
+				return fmt.Errorf("invalid --auto flag: %w", err)
+			}
+			if auto {
+				if create != "" || table != "" {
+					return fmt.Errorf("--auto cannot be combined with --create or --table")
+				}
+				return runAutoMigration(cmd, ctx, args[0])
+			}
+
 			opts := generator.MigrationOptions{
 				CreateTable: create,
 				AlterTable:  table,
@@ -47,5 +59,37 @@ func NewMakeMigrationCommand(getCtx func() *CommandContext) *cobra.Command {
 	}
 	cmd.Flags().String("create", "", "table name to create (pre-populates schema Create call)")
 	cmd.Flags().String("table", "", "table name to alter (pre-populates schema Alter call)")
+	cmd.Flags().Bool("auto", false, "generate the migration by diffing declared tables against the database")
 	return cmd
 }
+
+// runAutoMigration diffs the declared schema against the database and
+// writes a migration for the difference. No file is written when the
+// database is already up to date.
+func runAutoMigration(cmd *cobra.Command, ctx *CommandContext, name string) error {
+	if ctx.DiffSchema == nil {
+		return fmt.Errorf("schema diff not initialized")
+	}
+
+	diff, err := ctx.DiffSchema(commandContext(cmd))
+	if err != nil {
+		return fmt.Errorf("diff schema: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if diff.Empty() {
+		fmt.Fprintln(out, "Nothing to migrate: the database matches the declared schema")
+		return nil
+	}
+	for _, w := range diff.Warnings {
+		fmt.Fprintf(out, "Warning: %s\n", w)
+	}
+
+	path, err := ctx.Generator.AutoMigration(name, diff)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Created migration: %s\n", path)
+	return nil
+}
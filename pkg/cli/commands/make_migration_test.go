@@ -2,11 +2,13 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/andrianprasetya/go-migration/internal/generator"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -128,3 +130,70 @@ func TestNewMakeMigrationCommand_TableFlag(t *testing.T) {
 	assert.Contains(t, string(content), "Alter")
 	assert.Contains(t, string(content), "users")
 }
+
+func TestNewMakeMigrationCommand_Auto(t *testing.T) {
+	tmpDir := t.TempDir()
+	gen := generator.NewGenerator(tmpDir)
+
+	users := schema.NewBlueprint("users")
+	users.ID()
+	diff := &schema.SchemaDiff{
+		Tables:   []schema.TableDiff{{Table: "users", Create: users}},
+		Warnings: []string{"something to review"},
+	}
+
+	cmd := NewMakeMigrationCommand(func() *CommandContext {
+		return &CommandContext{
+			Generator:  gen,
+			DiffSchema: func(context.Context) (*schema.SchemaDiff, error) { return diff, nil },
+		}
+	})
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"sync_schema", "--auto"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "Warning: something to review")
+	assert.Contains(t, buf.String(), "Created migration:")
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	content, err := os.ReadFile(filepath.Join(tmpDir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `s.Create("users"`)
+}
+
+func TestNewMakeMigrationCommand_AutoNothingToMigrate(t *testing.T) {
+	tmpDir := t.TempDir()
+	gen := generator.NewGenerator(tmpDir)
+
+	cmd := NewMakeMigrationCommand(func() *CommandContext {
+		return &CommandContext{
+			Generator:  gen,
+			DiffSchema: func(context.Context) (*schema.SchemaDiff, error) { return &schema.SchemaDiff{}, nil },
+		}
+	})
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"sync_schema", "--auto"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "Nothing to migrate")
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNewMakeMigrationCommand_AutoRejectsCreate(t *testing.T) {
+	cmd := NewMakeMigrationCommand(func() *CommandContext {
+		return &CommandContext{Generator: generator.NewGenerator(t.TempDir())}
+	})
+	cmd.SetArgs([]string{"sync_schema", "--auto", "--create", "users"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--auto cannot be combined")
+}
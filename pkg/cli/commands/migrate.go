@@ -2,6 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -16,9 +18,79 @@ func NewMigrateCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
-			return ctx.Migrator.Up()
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			tenants, _ := cmd.Flags().GetBool("tenants")
+			// In production, migrations that drop data need confirming.
+			// The plan is only computed on the default database, so with
+			// --tenants, where every tenant changes, only --force runs it.
+			if guardsProduction(cmd, ctx) {
+				if tenants {
+					err := fmt.Errorf("%w: migrate --tenants changes every tenant, pass --force to run it", ErrProductionGuard)
+					return report(cmd, ctx, format, nil, err)
+				}
+				plan, err := ctx.Migrator.Plan(commandContext(cmd), "up", "")
+				if err != nil {
+					return report(cmd, ctx, format, nil, err)
+				}
+				if reasons := destructiveReasons(plan); len(reasons) > 0 {
+					if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+						return err
+					}
+				}
+			}
+			if tenants {
+				return migrateTenants(cmd, ctx, format)
+			}
+			err = ctx.Migrator.Up(commandContext(cmd))
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().String("phase", "expand", "Run phased migrations up to this phase (expand, backfill or contract)")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	cmd.Flags().Bool("tenants", false, "Run pending migrations on every configured tenant")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
 	return cmd
 }
+
+// migrateTenants runs the pending migrations of every tenant and prints a
+// report with one row per tenant. The error reports the failed tenants.
+func migrateTenants(cmd *cobra.Command, ctx *CommandContext, format string) error {
+	reports, err := ctx.Migrator.UpTenants(commandContext(cmd))
+
+	if format != OutputTable {
+		result := &Result{Tenants: make([]TenantResult, len(reports))}
+		for i, r := range reports {
+			result.Tenants[i] = TenantResult{
+				Tenant:     r.Tenant,
+				Status:     "ok",
+				Applied:    len(r.Applied),
+				Migrations: r.Applied,
+				DurationMS: milliseconds(r.Duration),
+				Error:      ctx.describe(r.Err),
+			}
+			if r.Err != nil {
+				result.Tenants[i].Status = "error"
+			}
+		}
+		return report(cmd, ctx, format, result, err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Tenant\tResult\tApplied\tDuration")
+	for _, r := range reports {
+		result := "OK"
+		if r.Err != nil {
+			result = fmt.Sprintf("Failed: %v", r.Err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", r.Tenant, result, len(r.Applied), r.Duration.Round(time.Millisecond))
+	}
+	if flushErr := w.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
+}
@@ -17,6 +17,19 @@ func NewMigrateFreshCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Dropping every table of a production database is refused
+			// unless explicitly allowed; --force alone is not enough.
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			allow, _ := cmd.Flags().GetBool("allow-production")
+			if ctx.Production && !dryRun && !allow {
+				err := fmt.Errorf("%w: migrate:fresh drops every table, pass --allow-production to run it", ErrProductionGuard)
+				return report(cmd, ctx, format, nil, err)
+			}
 
 			force, _ := cmd.Flags().GetBool("force")
 			if !force {
@@ -25,17 +38,23 @@ func NewMigrateFreshCommand(getCtx func() *CommandContext) *cobra.Command {
 					return err
 				}
 				if !confirmed {
-					fmt.Fprintln(cmd.OutOrStdout(), "Operation cancelled.")
-					return nil
+					return cancelled(cmd, ctx, format)
 				}
 			}
 
-			return ctx.Migrator.Fresh()
+			err = ctx.Migrator.Fresh(commandContext(cmd))
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 
 	cmd.Flags().Bool("force", false, "Force the operation to run without confirmation")
 	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().Bool("allow-production", false, "Allow running in a production environment")
+	cmd.Flags().Bool("drop-views", false, "Also drop all views (PostgreSQL drops views that depend on the tables regardless)")
+	cmd.Flags().Bool("drop-triggers", false, "Also drop all triggers")
+	cmd.Flags().Bool("drop-sequences", false, "Also drop all sequences")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	addOutputFlag(cmd)
 
 	return cmd
 }
@@ -9,7 +9,7 @@ import (
 // NewMigrateInstallCommand creates the "migrate:install" command
 // that creates the migration tracking table.
 func NewMigrateInstallCommand(getCtx func() *CommandContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "migrate:install",
 		Short: "Create the migration tracking table",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -17,7 +17,14 @@ func NewMigrateInstallCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.TrackerEnsurer == nil {
 				return fmt.Errorf("database connection not initialized")
 			}
-			return ctx.TrackerEnsurer.EnsureTable()
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			return report(cmd, ctx, format, nil, ctx.TrackerEnsurer.EnsureTable())
 		},
 	}
+	cmd.Flags().String("database", "", "Run against this connection only")
+	addOutputFlag(cmd)
+	return cmd
 }
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// ErrLintViolations is returned by migrate:lint when a pending migration
+// breaks a lint rule, so that CI fails.
+var ErrLintViolations = errors.New("migrations break lint rules")
+
+// NewMigrateLintCommand creates the "migrate:lint" command that checks the
+// statements migrate would run for patterns that are risky on a live
+// database, such as indexes built without CONCURRENTLY or NOT NULL columns
+// added without a default. It fails when a rule is broken.
+func NewMigrateLintCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate:lint",
+		Short: "Check pending migrations for risky statements",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Migrator == nil {
+				return fmt.Errorf("migrator not initialized")
+			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			disabled, err := cmd.Flags().GetStringSlice("disable")
+			if err != nil {
+				return fmt.Errorf("invalid --disable flag: %w", err)
+			}
+
+			violations, err := ctx.Migrator.Lint(commandContext(cmd), disabled)
+			if err == nil && len(violations) > 0 {
+				err = fmt.Errorf("%w: %d violations", ErrLintViolations, len(violations))
+			}
+			if format != OutputTable {
+				return report(cmd, ctx, format, &Result{Lint: violations}, err)
+			}
+			if printErr := printLint(cmd.OutOrStdout(), violations); printErr != nil && err == nil {
+				err = printErr
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringSlice("disable", nil, "Lint rules not to check, comma-separated")
+	cmd.Flags().String("phase", "expand", "Check phased migrations up to this phase (expand, backfill or contract)")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// printLint writes each violation with the statement that caused it.
+func printLint(w io.Writer, violations []LintViolationInfo) error {
+	if len(violations) == 0 {
+		_, err := fmt.Fprintln(w, "No lint violations.")
+		return err
+	}
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s: %s [%s]\n", v.Migration, v.Message, v.Rule)
+		fmt.Fprintf(w, "  %s;\n", v.Statement)
+	}
+	noun := "violations"
+	if len(violations) == 1 {
+		noun = "violation"
+	}
+	_, err := fmt.Fprintf(w, "\n%d lint %s.\n", len(violations), noun)
+	return err
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMigratePlanCommand creates the "migrate:plan" command that shows what
+// migrate (or, with --direction=down, migrate:rollback) would do: the
+// migrations in order, the statements each would run, the batch it would
+// be recorded in and warnings such as destructive drops. Nothing is
+// written to the database.
+func NewMigratePlanCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate:plan [target]",
+		Short: "Show what migrate or migrate:rollback would do",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Migrator == nil {
+				return fmt.Errorf("migrator not initialized")
+			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			direction, err := cmd.Flags().GetString("direction")
+			if err != nil {
+				return fmt.Errorf("invalid --direction flag: %w", err)
+			}
+			target := ""
+			if len(args) == 1 {
+				target = args[0]
+			}
+
+			plan, err := ctx.Migrator.Plan(commandContext(cmd), direction, target)
+			if err != nil || format != OutputTable {
+				return report(cmd, ctx, format, &Result{Plan: plan}, err)
+			}
+			return printPlan(cmd.OutOrStdout(), plan)
+		},
+	}
+	cmd.Flags().String("direction", "up", "Plan migrating up or rolling back (up or down)")
+	cmd.Flags().String("phase", "expand", "Plan phased migrations up to this phase (expand, backfill or contract)")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// printPlan writes plan as one block per migration: a header with its
+// batch and transaction mode, its statements and its warnings.
+func printPlan(w io.Writer, plan *PlanInfo) error {
+	if len(plan.Migrations) == 0 {
+		if plan.Direction == "down" {
+			_, err := fmt.Fprintln(w, "Nothing to roll back.")
+			return err
+		}
+		_, err := fmt.Fprintln(w, "Nothing to migrate.")
+		return err
+	}
+
+	// The connection is only shown when the plan spans several.
+	multi := false
+	for _, pm := range plan.Migrations {
+		if pm.Connection != plan.Migrations[0].Connection {
+			multi = true
+		}
+	}
+
+	for i, pm := range plan.Migrations {
+		var details []string
+		if multi {
+			details = append(details, "connection "+pm.Connection)
+		}
+		if pm.Baseline {
+			details = append(details, "schema dump")
+		}
+		if pm.Batch > 0 {
+			details = append(details, fmt.Sprintf("batch %d", pm.Batch))
+		}
+		if len(pm.Phases) > 0 {
+			details = append(details, "phases "+strings.Join(pm.Phases, ", "))
+		}
+		if pm.Transactional {
+			details = append(details, "transaction")
+		} else {
+			details = append(details, "no transaction")
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s %s (%s)\n", plan.Direction, pm.Name, strings.Join(details, ", "))
+		for _, stmt := range pm.Statements {
+			fmt.Fprintf(w, "  %s;\n", stmt)
+		}
+		for _, warning := range pm.Warnings {
+			if _, err := fmt.Fprintf(w, "  WARNING: %s\n", warning); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
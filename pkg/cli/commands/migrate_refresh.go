@@ -17,9 +17,23 @@ func NewMigrateRefreshCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
-			return ctx.Migrator.Refresh()
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			if guardsProduction(cmd, ctx) {
+				reasons := []string{"roll back every migration and run them again"}
+				if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+					return err
+				}
+			}
+			err = ctx.Migrator.Refresh(commandContext(cmd))
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
 	return cmd
 }
@@ -16,6 +16,10 @@ func NewMigrateResetCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
 
 			force, _ := cmd.Flags().GetBool("force")
 			if !force {
@@ -24,17 +28,19 @@ func NewMigrateResetCommand(getCtx func() *CommandContext) *cobra.Command {
 					return err
 				}
 				if !confirmed {
-					fmt.Fprintln(cmd.OutOrStdout(), "Operation cancelled.")
-					return nil
+					return cancelled(cmd, ctx, format)
 				}
 			}
 
-			return ctx.Migrator.Reset()
+			err = ctx.Migrator.Reset(commandContext(cmd))
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 
 	cmd.Flags().Bool("force", false, "Force the operation to run without confirmation")
 	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	addOutputFlag(cmd)
 
 	return cmd
 }
@@ -8,7 +8,8 @@ import (
 
 // NewMigrateRollbackCommand creates the "migrate:rollback" command.
 // It supports a --step flag to roll back a specific number of migrations.
-// When --step is 0 (default), it rolls back the last batch.
+// When --step is 0 (default), it rolls back the last batch. With --to it
+// rolls back every migration applied after the named one instead.
 func NewMigrateRollbackCommand(getCtx func() *CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "migrate:rollback",
@@ -18,14 +19,51 @@ func NewMigrateRollbackCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
 			steps, err := cmd.Flags().GetInt("step")
 			if err != nil {
 				return fmt.Errorf("invalid --step flag: %w", err)
 			}
-			return ctx.Migrator.Rollback(steps)
+			to, err := cmd.Flags().GetString("to")
+			if err != nil {
+				return fmt.Errorf("invalid --to flag: %w", err)
+			}
+			if to != "" && steps != 0 {
+				return fmt.Errorf("--to and --step cannot be used together")
+			}
+			// In production, rolling back needs confirming. Only the
+			// plan of a batch or --to lists the migrations concerned.
+			if guardsProduction(cmd, ctx) {
+				reasons := []string{fmt.Sprintf("roll back the last %d migrations", steps)}
+				if steps == 0 {
+					plan, err := ctx.Migrator.Plan(commandContext(cmd), "down", to)
+					if err != nil {
+						return report(cmd, ctx, format, nil, err)
+					}
+					reasons = rollbackReasons(plan)
+				}
+				if len(reasons) > 0 {
+					if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+						return err
+					}
+				}
+			}
+			if to != "" {
+				err = ctx.Migrator.RollbackTo(commandContext(cmd), to)
+			} else {
+				err = ctx.Migrator.Rollback(commandContext(cmd), steps)
+			}
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 	cmd.Flags().Int("step", 0, "number of migrations to roll back (0 = last batch)")
+	cmd.Flags().String("to", "", "roll back every migration applied after this one")
 	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
 	return cmd
 }
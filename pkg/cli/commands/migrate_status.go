@@ -10,7 +10,7 @@ import (
 // NewMigrateStatusCommand creates the "migrate:status" command
 // that displays the status of all registered migrations.
 func NewMigrateStatusCommand(getCtx func() *CommandContext) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "migrate:status",
 		Short: "Show the status of each migration",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -18,12 +18,31 @@ func NewMigrateStatusCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Migrator == nil {
 				return fmt.Errorf("migrator not initialized")
 			}
-			statuses, err := ctx.Migrator.Status()
+			format, err := outputFormat(cmd)
 			if err != nil {
 				return err
 			}
+			if tenants, _ := cmd.Flags().GetBool("tenants"); tenants {
+				return statusTenants(cmd, ctx, format)
+			}
+			statuses, err := ctx.Migrator.Status(commandContext(cmd))
+			if err != nil || format != OutputTable {
+				return report(cmd, ctx, format, &Result{Migrations: migrationResults(statuses)}, err)
+			}
+
+			// The connection column is only shown when migrations are
+			// spread over several connections.
+			multi := false
+			for _, s := range statuses {
+				if s.Connection != statuses[0].Connection {
+					multi = true
+				}
+			}
 
 			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			if multi {
+				fmt.Fprint(w, "Connection\t")
+			}
 			fmt.Fprintln(w, "Migration\tStatus\tBatch\tApplied At")
 			for _, s := range statuses {
 				status := "Pending"
@@ -31,14 +50,105 @@ func NewMigrateStatusCommand(getCtx func() *CommandContext) *cobra.Command {
 				appliedAt := ""
 				if s.Applied {
 					status = "Applied"
+					if s.Partial {
+						status = fmt.Sprintf("Partial (%s)", s.Phase)
+					}
+					if s.Modified {
+						status = "Modified"
+					}
 					batch = fmt.Sprintf("%d", s.Batch)
 					if s.AppliedAt != nil {
 						appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
 					}
 				}
+				if multi {
+					fmt.Fprintf(w, "%s\t", s.Connection)
+				}
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, status, batch, appliedAt)
 			}
-			return w.Flush()
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			for _, s := range statuses {
+				if s.ChecksumError != "" {
+					fmt.Fprintf(cmd.ErrOrStderr(), "checksum %s: %s\n", s.Name, s.ChecksumError)
+				}
+			}
+			return nil
 		},
 	}
+	cmd.Flags().String("database", "", "Run against this connection only")
+	cmd.Flags().Bool("tenants", false, "Summarize the migrations of every configured tenant")
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// migrationResults converts statuses to their --output representation.
+func migrationResults(statuses []MigrationStatusInfo) []MigrationResult {
+	results := make([]MigrationResult, len(statuses))
+	for i, s := range statuses {
+		r := MigrationResult{
+			Name:       s.Name,
+			Connection: s.Connection,
+			Status:     "pending",
+		}
+		if s.Applied {
+			r.Status = "applied"
+			if s.Partial {
+				r.Status = "partial"
+			}
+			if s.Modified {
+				r.Status = "modified"
+			}
+			r.Batch = s.Batch
+			r.AppliedAt = s.AppliedAt
+			r.Phase = s.Phase
+			r.ChecksumError = s.ChecksumError
+		}
+		results[i] = r
+	}
+	return results
+}
+
+// statusTenants prints the number of applied and pending migrations of
+// every tenant.
+func statusTenants(cmd *cobra.Command, ctx *CommandContext, format string) error {
+	statuses, err := ctx.Migrator.StatusTenants(commandContext(cmd))
+
+	if format != OutputTable {
+		result := &Result{Tenants: make([]TenantResult, len(statuses))}
+		for i, s := range statuses {
+			result.Tenants[i] = TenantResult{
+				Tenant:  s.Tenant,
+				Status:  "ok",
+				Applied: s.Applied,
+				Pending: s.Pending,
+				Error:   ctx.describe(s.Err),
+			}
+			switch {
+			case s.Err != nil:
+				result.Tenants[i].Status = "error"
+			case s.Pending > 0:
+				result.Tenants[i].Status = "pending"
+			}
+		}
+		return report(cmd, ctx, format, result, err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Tenant\tApplied\tPending\tStatus")
+	for _, s := range statuses {
+		status := "Up to date"
+		switch {
+		case s.Err != nil:
+			status = fmt.Sprintf("Error: %v", s.Err)
+		case s.Pending > 0:
+			status = "Pending"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.Tenant, s.Applied, s.Pending, status)
+	}
+	if flushErr := w.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
 }
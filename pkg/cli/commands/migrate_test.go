@@ -1,7 +1,11 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,6 +17,10 @@ func TestNewMigrateCommand_BasicSetup(t *testing.T) {
 	cmd := NewMigrateCommand(func() *CommandContext { return nil })
 	assert.Equal(t, "migrate", cmd.Use)
 	assert.NotEmpty(t, cmd.Short)
+
+	phase := cmd.Flags().Lookup("phase")
+	require.NotNil(t, phase)
+	assert.Equal(t, "expand", phase.DefValue)
 }
 
 func TestNewMigrateCommand_NilContext(t *testing.T) {
@@ -95,6 +103,10 @@ func TestNewMigrateFreshCommand_BasicSetup(t *testing.T) {
 	cmd := NewMigrateFreshCommand(func() *CommandContext { return nil })
 	assert.Equal(t, "migrate:fresh", cmd.Use)
 	assert.NotEmpty(t, cmd.Short)
+
+	for _, flag := range []string{"drop-views", "drop-triggers", "drop-sequences"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), flag)
+	}
 }
 
 func TestNewMigrateFreshCommand_NilContext(t *testing.T) {
@@ -119,6 +131,193 @@ func TestNewMigrateStatusCommand_NilContext(t *testing.T) {
 	assert.Contains(t, err.Error(), "migrator not initialized")
 }
 
+// statusMigrator is a stubMigrator that reports fixed statuses.
+type statusMigrator struct {
+	stubMigrator
+	statuses []MigrationStatusInfo
+}
+
+func (s statusMigrator) Status(context.Context) ([]MigrationStatusInfo, error) {
+	return s.statuses, nil
+}
+
+func TestNewMigrateStatusCommand_ShowsModified(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := statusMigrator{statuses: []MigrationStatusInfo{
+		{Name: "20240101000000_create_users", Applied: true, Batch: 1, AppliedAt: &appliedAt},
+		{Name: "20240102000000_create_posts", Applied: true, Batch: 1, AppliedAt: &appliedAt, Modified: true},
+		{Name: "20240103000000_create_tags"},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.Contains(t, string(lines[1]), "Applied")
+	assert.Contains(t, string(lines[2]), "Modified")
+	assert.Contains(t, string(lines[3]), "Pending")
+}
+
+func TestNewMigrateStatusCommand_ReportsChecksumErrors(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := statusMigrator{statuses: []MigrationStatusInfo{
+		{Name: "20240101000000_create_users", Applied: true, Batch: 1, AppliedAt: &appliedAt, ChecksumError: "up failed"},
+		{Name: "20240102000000_create_posts", Applied: true, Batch: 1, AppliedAt: &appliedAt},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[1]), "Applied")
+	assert.Equal(t, "checksum 20240101000000_create_users: up failed\n", errOut.String())
+}
+
+func TestNewMigrateStatusCommand_ShowsPartial(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := statusMigrator{statuses: []MigrationStatusInfo{
+		{Name: "20240101000000_full_name", Applied: true, Batch: 1, AppliedAt: &appliedAt, Phase: "backfill", Partial: true},
+		{Name: "20240102000000_email", Applied: true, Batch: 1, AppliedAt: &appliedAt, Phase: "contract"},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[1]), "Partial (backfill)")
+	assert.Contains(t, string(lines[2]), "Applied")
+}
+
+func TestNewMigrateStatusCommand_ShowsConnection(t *testing.T) {
+	m := statusMigrator{statuses: []MigrationStatusInfo{
+		{Name: "20240101000000_create_users", Connection: "app"},
+		{Name: "20240102000000_create_events", Connection: "analytics"},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.True(t, bytes.HasPrefix(lines[0], []byte("Connection")))
+	assert.True(t, bytes.HasPrefix(lines[2], []byte("analytics")))
+	assert.NotNil(t, cmd.Flags().Lookup("database"))
+}
+
+// tenantMigrator is a stubMigrator that returns fixed tenant results.
+type tenantMigrator struct {
+	stubMigrator
+	reports  []TenantReportInfo
+	statuses []TenantStatusInfo
+	err      error
+}
+
+func (m tenantMigrator) UpTenants(context.Context) ([]TenantReportInfo, error) {
+	return m.reports, m.err
+}
+
+func (m tenantMigrator) StatusTenants(context.Context) ([]TenantStatusInfo, error) {
+	return m.statuses, m.err
+}
+
+func TestNewMigrateCommand_Tenants(t *testing.T) {
+	failed := errors.New("1 of 2 tenants: tenant migration failed")
+	m := tenantMigrator{
+		reports: []TenantReportInfo{
+			{Tenant: "acme", Applied: []string{"20240101000000_create_users"}, Duration: time.Second},
+			{Tenant: "globex", Err: errors.New("connection refused")},
+		},
+		err: failed,
+	}
+	cmd := NewMigrateCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	require.NoError(t, cmd.Flags().Set("tenants", "true"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	assert.Equal(t, failed, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[1]), "acme")
+	assert.Contains(t, string(lines[1]), "OK")
+	assert.Contains(t, string(lines[2]), "Failed: connection refused")
+}
+
+func TestNewMigrateStatusCommand_Tenants(t *testing.T) {
+	m := tenantMigrator{statuses: []TenantStatusInfo{
+		{Tenant: "acme", Applied: 2},
+		{Tenant: "globex", Applied: 1, Pending: 1},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	require.NoError(t, cmd.Flags().Set("tenants", "true"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[1]), "Up to date")
+	assert.Contains(t, string(lines[2]), "Pending")
+}
+
+// --- NewMigrateToCommand ---
+
+// targetMigrator is a stubMigrator that records MigrateTo/RollbackTo targets.
+type targetMigrator struct {
+	stubMigrator
+	calls []string
+}
+
+func (m *targetMigrator) MigrateTo(_ context.Context, name string) error {
+	m.calls = append(m.calls, "to:"+name)
+	return nil
+}
+
+func (m *targetMigrator) RollbackTo(_ context.Context, name string) error {
+	m.calls = append(m.calls, "rollback-to:"+name)
+	return nil
+}
+
+func TestNewMigrateToCommand_BasicSetup(t *testing.T) {
+	cmd := NewMigrateToCommand(func() *CommandContext { return nil })
+	assert.Equal(t, "migrate:to <name>", cmd.Use)
+	assert.NotEmpty(t, cmd.Short)
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+}
+
+func TestNewMigrateToCommand_RequiresName(t *testing.T) {
+	cmd := NewMigrateToCommand(func() *CommandContext { return nil })
+	assert.Error(t, cmd.Args(cmd, nil))
+	assert.NoError(t, cmd.Args(cmd, []string{"20240101000000_create_users"}))
+}
+
+func TestNewMigrateToCommand_CallsMigrateTo(t *testing.T) {
+	m := &targetMigrator{}
+	cmd := NewMigrateToCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+
+	require.NoError(t, cmd.RunE(cmd, []string{"20240101000000_create_users"}))
+	assert.Equal(t, []string{"to:20240101000000_create_users"}, m.calls)
+}
+
+func TestNewMigrateRollbackCommand_To(t *testing.T) {
+	m := &targetMigrator{}
+	cmd := NewMigrateRollbackCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	require.NoError(t, cmd.Flags().Set("to", "20240101000000_create_users"))
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, []string{"rollback-to:20240101000000_create_users"}, m.calls)
+
+	require.NoError(t, cmd.Flags().Set("step", "2"))
+	assert.Error(t, cmd.RunE(cmd, nil))
+}
+
 // --- NewMigrateInstallCommand ---
 
 func TestNewMigrateInstallCommand_BasicSetup(t *testing.T) {
@@ -141,6 +340,106 @@ func TestNewMigrateInstallCommand_NilDB(t *testing.T) {
 	assert.Contains(t, err.Error(), "database connection not initialized")
 }
 
+// --- NewMigratePlanCommand ---
+
+// planMigrator is a stubMigrator that returns a fixed plan.
+type planMigrator struct {
+	stubMigrator
+	plan   *PlanInfo
+	target *string
+}
+
+func (m planMigrator) Plan(_ context.Context, direction, target string) (*PlanInfo, error) {
+	*m.target = target
+	return m.plan, nil
+}
+
+func TestNewMigratePlanCommand_PrintsPlan(t *testing.T) {
+	var target string
+	m := planMigrator{target: &target, plan: &PlanInfo{
+		Direction: "down",
+		Migrations: []PlannedMigrationInfo{{
+			Name:          "20240102000000_create_posts",
+			Batch:         2,
+			Transactional: true,
+			Statements:    []string{`DROP TABLE "posts"`},
+			Warnings:      []string{`destructive statement: DROP TABLE "posts"`},
+		}},
+	}}
+	cmd := NewMigratePlanCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("direction", "down"))
+
+	require.NoError(t, cmd.RunE(cmd, []string{"20240101000000_create_users"}))
+
+	assert.Equal(t, "20240101000000_create_users", target)
+	assert.Equal(t, `down 20240102000000_create_posts (batch 2, transaction)
+  DROP TABLE "posts";
+  WARNING: destructive statement: DROP TABLE "posts"
+`, out.String())
+}
+
+func TestNewMigratePlanCommand_Empty(t *testing.T) {
+	var target string
+	m := planMigrator{target: &target, plan: &PlanInfo{Direction: "up"}}
+	cmd := NewMigratePlanCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, "Nothing to migrate.\n", out.String())
+}
+
+// --- NewMigrateLintCommand ---
+
+// lintMigrator is a stubMigrator that returns fixed lint violations.
+type lintMigrator struct {
+	stubMigrator
+	violations []LintViolationInfo
+	disabled   *[]string
+}
+
+func (m lintMigrator) Lint(_ context.Context, disabled []string) ([]LintViolationInfo, error) {
+	*m.disabled = disabled
+	return m.violations, nil
+}
+
+func TestNewMigrateLintCommand_FailsOnViolations(t *testing.T) {
+	var disabled []string
+	m := lintMigrator{disabled: &disabled, violations: []LintViolationInfo{{
+		Rule:      "index-concurrently",
+		Migration: "20240102000000_index_users",
+		Statement: `CREATE INDEX "users_email_index" ON "users" ("email")`,
+		Message:   `index on existing table "users" is built without CONCURRENTLY and blocks writes`,
+	}}}
+	cmd := NewMigrateLintCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("disable", "rename-table,change-column-type"))
+
+	err := cmd.RunE(cmd, nil)
+	require.ErrorIs(t, err, ErrLintViolations)
+	assert.Equal(t, []string{"rename-table", "change-column-type"}, disabled)
+	assert.Equal(t, `20240102000000_index_users: index on existing table "users" is built without CONCURRENTLY and blocks writes [index-concurrently]
+  CREATE INDEX "users_email_index" ON "users" ("email");
+
+1 lint violation.
+`, out.String())
+}
+
+func TestNewMigrateLintCommand_Clean(t *testing.T) {
+	var disabled []string
+	cmd := NewMigrateLintCommand(func() *CommandContext {
+		return &CommandContext{Migrator: lintMigrator{disabled: &disabled}}
+	})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, "No lint violations.\n", out.String())
+}
+
 // --- Help text tests ---
 
 func TestAllMigrateCommands_HaveHelpText(t *testing.T) {
@@ -153,4 +452,7 @@ func TestAllMigrateCommands_HaveHelpText(t *testing.T) {
 	assert.NotEmpty(t, NewMigrateFreshCommand(getCtx).Short)
 	assert.NotEmpty(t, NewMigrateStatusCommand(getCtx).Short)
 	assert.NotEmpty(t, NewMigrateInstallCommand(getCtx).Short)
+	assert.NotEmpty(t, NewMigrateToCommand(getCtx).Short)
+	assert.NotEmpty(t, NewMigratePlanCommand(getCtx).Short)
+	assert.NotEmpty(t, NewMigrateLintCommand(getCtx).Short)
 }
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMigrateToCommand creates the "migrate:to" command that brings the
+// database to the schema version of the named migration. Pending migrations
+// up to and including it are applied; applied migrations after it are
+// rolled back.
+func NewMigrateToCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate:to <name>",
+		Short: "Migrate up or down to the named migration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Migrator == nil {
+				return fmt.Errorf("migrator not initialized")
+			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			// In production, rolling back or running migrations that
+			// drop data needs confirming.
+			if guardsProduction(cmd, ctx) {
+				down, err := ctx.Migrator.Plan(commandContext(cmd), "down", args[0])
+				if err != nil {
+					return report(cmd, ctx, format, nil, err)
+				}
+				up, err := ctx.Migrator.Plan(commandContext(cmd), "up", args[0])
+				if err != nil {
+					return report(cmd, ctx, format, nil, err)
+				}
+				if reasons := append(rollbackReasons(down), destructiveReasons(up)...); len(reasons) > 0 {
+					if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+						return err
+					}
+				}
+			}
+			err = ctx.Migrator.MigrateTo(commandContext(cmd), args[0])
+			return report(cmd, ctx, format, nil, err)
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Show SQL without executing")
+	cmd.Flags().String("database", "", "Run against this connection only")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
+	return cmd
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMigrateUnlockCommand creates the "migrate:unlock" command that
+// releases a migration lock left behind by a process that died while
+// holding it. Only SQLite keeps such locks; the PostgreSQL and MySQL locks
+// are released when their session ends.
+func NewMigrateUnlockCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate:unlock",
+		Short: "Release a migration lock left by a crashed run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Migrator == nil {
+				return fmt.Errorf("migrator not initialized")
+			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			released, err := ctx.Migrator.Unlock(commandContext(cmd))
+			if err != nil || format != OutputTable {
+				return report(cmd, ctx, format, nil, err)
+			}
+			if released {
+				fmt.Fprintln(cmd.OutOrStdout(), "Migration lock released.")
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), "No migration lock is held.")
+			}
+			return nil
+		},
+	}
+	addOutputFlag(cmd)
+	return cmd
+}
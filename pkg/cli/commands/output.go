@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by the --output flag of the migrate:* and
+// db:seed* commands.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// ResultSchemaVersion is the version of the Result schema. It is bumped
+// only when a field is removed or changes meaning; new fields may be added
+// without a bump.
+const ResultSchemaVersion = 1
+
+// Result is the document printed by a migrate:* or db:seed* command run
+// with --output=json or --output=yaml. Empty lists and unset fields are
+// omitted.
+type Result struct {
+	SchemaVersion int    `json:"schema_version" yaml:"schema_version"`
+	Command       string `json:"command" yaml:"command"`
+	// Status is "ok", "error" or "cancelled" when a confirmation prompt
+	// was declined.
+	Status string `json:"status" yaml:"status"`
+	// Migrations is the migration status reported by migrate:status.
+	Migrations []MigrationResult `json:"migrations,omitempty" yaml:"migrations,omitempty"`
+	// Events lists the migrations applied or rolled back, in order. It is
+	// not reported with --tenants, where each tenant has its own list.
+	Events []EventResult `json:"events,omitempty" yaml:"events,omitempty"`
+	// Tenants is reported by migrate and migrate:status with --tenants.
+	Tenants []TenantResult `json:"tenants,omitempty" yaml:"tenants,omitempty"`
+	// Plan is reported by migrate:plan.
+	Plan *PlanInfo `json:"plan,omitempty" yaml:"plan,omitempty"`
+	// Seeders is the seeder status reported by db:seed:status and the
+	// seeders run by db:seed with --parallel.
+	Seeders []SeederResult `json:"seeders,omitempty" yaml:"seeders,omitempty"`
+	// Lint lists the violations found by migrate:lint.
+	Lint  []LintViolationInfo `json:"lint,omitempty" yaml:"lint,omitempty"`
+	Error *ErrorInfo          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// MigrationResult is the status of a single migration.
+type MigrationResult struct {
+	Name       string `json:"name" yaml:"name"`
+	Connection string `json:"connection,omitempty" yaml:"connection,omitempty"`
+	// Status is "pending", "applied", "partial" or "modified".
+	Status    string     `json:"status" yaml:"status"`
+	Batch     int        `json:"batch,omitempty" yaml:"batch,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty" yaml:"applied_at,omitempty"`
+	// Phase is the last phase a phased migration completed.
+	Phase string `json:"phase,omitempty" yaml:"phase,omitempty"`
+	// ChecksumError is why an applied migration's checksum could not be
+	// computed.
+	ChecksumError string `json:"checksum_error,omitempty" yaml:"checksum_error,omitempty"`
+}
+
+// SeederResult is the status of a single seeder.
+type SeederResult struct {
+	Name string `json:"name" yaml:"name"`
+	// Status is "pending", "ran" or "changed" when its version changed
+	// since it ran.
+	Status  string     `json:"status" yaml:"status"`
+	Batch   int        `json:"batch,omitempty" yaml:"batch,omitempty"`
+	RanAt   *time.Time `json:"ran_at,omitempty" yaml:"ran_at,omitempty"`
+	Version string     `json:"version,omitempty" yaml:"version,omitempty"`
+	// DurationMS is how long the seeder took, reported by db:seed with
+	// --parallel.
+	DurationMS float64 `json:"duration_ms,omitempty" yaml:"duration_ms,omitempty"`
+}
+
+// EventResult describes one migration applied or rolled back during the
+// command.
+type EventResult struct {
+	Name string `json:"name" yaml:"name"`
+	// Direction is "up" or "down".
+	Direction  string  `json:"direction" yaml:"direction"`
+	DurationMS float64 `json:"duration_ms" yaml:"duration_ms"`
+}
+
+// TenantResult is the outcome of a tenant fanout for one tenant.
+type TenantResult struct {
+	Tenant string `json:"tenant" yaml:"tenant"`
+	// Status is "ok", "pending" (migrate:status only) or "error".
+	Status string `json:"status" yaml:"status"`
+	// Applied is the number of migrations applied to the tenant: during the
+	// command for migrate, in total for migrate:status.
+	Applied int `json:"applied" yaml:"applied"`
+	// Pending is the number of migrations not yet applied (migrate:status).
+	Pending int `json:"pending,omitempty" yaml:"pending,omitempty"`
+	// Migrations lists the migrations applied to the tenant (migrate).
+	Migrations []string   `json:"migrations,omitempty" yaml:"migrations,omitempty"`
+	DurationMS float64    `json:"duration_ms,omitempty" yaml:"duration_ms,omitempty"`
+	Error      *ErrorInfo `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ErrorInfo describes a failure. Migration, SQL, Position and Cause are set
+// for failed migrations and Seeder and Cause for failed seeders.
+type ErrorInfo struct {
+	// Class is the failure class, such as "migration" or "lock".
+	Class string `json:"class" yaml:"class"`
+	// ExitCode is the code the CLI exits with for Class.
+	ExitCode  int    `json:"exit_code" yaml:"exit_code"`
+	Message   string `json:"message" yaml:"message"`
+	Migration string `json:"migration,omitempty" yaml:"migration,omitempty"`
+	Seeder    string `json:"seeder,omitempty" yaml:"seeder,omitempty"`
+	SQL       string `json:"sql,omitempty" yaml:"sql,omitempty"`
+	Position  string `json:"position,omitempty" yaml:"position,omitempty"`
+	Cause     string `json:"cause,omitempty" yaml:"cause,omitempty"`
+}
+
+// EventRecorder collects the migrations applied or rolled back while a
+// command runs. It is safe for concurrent use.
+type EventRecorder struct {
+	mu     sync.Mutex
+	events []EventResult
+}
+
+// Record appends the completion of a migration.
+func (r *EventRecorder) Record(name, direction string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, EventResult{
+		Name:       name,
+		Direction:  direction,
+		DurationMS: milliseconds(duration),
+	})
+}
+
+// Events returns the recorded events in order.
+func (r *EventRecorder) Events() []EventResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]EventResult(nil), r.events...)
+}
+
+// milliseconds converts d to milliseconds with microsecond precision.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// addOutputFlag registers the --output flag.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", OutputTable, "Output format: table, json or yaml")
+}
+
+// outputFormat returns the format selected with --output.
+func outputFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", fmt.Errorf("invalid --output flag: %w", err)
+	}
+	switch format {
+	case OutputTable, OutputJSON, OutputYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid --output %q: must be table, json or yaml", format)
+	}
+}
+
+// report prints r in format, completed with the command name, the recorded
+// events and err, and returns err so that the command still fails. With
+// the table format nothing is printed; commands print their own table.
+func report(cmd *cobra.Command, ctx *CommandContext, format string, r *Result, err error) error {
+	if format == OutputTable {
+		return err
+	}
+	if r == nil {
+		r = &Result{}
+	}
+	r.SchemaVersion = ResultSchemaVersion
+	r.Command = cmd.Name()
+	if r.Status == "" {
+		r.Status = "ok"
+	}
+	if ctx.Events != nil && r.Tenants == nil {
+		r.Events = ctx.Events.Events()
+	}
+	if err != nil {
+		r.Status = "error"
+		r.Error = ctx.describe(err)
+	}
+
+	var out []byte
+	var encErr error
+	if format == OutputJSON {
+		out, encErr = json.MarshalIndent(r, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, encErr = yaml.Marshal(r)
+	}
+	if encErr != nil {
+		return fmt.Errorf("encode %s output: %w", format, encErr)
+	}
+	if _, writeErr := cmd.OutOrStdout().Write(out); writeErr != nil && err == nil {
+		return writeErr
+	}
+	return err
+}
+
+// ReportError reports err, which occurred before cmd ran, for example
+// while loading the configuration, like report does, and returns it.
+// Nothing is printed for commands without --output or with the table
+// format.
+func ReportError(cmd *cobra.Command, ctx *CommandContext, err error) error {
+	if cmd.Flags().Lookup("output") == nil {
+		return err
+	}
+	format, formatErr := outputFormat(cmd)
+	if formatErr != nil {
+		return err
+	}
+	return report(cmd, ctx, format, nil, err)
+}
+
+// cancelled reports that the user declined a confirmation prompt.
+func cancelled(cmd *cobra.Command, ctx *CommandContext, format string) error {
+	if format == OutputTable {
+		fmt.Fprintln(cmd.OutOrStdout(), "Operation cancelled.")
+		return nil
+	}
+	return report(cmd, ctx, format, &Result{Status: "cancelled"}, nil)
+}
+
+// describe returns the ErrorInfo of err, or nil for a nil error.
+func (c *CommandContext) describe(err error) *ErrorInfo {
+	if err == nil {
+		return nil
+	}
+	if c.DescribeError != nil {
+		info := c.DescribeError(err)
+		return &info
+	}
+	return &ErrorInfo{Class: "error", ExitCode: 1, Message: err.Error()}
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// failingMigrator is a stubMigrator whose Up records one event and fails.
+type failingMigrator struct {
+	stubMigrator
+	events *EventRecorder
+	err    error
+}
+
+func (m failingMigrator) Up(context.Context) error {
+	m.events.Record("20240101000000_create_users", "up", 1500*time.Microsecond)
+	return m.err
+}
+
+func TestNewMigrateCommand_JSONOutput(t *testing.T) {
+	events := &EventRecorder{}
+	ctx := &CommandContext{
+		Migrator: failingMigrator{events: events, err: errors.New("syntax error")},
+		Events:   events,
+		DescribeError: func(err error) ErrorInfo {
+			return ErrorInfo{Class: "migration", ExitCode: 5, Message: err.Error(), SQL: "CREATE TABLE"}
+		},
+	}
+	cmd := NewMigrateCommand(func() *CommandContext { return ctx })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	err := cmd.RunE(cmd, nil)
+	assert.EqualError(t, err, "syntax error")
+
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, ResultSchemaVersion, result.SchemaVersion)
+	assert.Equal(t, "migrate", result.Command)
+	assert.Equal(t, "error", result.Status)
+	assert.Equal(t, []EventResult{{Name: "20240101000000_create_users", Direction: "up", DurationMS: 1.5}}, result.Events)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "migration", result.Error.Class)
+	assert.Equal(t, 5, result.Error.ExitCode)
+	assert.Equal(t, "CREATE TABLE", result.Error.SQL)
+}
+
+func TestNewMigrateStatusCommand_YAMLOutput(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := statusMigrator{statuses: []MigrationStatusInfo{
+		{Name: "20240101000000_create_users", Applied: true, Batch: 1, AppliedAt: &appliedAt},
+		{Name: "20240102000000_create_posts"},
+	}}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("output", "yaml"))
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+
+	var result Result
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "ok", result.Status)
+	assert.Nil(t, result.Error)
+	require.Len(t, result.Migrations, 2)
+	assert.Equal(t, "applied", result.Migrations[0].Status)
+	assert.Equal(t, 1, result.Migrations[0].Batch)
+	assert.True(t, appliedAt.Equal(*result.Migrations[0].AppliedAt))
+	assert.Equal(t, "pending", result.Migrations[1].Status)
+	assert.Nil(t, result.Migrations[1].AppliedAt)
+}
+
+func TestNewMigrateStatusCommand_TenantsJSONOutput(t *testing.T) {
+	m := tenantMigrator{
+		statuses: []TenantStatusInfo{
+			{Tenant: "acme", Applied: 2},
+			{Tenant: "globex", Err: errors.New("connection refused")},
+		},
+		err: errors.New("1 of 2 tenants failed"),
+	}
+	cmd := NewMigrateStatusCommand(func() *CommandContext { return &CommandContext{Migrator: m} })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("tenants", "true"))
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	assert.Error(t, cmd.RunE(cmd, nil))
+
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	require.Len(t, result.Tenants, 2)
+	assert.Equal(t, "ok", result.Tenants[0].Status)
+	assert.Equal(t, "error", result.Tenants[1].Status)
+	require.NotNil(t, result.Tenants[1].Error)
+	assert.Equal(t, "error", result.Tenants[1].Error.Class)
+	assert.Equal(t, "connection refused", result.Tenants[1].Error.Message)
+}
+
+func TestOutput_InvalidFormat(t *testing.T) {
+	cmd := NewMigrateCommand(func() *CommandContext { return &CommandContext{Migrator: stubMigrator{}} })
+	require.NoError(t, cmd.Flags().Set("output", "xml"))
+
+	err := cmd.RunE(cmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --output "xml"`)
+}
+
+func TestOutput_CancelledPromptJSON(t *testing.T) {
+	cmd := NewMigrateResetCommand(func() *CommandContext {
+		return &CommandContext{Migrator: stubMigrator{}}
+	})
+	var out, prompt bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&prompt)
+	cmd.SetIn(bytes.NewBufferString("n\n"))
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+
+	assert.Contains(t, prompt.String(), "[y/N]")
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "cancelled", result.Status)
+}
+
+func TestReportError_PrintsSetupErrors(t *testing.T) {
+	ctx := &CommandContext{DescribeError: func(err error) ErrorInfo {
+		return ErrorInfo{Class: "config", ExitCode: 2, Message: err.Error()}
+	}}
+	setupErr := errors.New("load config: no such file")
+
+	cmd := NewMigrateCommand(func() *CommandContext { return ctx })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	assert.Equal(t, setupErr, ReportError(cmd, ctx, setupErr))
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "migrate", result.Command)
+	assert.Equal(t, "error", result.Status)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "config", result.Error.Class)
+	assert.Equal(t, "load config: no such file", result.Error.Message)
+
+	// The table format, and commands without --output, print nothing.
+	for _, cmd := range []*cobra.Command{
+		NewMigrateCommand(func() *CommandContext { return ctx }),
+		NewMakeMigrationCommand(func() *CommandContext { return ctx }),
+	} {
+		out.Reset()
+		cmd.SetOut(&out)
+		assert.Equal(t, setupErr, ReportError(cmd, ctx, setupErr))
+		assert.Empty(t, out.String(), cmd.Name())
+	}
+}
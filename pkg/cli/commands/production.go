@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ErrProductionGuard is returned when a destructive command is refused in a
+// production environment: it was not confirmed and --force was not given,
+// or it is migrate:fresh without --allow-production.
+var ErrProductionGuard = errors.New("refused in production")
+
+// guardsProduction reports whether cmd must be confirmed before it destroys
+// data: the environment is production and neither --dry-run nor --force
+// was given.
+func guardsProduction(cmd *cobra.Command, ctx *CommandContext) bool {
+	if !ctx.Production {
+		return false
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return false
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	return !force
+}
+
+// confirmProduction lists reasons, the data cmd would destroy, and asks
+// whether to continue. It returns true when the command may run; otherwise
+// the caller returns err, which is nil when the user declined. Without an
+// answer, for example when stdin is not a terminal, the command is refused
+// with ErrProductionGuard.
+func confirmProduction(cmd *cobra.Command, ctx *CommandContext, format string, reasons []string) (bool, error) {
+	w := promptWriter(cmd)
+	fmt.Fprintf(w, "This is a production environment. %s will:\n", cmd.Name())
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  - %s\n", reason)
+	}
+
+	confirmed, err := confirm(cmd, "Continue?")
+	if err != nil {
+		err = fmt.Errorf("%w: %s needs confirmation, pass --force to run it without a prompt", ErrProductionGuard, cmd.Name())
+		return false, report(cmd, ctx, format, nil, err)
+	}
+	if !confirmed {
+		return false, cancelled(cmd, ctx, format)
+	}
+	return true, nil
+}
+
+// destructiveReasons returns the warnings of the destructive migrations of
+// plan, each prefixed with the migration's name.
+func destructiveReasons(plan *PlanInfo) []string {
+	if plan == nil {
+		return nil
+	}
+	var reasons []string
+	for _, pm := range plan.Migrations {
+		if !pm.Destructive {
+			continue
+		}
+		for _, warning := range pm.Warnings {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", pm.Name, warning))
+		}
+	}
+	return reasons
+}
+
+// rollbackReasons returns one reason per migration plan rolls back,
+// followed by the warnings of the destructive ones.
+func rollbackReasons(plan *PlanInfo) []string {
+	if plan == nil {
+		return nil
+	}
+	reasons := make([]string, 0, len(plan.Migrations))
+	for _, pm := range plan.Migrations {
+		reasons = append(reasons, "roll back "+pm.Name)
+	}
+	return append(reasons, destructiveReasons(plan)...)
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// guardMigrator is a stubMigrator that plans one destructive migration and
+// records the operations that ran.
+type guardMigrator struct {
+	stubMigrator
+	ran *[]string
+}
+
+func (m guardMigrator) Up(context.Context) error {
+	*m.ran = append(*m.ran, "up")
+	return nil
+}
+
+func (m guardMigrator) Rollback(context.Context, int) error {
+	*m.ran = append(*m.ran, "rollback")
+	return nil
+}
+
+func (m guardMigrator) Fresh(context.Context) error {
+	*m.ran = append(*m.ran, "fresh")
+	return nil
+}
+
+func (m guardMigrator) Plan(_ context.Context, direction, _ string) (*PlanInfo, error) {
+	return &PlanInfo{Direction: direction, Migrations: []PlannedMigrationInfo{{
+		Name:        "20240102000000_drop_posts",
+		Statements:  []string{`DROP TABLE "posts"`},
+		Destructive: true,
+		Warnings:    []string{`destructive statement: DROP TABLE "posts"`},
+	}}}, nil
+}
+
+func TestMigrate_ProductionConfirms(t *testing.T) {
+	var ran []string
+	ctx := &CommandContext{Migrator: guardMigrator{ran: &ran}, Production: true}
+	cmd := NewMigrateCommand(func() *CommandContext { return ctx })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetIn(bytes.NewBufferString("n\n"))
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Empty(t, ran)
+	assert.Contains(t, out.String(), "This is a production environment. migrate will:")
+	assert.Contains(t, out.String(), `20240102000000_drop_posts: destructive statement: DROP TABLE "posts"`)
+	assert.Contains(t, out.String(), "Operation cancelled.")
+
+	cmd = NewMigrateCommand(func() *CommandContext { return ctx })
+	cmd.SetOut(&out)
+	cmd.SetIn(bytes.NewBufferString("y\n"))
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, []string{"up"}, ran)
+}
+
+func TestMigrate_ProductionForceAndDryRunSkipPrompt(t *testing.T) {
+	for _, flag := range []string{"force", "dry-run"} {
+		var ran []string
+		ctx := &CommandContext{Migrator: guardMigrator{ran: &ran}, Production: true}
+		cmd := NewMigrateCommand(func() *CommandContext { return ctx })
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.Flags().Set(flag, "true"))
+
+		require.NoError(t, cmd.RunE(cmd, nil), flag)
+		assert.Equal(t, []string{"up"}, ran, flag)
+		assert.Empty(t, out.String(), flag)
+	}
+}
+
+func TestMigrate_OutsideProductionDoesNotPrompt(t *testing.T) {
+	var ran []string
+	cmd := NewMigrateCommand(func() *CommandContext {
+		return &CommandContext{Migrator: guardMigrator{ran: &ran}}
+	})
+	cmd.SetOut(&bytes.Buffer{})
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, []string{"up"}, ran)
+}
+
+func TestMigrateRollback_ProductionWithoutTerminal(t *testing.T) {
+	var ran []string
+	ctx := &CommandContext{Migrator: guardMigrator{ran: &ran}, Production: true}
+	cmd := NewMigrateRollbackCommand(func() *CommandContext { return ctx })
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetIn(&bytes.Buffer{})
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+
+	err := cmd.RunE(cmd, nil)
+	require.ErrorIs(t, err, ErrProductionGuard)
+	assert.Contains(t, err.Error(), "--force")
+	assert.Empty(t, ran)
+	assert.Contains(t, errOut.String(), "roll back 20240102000000_drop_posts")
+
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "error", result.Status)
+}
+
+func TestMigrateFresh_ProductionRequiresAllowProduction(t *testing.T) {
+	var ran []string
+	ctx := &CommandContext{Migrator: guardMigrator{ran: &ran}, Production: true}
+	cmd := NewMigrateFreshCommand(func() *CommandContext { return ctx })
+	cmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, cmd.Flags().Set("force", "true"))
+
+	err := cmd.RunE(cmd, nil)
+	require.ErrorIs(t, err, ErrProductionGuard)
+	assert.Empty(t, ran)
+
+	require.NoError(t, cmd.Flags().Set("allow-production", "true"))
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, []string{"fresh"}, ran)
+}
+
+func TestMigrate_ProductionTenantsRequireForce(t *testing.T) {
+	var ran []string
+	ctx := &CommandContext{Migrator: guardMigrator{ran: &ran}, Production: true}
+	cmd := NewMigrateCommand(func() *CommandContext { return ctx })
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(bytes.NewBufferString("y\n"))
+	require.NoError(t, cmd.Flags().Set("tenants", "true"))
+
+	err := cmd.RunE(cmd, nil)
+	require.ErrorIs(t, err, ErrProductionGuard)
+	assert.Contains(t, err.Error(), "--force")
+	assert.Empty(t, ran)
+}
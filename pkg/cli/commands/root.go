@@ -20,16 +20,23 @@ Usage Examples:
   go-migration migrate                  Run all pending migrations
   go-migration migrate:rollback         Rollback the last batch of migrations
   go-migration migrate:rollback --step 2  Rollback the last 2 migrations
+  go-migration migrate:to <name>        Migrate up or down to the named migration
   go-migration migrate:status           Show migration status
+  go-migration migrate:plan             Show what migrate would run, without running it
+  go-migration migrate:lint             Check pending migrations for risky statements
   go-migration migrate:reset            Rollback all migrations
   go-migration migrate:refresh          Reset and re-run all migrations
   go-migration migrate:fresh            Drop all tables and re-run migrations
   go-migration migrate:install          Create the migration tracking table
+  go-migration migrate:unlock           Release a migration lock left by a crashed run
+  go-migration schema:dump --prune      Dump the schema to a baseline and prune migrations
   go-migration make:migration create_users --create=users
   go-migration make:seeder users
   go-migration make:factory users
   go-migration db:seed                  Run all seeders
-  go-migration db:seed --class=users    Run a specific seeder`,
+  go-migration db:seed --class=users    Run a specific seeder
+  go-migration db:seed:status           Show which seeders have run
+  go-migration db:seed:rollback         Rollback the last seed batch`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSchemaDumpCommand creates the "schema:dump" command that writes the
+// current database schema to a baseline SQL file. Running migrate on an
+// empty database loads the baseline instead of replaying the migrations it
+// covers. With --prune the covered migration files are deleted.
+func NewSchemaDumpCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema:dump",
+		Short: "Dump the database schema to a baseline SQL file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Migrator == nil {
+				return fmt.Errorf("migrator not initialized")
+			}
+
+			path, _ := cmd.Flags().GetString("path")
+			if path == "" {
+				path = ctx.SchemaDumpPath
+			}
+			if path == "" {
+				return fmt.Errorf("no schema dump path: set schema_dump in the config or pass --path")
+			}
+
+			// Rollbacks recognise pruned migrations by the baseline at
+			// schema_dump; one written elsewhere would leave them broken.
+			prune, _ := cmd.Flags().GetBool("prune")
+			if prune && filepath.Clean(path) != filepath.Clean(ctx.SchemaDumpPath) {
+				return fmt.Errorf("--prune requires the dump to be written to schema_dump (%q), so that rollbacks stop at the pruned migrations", ctx.SchemaDumpPath)
+			}
+
+			covered, err := ctx.Migrator.DumpSchema(commandContext(cmd), path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Schema dumped to %s (%d migrations)\n", path, len(covered))
+
+			if prune {
+				pruned, err := pruneMigrationFiles(ctx.MigrationDir, covered)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d migration files\n", pruned)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("path", "", "Baseline file to write (default: schema_dump from the config)")
+	cmd.Flags().Bool("prune", false, "Delete the migration files covered by the dump")
+	return cmd
+}
+
+// migrationFileSuffixes are the extensions of the files a migration can
+// be defined in: Go code or a pair of SQL scripts.
+var migrationFileSuffixes = []string{".go", ".up.sql", ".down.sql"}
+
+// pruneMigrationFiles deletes the files of the named migrations from dir
+// and returns how many were removed. Missing files are skipped.
+func pruneMigrationFiles(dir string, names []string) (int, error) {
+	if dir == "" {
+		return 0, fmt.Errorf("no migration directory configured")
+	}
+	pruned := 0
+	for _, name := range names {
+		for _, suffix := range migrationFileSuffixes {
+			err := os.Remove(filepath.Join(dir, name+suffix))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return pruned, fmt.Errorf("prune %q: %w", name, err)
+			}
+			pruned++
+		}
+	}
+	return pruned, nil
+}
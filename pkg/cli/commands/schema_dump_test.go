@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpMigrator is a stubMigrator that records the dump path and reports
+// fixed covered migrations.
+type dumpMigrator struct {
+	stubMigrator
+	path    string
+	covered []string
+}
+
+func (m *dumpMigrator) DumpSchema(_ context.Context, path string) ([]string, error) {
+	m.path = path
+	return m.covered, nil
+}
+
+func TestNewSchemaDumpCommand_BasicSetup(t *testing.T) {
+	cmd := NewSchemaDumpCommand(func() *CommandContext { return nil })
+	assert.Equal(t, "schema:dump", cmd.Use)
+	assert.NotEmpty(t, cmd.Short)
+	assert.NotNil(t, cmd.Flags().Lookup("path"))
+	assert.NotNil(t, cmd.Flags().Lookup("prune"))
+}
+
+func TestNewSchemaDumpCommand_NilContext(t *testing.T) {
+	cmd := NewSchemaDumpCommand(func() *CommandContext { return nil })
+	err := cmd.RunE(cmd, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "migrator not initialized")
+}
+
+func TestNewSchemaDumpCommand_UsesConfiguredPath(t *testing.T) {
+	m := &dumpMigrator{covered: []string{"20240101000000_create_users"}}
+	cmd := NewSchemaDumpCommand(func() *CommandContext {
+		return &CommandContext{Migrator: m, SchemaDumpPath: "migrations/schema.sql"}
+	})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, "migrations/schema.sql", m.path)
+	assert.Contains(t, out.String(), "(1 migrations)")
+
+	require.NoError(t, cmd.Flags().Set("path", "other.sql"))
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Equal(t, "other.sql", m.path)
+}
+
+func TestNewSchemaDumpCommand_Prune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20240101000000_create_users.go", "20240102000000_create_posts.go", "20240103000000_create_tags.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("package migrations\n"), 0o644))
+	}
+
+	m := &dumpMigrator{covered: []string{"20240101000000_create_users", "20240102000000_create_posts", "20231231000000_already_gone"}}
+	cmd := NewSchemaDumpCommand(func() *CommandContext {
+		return &CommandContext{Migrator: m, MigrationDir: dir, SchemaDumpPath: filepath.Join(dir, "schema.sql")}
+	})
+	require.NoError(t, cmd.Flags().Set("prune", "true"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, out.String(), "Pruned 2 migration files")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "20240103000000_create_tags.go", entries[0].Name())
+}
+
+func TestNewSchemaDumpCommand_PruneSQLFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20240101000000_add_index.up.sql", "20240101000000_add_index.down.sql", "20240102000000_seed_roles.up.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;\n"), 0o644))
+	}
+
+	m := &dumpMigrator{covered: []string{"20240101000000_add_index"}}
+	cmd := NewSchemaDumpCommand(func() *CommandContext {
+		return &CommandContext{Migrator: m, MigrationDir: dir, SchemaDumpPath: filepath.Join(dir, "schema.sql")}
+	})
+	require.NoError(t, cmd.Flags().Set("prune", "true"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, out.String(), "Pruned 2 migration files")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "20240102000000_seed_roles.up.sql", entries[0].Name())
+}
+
+func TestNewSchemaDumpCommand_PruneRequiresConfiguredPath(t *testing.T) {
+	m := &dumpMigrator{covered: []string{"20240101000000_create_users"}}
+	cmd := NewSchemaDumpCommand(func() *CommandContext {
+		return &CommandContext{Migrator: m, MigrationDir: t.TempDir(), SchemaDumpPath: "migrations/schema.sql"}
+	})
+	require.NoError(t, cmd.Flags().Set("prune", "true"))
+	require.NoError(t, cmd.Flags().Set("path", "other.sql"))
+
+	err := cmd.RunE(cmd, nil)
+	assert.ErrorContains(t, err, "--prune requires the dump to be written to schema_dump")
+	assert.Empty(t, m.path, "nothing is dumped")
+}
@@ -2,13 +2,20 @@ package commands
 
 import (
 	"fmt"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // NewSeedCommand creates the "db:seed" command that runs database seeders.
 // It supports an optional --class flag to run a specific seeder by name.
-// When --class is empty, all registered seeders are executed.
+// When --class is empty, all registered seeders are executed. Seeders
+// recorded in the seeder tracking table are skipped unless --force is set.
+// --transaction=seeder|all wraps seeders implementing seeder.TxSeeder in
+// transactions; the runner is configured with it when it is created.
+// --parallel=N runs up to N independent seeders at a time and reports how
+// long each took.
 func NewSeedCommand(getCtx func() *CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "db:seed",
@@ -18,24 +25,71 @@ func NewSeedCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Seeder == nil {
 				return fmt.Errorf("seeder runner not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
 			class, err := cmd.Flags().GetString("class")
 			if err != nil {
 				return fmt.Errorf("invalid --class flag: %w", err)
 			}
-			if class != "" {
-				return ctx.Seeder.Run(class)
-			}
 			tag, err := cmd.Flags().GetString("tag")
 			if err != nil {
 				return fmt.Errorf("invalid --tag flag: %w", err)
 			}
-			if tag != "" {
-				return ctx.Seeder.RunByTag(tag)
+			parallel, err := cmd.Flags().GetInt("parallel")
+			if err != nil {
+				return fmt.Errorf("invalid --parallel flag: %w", err)
 			}
-			return ctx.Seeder.RunAll()
+			if parallel > 1 {
+				if class != "" || tag != "" {
+					return fmt.Errorf("--parallel cannot be combined with --class or --tag")
+				}
+				return seedParallel(cmd, ctx, format, parallel)
+			}
+			switch {
+			case class != "":
+				err = ctx.Seeder.RunContext(commandContext(cmd), class)
+			case tag != "":
+				err = ctx.Seeder.RunByTagContext(commandContext(cmd), tag)
+			default:
+				err = ctx.Seeder.RunAllContext(commandContext(cmd))
+			}
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
 	cmd.Flags().String("class", "", "specific seeder class to run")
 	cmd.Flags().String("tag", "", "run only seeders with the specified tag")
+	cmd.Flags().Bool("force", false, "run seeders even if they already ran")
+	cmd.Flags().String("transaction", "none", "wrap seeders in transactions (none, seeder or all)")
+	cmd.Flags().Int("parallel", 1, "run up to this many independent seeders at a time")
+	addOutputFlag(cmd)
 	return cmd
 }
+
+// seedParallel runs all seeders with up to n at a time and prints how long
+// each seeder that completed took.
+func seedParallel(cmd *cobra.Command, ctx *CommandContext, format string, n int) error {
+	timings, err := ctx.Seeder.RunAllParallelContext(commandContext(cmd), n)
+
+	if format != OutputTable {
+		result := &Result{Seeders: make([]SeederResult, len(timings))}
+		for i, t := range timings {
+			result.Seeders[i] = SeederResult{Name: t.Name, Status: "ran", DurationMS: milliseconds(t.Duration)}
+		}
+		return report(cmd, ctx, format, result, err)
+	}
+	if len(timings) == 0 {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Seeder\tDuration")
+	for _, t := range timings {
+		fmt.Fprintf(w, "%s\t%s\n", t.Name, t.Duration.Round(time.Millisecond))
+	}
+	if flushErr := w.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	return err
+}
@@ -7,27 +7,44 @@ import (
 )
 
 // NewSeedRollbackCommand creates the "db:seed:rollback" command that rolls back
-// a specific seeder by name. The --class flag is required and specifies which
-// seeder to roll back.
+// a specific seeder by name with --class, or else the last batch of seeders
+// recorded in the seeder tracking table, in reverse dependency order.
 func NewSeedRollbackCommand(getCtx func() *CommandContext) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "db:seed:rollback",
-		Short: "Rollback a specific database seeder",
+		Short: "Rollback the last seed batch or a specific seeder",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := getCtx()
 			if ctx == nil || ctx.Seeder == nil {
 				return fmt.Errorf("seeder runner not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
 			class, err := cmd.Flags().GetString("class")
 			if err != nil {
 				return fmt.Errorf("invalid --class flag: %w", err)
 			}
+			if guardsProduction(cmd, ctx) {
+				reasons := []string{"roll back the last seed batch"}
+				if class != "" {
+					reasons = []string{fmt.Sprintf("roll back seeder %q", class)}
+				}
+				if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+					return err
+				}
+			}
 			if class == "" {
-				return fmt.Errorf("--class flag is required")
+				err = ctx.Seeder.RollbackLastBatchContext(commandContext(cmd))
+			} else {
+				err = ctx.Seeder.RollbackContext(commandContext(cmd), class)
 			}
-			return ctx.Seeder.Rollback(class)
+			return report(cmd, ctx, format, nil, err)
 		},
 	}
-	cmd.Flags().String("class", "", "seeder class to rollback (required)")
+	cmd.Flags().String("class", "", "seeder class to rollback instead of the last batch")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
 	return cmd
 }
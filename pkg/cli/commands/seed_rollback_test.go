@@ -38,12 +38,11 @@ func TestNewSeedRollbackCommand_NilSeeder(t *testing.T) {
 	assert.Contains(t, err.Error(), "seeder runner not initialized")
 }
 
-func TestNewSeedRollbackCommand_EmptyClass(t *testing.T) {
+func TestNewSeedRollbackCommand_LastBatchRequiresTracking(t *testing.T) {
 	runner := seeder.NewRunner(seeder.NewRegistry(), nil, nil)
 	cmd := NewSeedRollbackCommand(func() *CommandContext {
 		return &CommandContext{Seeder: runner}
 	})
 	err := cmd.RunE(cmd, nil)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "--class flag is required")
+	assert.ErrorIs(t, err, seeder.ErrTrackingDisabled)
 }
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
+	"github.com/spf13/cobra"
+)
+
+// NewSeedStatusCommand creates the "db:seed:status" command that displays
+// whether each registered seeder has run, from the seeder tracking table.
+func NewSeedStatusCommand(getCtx func() *CommandContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db:seed:status",
+		Short: "Show the status of each seeder",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := getCtx()
+			if ctx == nil || ctx.Seeder == nil {
+				return fmt.Errorf("seeder runner not initialized")
+			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			statuses, err := ctx.Seeder.StatusContext(commandContext(cmd))
+			if err != nil || format != OutputTable {
+				return report(cmd, ctx, format, &Result{Seeders: seederResults(statuses)}, err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "Seeder\tStatus\tBatch\tRan At")
+			for _, s := range seederResults(statuses) {
+				batch := ""
+				ranAt := ""
+				if s.Batch > 0 {
+					batch = fmt.Sprintf("%d", s.Batch)
+				}
+				if s.RanAt != nil {
+					ranAt = s.RanAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, seederStatusLabels[s.Status], batch, ranAt)
+			}
+			return w.Flush()
+		},
+	}
+	addOutputFlag(cmd)
+	return cmd
+}
+
+// seederStatusLabels are the table labels of the SeederResult statuses.
+var seederStatusLabels = map[string]string{
+	"pending": "Pending",
+	"ran":     "Ran",
+	"changed": "Changed",
+}
+
+// seederResults converts statuses to their --output representation.
+func seederResults(statuses []seeder.SeederStatus) []SeederResult {
+	results := make([]SeederResult, len(statuses))
+	for i, s := range statuses {
+		r := SeederResult{Name: s.Name, Status: "pending"}
+		if s.Ran {
+			r.Status = "ran"
+			if s.Changed {
+				r.Status = "changed"
+			}
+			r.Batch = s.Batch
+			r.RanAt = s.RanAt
+			r.Version = s.Version
+		}
+		results[i] = r
+	}
+	return results
+}
@@ -1,8 +1,13 @@
 package commands
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,3 +44,81 @@ func TestNewSeedCommand_HelpText(t *testing.T) {
 	cmd := NewSeedCommand(func() *CommandContext { return nil })
 	assert.Contains(t, cmd.Short, "seed")
 }
+
+func TestNewSeedCommand_ForceFlag(t *testing.T) {
+	cmd := NewSeedCommand(func() *CommandContext { return nil })
+	flag := cmd.Flags().Lookup("force")
+	require.NotNil(t, flag, "--force flag should be registered")
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+func TestNewSeedCommand_TransactionFlag(t *testing.T) {
+	cmd := NewSeedCommand(func() *CommandContext { return nil })
+	flag := cmd.Flags().Lookup("transaction")
+	require.NotNil(t, flag, "--transaction flag should be registered")
+	assert.Equal(t, "none", flag.DefValue)
+}
+
+// noopSeeder does nothing.
+type noopSeeder struct{}
+
+func (noopSeeder) Run(*sql.DB) error { return nil }
+
+func TestNewSeedCommand_ParallelFlag(t *testing.T) {
+	cmd := NewSeedCommand(func() *CommandContext { return nil })
+	flag := cmd.Flags().Lookup("parallel")
+	require.NotNil(t, flag, "--parallel flag should be registered")
+	assert.Equal(t, "1", flag.DefValue)
+}
+
+func TestNewSeedCommand_ParallelRejectsClass(t *testing.T) {
+	runner := seeder.NewRunner(seeder.NewRegistry(), nil, nil)
+	cmd := NewSeedCommand(func() *CommandContext { return &CommandContext{Seeder: runner} })
+	require.NoError(t, cmd.Flags().Set("parallel", "4"))
+	require.NoError(t, cmd.Flags().Set("class", "UserSeeder"))
+
+	err := cmd.RunE(cmd, nil)
+	assert.ErrorContains(t, err, "--parallel cannot be combined with --class or --tag")
+}
+
+func TestNewSeedCommand_ParallelReportsTimings(t *testing.T) {
+	reg := seeder.NewRegistry()
+	require.NoError(t, reg.Register("users", &noopSeeder{}))
+	require.NoError(t, reg.Register("posts", &noopSeeder{}))
+	cmd := NewSeedCommand(func() *CommandContext { return &CommandContext{Seeder: seeder.NewRunner(reg, nil, nil)} })
+	require.NoError(t, cmd.Flags().Set("parallel", "2"))
+	require.NoError(t, cmd.Flags().Set("output", "json"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	require.NoError(t, cmd.RunE(cmd, nil))
+	var result Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	require.Len(t, result.Seeders, 2)
+	names := []string{result.Seeders[0].Name, result.Seeders[1].Name}
+	assert.ElementsMatch(t, []string{"users", "posts"}, names)
+	assert.Equal(t, "ran", result.Seeders[0].Status)
+}
+
+func TestSeederResults(t *testing.T) {
+	ranAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	results := seederResults([]seeder.SeederStatus{
+		{Name: "roles", Ran: true, Batch: 1, RanAt: &ranAt, Version: "v1"},
+		{Name: "users", Ran: true, Batch: 2, RanAt: &ranAt, Version: "v1", Changed: true},
+		{Name: "posts"},
+	})
+
+	assert.Equal(t, []SeederResult{
+		{Name: "roles", Status: "ran", Batch: 1, RanAt: &ranAt, Version: "v1"},
+		{Name: "users", Status: "changed", Batch: 2, RanAt: &ranAt, Version: "v1"},
+		{Name: "posts", Status: "pending"},
+	}, results)
+}
+
+func TestNewSeedStatusCommand_RequiresTracking(t *testing.T) {
+	runner := seeder.NewRunner(seeder.NewRegistry(), nil, nil)
+	cmd := NewSeedStatusCommand(func() *CommandContext { return &CommandContext{Seeder: runner} })
+
+	err := cmd.RunE(cmd, nil)
+	assert.ErrorIs(t, err, seeder.ErrTrackingDisabled)
+}
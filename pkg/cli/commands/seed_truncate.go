@@ -18,6 +18,10 @@ func NewSeedTruncateCommand(getCtx func() *CommandContext) *cobra.Command {
 			if ctx == nil || ctx.Seeder == nil {
 				return fmt.Errorf("seeder runner not initialized")
 			}
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
 			table, err := cmd.Flags().GetString("table")
 			if err != nil {
 				return fmt.Errorf("invalid --table flag: %w", err)
@@ -25,9 +29,17 @@ func NewSeedTruncateCommand(getCtx func() *CommandContext) *cobra.Command {
 			if table == "" {
 				return fmt.Errorf("--table flag is required")
 			}
-			return ctx.Seeder.Truncate(table)
+			if guardsProduction(cmd, ctx) {
+				reasons := []string{fmt.Sprintf("delete every row of table %q", table)}
+				if ok, err := confirmProduction(cmd, ctx, format, reasons); !ok {
+					return err
+				}
+			}
+			return report(cmd, ctx, format, nil, ctx.Seeder.TruncateContext(commandContext(cmd), table))
 		},
 	}
 	cmd.Flags().String("table", "", "table to truncate (required)")
+	cmd.Flags().Bool("force", false, "Skip the production confirmation")
+	addOutputFlag(cmd)
 	return cmd
 }
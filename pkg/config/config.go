@@ -18,16 +18,61 @@ import (
 // ErrConfigValidation is returned when configuration validation fails.
 var ErrConfigValidation = errors.New("configuration validation failed")
 
+// DefaultLockTimeout is the LockTimeout set by ApplyDefaults, and the
+// migrator's default when no timeout is configured.
+const DefaultLockTimeout = time.Minute
+
 // Config holds the top-level configuration for go-migration.
 type Config struct {
 	Connections    map[string]ConnectionConfig `yaml:"connections" json:"connections"`
 	DefaultConn    string                      `yaml:"default" json:"default"`
 	MigrationTable string                      `yaml:"migration_table" json:"migration_table"`
+	SeederTable    string                      `yaml:"seeder_table" json:"seeder_table"`
 	MigrationDir   string                      `yaml:"migration_dir" json:"migration_dir"`
 	SeederDir      string                      `yaml:"seeder_dir" json:"seeder_dir"`
 	FactoryDir     string                      `yaml:"factory_dir" json:"factory_dir"`
 	LogLevel       string                      `yaml:"log_level" json:"log_level"`
 	LogOutput      string                      `yaml:"log_output" json:"log_output"`
+	LockTimeout    time.Duration               `yaml:"lock_timeout" json:"lock_timeout"`
+	SchemaDump     string                      `yaml:"schema_dump" json:"schema_dump"`
+	Tenants        TenantsConfig               `yaml:"tenants" json:"tenants"`
+	Lint           LintConfig                  `yaml:"lint" json:"lint"`
+	// SeederTracking makes db:seed record the seeders it ran in
+	// SeederTable (default "seeders") and skip them afterwards. It is off
+	// by default and turned on by setting either field.
+	SeederTracking bool `yaml:"seeder_tracking" json:"seeder_tracking"`
+	// Environment names the deployment, such as "staging". In
+	// "production" the CLI asks before destructive operations.
+	Environment string `yaml:"environment" json:"environment"`
+}
+
+// EnvironmentProduction is the Environment in which destructive CLI
+// operations require confirmation.
+const EnvironmentProduction = "production"
+
+// IsProduction reports whether Environment is "production", ignoring case.
+func (c *Config) IsProduction() bool {
+	return strings.EqualFold(c.Environment, EnvironmentProduction)
+}
+
+// TenantsConfig configures the tenants migrate --tenants fans out to.
+// Tenants are listed in List, or returned by Query on the default
+// connection.
+type TenantsConfig struct {
+	List  []string `yaml:"list" json:"list"`
+	Query string   `yaml:"query" json:"query"`
+	// Mode is "schema" to set the PostgreSQL search_path to the tenant, or
+	// "database" to connect to the database named after the tenant. It
+	// defaults to "schema" on PostgreSQL and "database" otherwise.
+	Mode string `yaml:"mode" json:"mode"`
+	// Parallelism is the number of tenants migrated at once (default 1).
+	Parallelism int `yaml:"parallelism" json:"parallelism"`
+}
+
+// LintConfig configures migrate:lint.
+type LintConfig struct {
+	// Disable lists the lint rules that are not checked.
+	Disable []string `yaml:"disable" json:"disable"`
 }
 
 // ConnectionConfig holds the configuration for a single database connection.
@@ -42,6 +87,19 @@ type ConnectionConfig struct {
 	MaxIdleConns    int               `yaml:"max_idle_conns" json:"max_idle_conns"`
 	ConnMaxLifetime time.Duration     `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
 	Options         map[string]string `yaml:"options" json:"options"`
+	// Grammar overrides the SQL grammar derived from Driver ("postgres",
+	// "mysql" or "sqlite") for compatible engines such as CockroachDB,
+	// MariaDB or libSQL.
+	Grammar string `yaml:"grammar" json:"grammar"`
+}
+
+// GrammarName returns the grammar the connection uses: Grammar when set,
+// otherwise Driver.
+func (c ConnectionConfig) GrammarName() string {
+	if c.Grammar != "" {
+		return c.Grammar
+	}
+	return c.Driver
 }
 
 // ApplyDefaults sets sensible default values for optional settings.
@@ -49,6 +107,12 @@ func (c *Config) ApplyDefaults() {
 	if c.MigrationTable == "" {
 		c.MigrationTable = "migrations"
 	}
+	if c.SeederTracking && c.SeederTable == "" {
+		c.SeederTable = "seeders"
+	}
+	if c.SeederTable != "" {
+		c.SeederTracking = true
+	}
 	if c.MigrationDir == "" {
 		c.MigrationDir = "migrations"
 	}
@@ -64,6 +128,12 @@ func (c *Config) ApplyDefaults() {
 	if c.LogOutput == "" {
 		c.LogOutput = "console"
 	}
+	if c.LockTimeout == 0 {
+		c.LockTimeout = DefaultLockTimeout
+	}
+	if c.SchemaDump == "" {
+		c.SchemaDump = filepath.Join(c.MigrationDir, "schema.sql")
+	}
 	if c.Connections == nil {
 		c.Connections = make(map[string]ConnectionConfig)
 	}
@@ -78,7 +148,8 @@ func (c *Config) Validate() error {
 		violations = append(violations, "at least one connection must be defined")
 	}
 
-	validDrivers := map[string]bool{"postgres": true, "mysql": true, "sqlite": true}
+	validDrivers := map[string]bool{"postgres": true, "mysql": true, "sqlite": true, "sqlite3": true}
+	validGrammars := map[string]bool{"postgres": true, "mysql": true, "sqlite": true}
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	validLogOutputs := map[string]bool{"console": true, "file": true, "both": true}
 
@@ -86,7 +157,10 @@ func (c *Config) Validate() error {
 		if conn.Driver == "" {
 			violations = append(violations, fmt.Sprintf("connections.%s.driver is required", name))
 		} else if !validDrivers[conn.Driver] {
-			violations = append(violations, fmt.Sprintf("connections.%s.driver must be one of: postgres, mysql, sqlite", name))
+			violations = append(violations, fmt.Sprintf("connections.%s.driver must be one of: postgres, mysql, sqlite, sqlite3", name))
+		}
+		if conn.Grammar != "" && !validGrammars[conn.Grammar] {
+			violations = append(violations, fmt.Sprintf("connections.%s.grammar must be one of: postgres, mysql, sqlite", name))
 		}
 		if conn.Host == "" {
 			violations = append(violations, fmt.Sprintf("connections.%s.host is required", name))
@@ -111,6 +185,18 @@ func (c *Config) Validate() error {
 	if c.LogOutput != "" && !validLogOutputs[c.LogOutput] {
 		violations = append(violations, "log_output must be one of: console, file, both")
 	}
+	if c.LockTimeout < 0 {
+		violations = append(violations, "lock_timeout must be non-negative")
+	}
+	if len(c.Tenants.List) > 0 && c.Tenants.Query != "" {
+		violations = append(violations, "tenants.list and tenants.query are mutually exclusive")
+	}
+	if c.Tenants.Mode != "" && c.Tenants.Mode != "schema" && c.Tenants.Mode != "database" {
+		violations = append(violations, "tenants.mode must be one of: schema, database")
+	}
+	if c.Tenants.Parallelism < 0 {
+		violations = append(violations, "tenants.parallelism must be non-negative")
+	}
 
 	if len(violations) > 0 {
 		return fmt.Errorf("%w: %s", ErrConfigValidation, strings.Join(violations, ", "))
@@ -163,12 +249,31 @@ func LoadFromEnv() (*Config, error) {
 	// Top-level settings
 	cfg.DefaultConn = getEnv("GOMIGRATE_DEFAULT_CONNECTION", "default")
 	cfg.MigrationTable = getEnv("GOMIGRATE_MIGRATION_TABLE", "")
+	cfg.SeederTable = getEnv("GOMIGRATE_SEEDER_TABLE", "")
 	cfg.MigrationDir = getEnv("GOMIGRATE_MIGRATION_DIR", "")
 	cfg.SeederDir = getEnv("GOMIGRATE_SEEDER_DIR", "")
 	cfg.FactoryDir = getEnv("GOMIGRATE_FACTORY_DIR", "")
 	cfg.LogLevel = getEnv("GOMIGRATE_LOG_LEVEL", "")
 	cfg.LogOutput = getEnv("GOMIGRATE_LOG_OUTPUT", "")
 
+	cfg.SchemaDump = getEnv("GOMIGRATE_SCHEMA_DUMP", "")
+	if trackingStr := getEnv("GOMIGRATE_SEEDER_TRACKING", ""); trackingStr != "" {
+		tracking, err := strconv.ParseBool(trackingStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOMIGRATE_SEEDER_TRACKING value %q: %w", trackingStr, err)
+		}
+		cfg.SeederTracking = tracking
+	}
+	cfg.Environment = getEnv("GOMIGRATE_ENV", "")
+
+	if timeoutStr := getEnv("GOMIGRATE_LOCK_TIMEOUT", ""); timeoutStr != "" {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOMIGRATE_LOCK_TIMEOUT value %q: %w", timeoutStr, err)
+		}
+		cfg.LockTimeout = timeout
+	}
+
 	// Build default connection from env vars
 	conn := ConnectionConfig{
 		Driver:   getEnv("GOMIGRATE_DB_DRIVER", ""),
@@ -176,6 +281,7 @@ func LoadFromEnv() (*Config, error) {
 		Database: getEnv("GOMIGRATE_DB_DATABASE", ""),
 		Username: getEnv("GOMIGRATE_DB_USERNAME", ""),
 		Password: getEnv("GOMIGRATE_DB_PASSWORD", ""),
+		Grammar:  getEnv("GOMIGRATE_DB_GRAMMAR", ""),
 	}
 
 	if portStr := getEnv("GOMIGRATE_DB_PORT", ""); portStr != "" {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -169,6 +170,8 @@ func TestLoadFromEnvNoDBVars(t *testing.T) {
 
 	// Should have defaults applied
 	assert.Equal(t, "migrations", cfg.MigrationTable)
+	assert.Empty(t, cfg.SeederTable, "seeder tracking is off by default")
+	assert.False(t, cfg.SeederTracking)
 	assert.Equal(t, "migrations", cfg.MigrationDir)
 	assert.Equal(t, "seeders", cfg.SeederDir)
 	assert.Equal(t, "info", cfg.LogLevel)
@@ -237,6 +240,17 @@ func TestValidatePartialMissingFields(t *testing.T) {
 	assert.NotContains(t, err.Error(), "connections.partial.driver")
 }
 
+func TestApplyDefaults_SeederTracking(t *testing.T) {
+	cfg := &Config{SeederTracking: true}
+	cfg.ApplyDefaults()
+	assert.Equal(t, "seeders", cfg.SeederTable)
+
+	cfg = &Config{SeederTable: "seed_runs"}
+	cfg.ApplyDefaults()
+	assert.True(t, cfg.SeederTracking, "setting seeder_table enables tracking")
+	assert.Equal(t, "seed_runs", cfg.SeederTable)
+}
+
 func TestApplyDefaults(t *testing.T) {
 	cfg := &Config{}
 	cfg.ApplyDefaults()
@@ -246,6 +260,8 @@ func TestApplyDefaults(t *testing.T) {
 	assert.Equal(t, "seeders", cfg.SeederDir)
 	assert.Equal(t, "info", cfg.LogLevel)
 	assert.Equal(t, "console", cfg.LogOutput)
+	assert.Equal(t, time.Minute, cfg.LockTimeout)
+	assert.Equal(t, filepath.Join("migrations", "schema.sql"), cfg.SchemaDump)
 	assert.NotNil(t, cfg.Connections)
 }
 
@@ -360,7 +376,7 @@ func TestValidateInvalidDriver(t *testing.T) {
 	err := cfg.Validate()
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrConfigValidation)
-	assert.Contains(t, err.Error(), "connections.default.driver must be one of: postgres, mysql, sqlite")
+	assert.Contains(t, err.Error(), "connections.default.driver must be one of: postgres, mysql, sqlite, sqlite3")
 }
 
 func TestValidateNegativePort(t *testing.T) {
@@ -532,7 +548,7 @@ func TestValidateValidLogOutputs(t *testing.T) {
 }
 
 func TestValidateValidDrivers(t *testing.T) {
-	for _, driver := range []string{"postgres", "mysql", "sqlite"} {
+	for _, driver := range []string{"postgres", "mysql", "sqlite", "sqlite3"} {
 		cfg := &Config{
 			Connections: map[string]ConnectionConfig{
 				"default": {
@@ -546,3 +562,81 @@ func TestValidateValidDrivers(t *testing.T) {
 		assert.NoError(t, err, "driver %q should be valid", driver)
 	}
 }
+
+func TestValidateGrammarOverride(t *testing.T) {
+	cfg := &Config{
+		Connections: map[string]ConnectionConfig{
+			"cockroach": {Driver: "postgres", Grammar: "postgres", Host: "localhost", Database: "app"},
+			"broken":    {Driver: "mysql", Grammar: "oracle", Host: "localhost", Database: "app"},
+		},
+	}
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, ErrConfigValidation)
+	assert.Contains(t, err.Error(), "connections.broken.grammar must be one of: postgres, mysql, sqlite")
+	assert.NotContains(t, err.Error(), "connections.cockroach")
+}
+
+func TestConnectionConfig_GrammarName(t *testing.T) {
+	assert.Equal(t, "mysql", ConnectionConfig{Driver: "mysql"}.GrammarName())
+	assert.Equal(t, "sqlite", ConnectionConfig{Driver: "sqlite3", Grammar: "sqlite"}.GrammarName())
+}
+
+func TestValidateTenants(t *testing.T) {
+	cfg := &Config{
+		Connections: map[string]ConnectionConfig{
+			"default": {Driver: "postgres", Host: "localhost", Database: "app"},
+		},
+		Tenants: TenantsConfig{List: []string{"acme"}, Query: "SELECT name FROM tenants", Mode: "table", Parallelism: -1},
+	}
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, ErrConfigValidation)
+	assert.Contains(t, err.Error(), "tenants.list and tenants.query are mutually exclusive")
+	assert.Contains(t, err.Error(), "tenants.mode must be one of: schema, database")
+	assert.Contains(t, err.Error(), "tenants.parallelism must be non-negative")
+
+	cfg.Tenants = TenantsConfig{Query: "SELECT name FROM tenants", Mode: "schema", Parallelism: 4}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadYAML_Tenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+connections:
+  default:
+    driver: postgres
+    host: localhost
+    database: app
+tenants:
+  list: [acme, globex]
+  parallelism: 4
+`), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "globex"}, cfg.Tenants.List)
+	assert.Equal(t, 4, cfg.Tenants.Parallelism)
+}
+
+func TestIsProduction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+environment: Production
+connections:
+  default:
+    driver: postgres
+    host: localhost
+    database: app
+`), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.IsProduction())
+
+	t.Setenv("GOMIGRATE_ENV", "staging")
+	cfg, err = LoadFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "staging", cfg.Environment)
+	assert.False(t, cfg.IsProduction())
+}
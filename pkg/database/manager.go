@@ -114,6 +114,26 @@ func (m *Manager) Default() (*sql.DB, error) {
 	return m.Connection(name)
 }
 
+// DefaultName returns the name of the default connection, or an empty
+// string if no connection has been added.
+func (m *Manager) DefaultName() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.defaultName
+}
+
+// Config returns the stored configuration for the named connection.
+func (m *Manager) Config(name string) (ConnectionConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	config, ok := m.configs[name]
+	if !ok {
+		return ConnectionConfig{}, fmt.Errorf("connection %q: %w", name, ErrConnectionNotFound)
+	}
+	return config, nil
+}
+
 // SetDefault sets the default connection name. The named connection must
 // already be registered via AddConnection.
 func (m *Manager) SetDefault(name string) error {
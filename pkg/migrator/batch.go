@@ -1,6 +1,9 @@
 package migrator
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // BatchManager provides batch-level operations on top of the Tracker.
 type BatchManager struct {
@@ -15,7 +18,12 @@ func NewBatchManager(tracker *Tracker) *BatchManager {
 // NextBatchNumber returns the next batch number to use for a new migration run.
 // It is always one greater than the current highest batch number.
 func (b *BatchManager) NextBatchNumber() (int, error) {
-	last, err := b.tracker.GetLastBatchNumber()
+	return b.NextBatchNumberContext(context.Background())
+}
+
+// NextBatchNumberContext is like NextBatchNumber but executes with ctx.
+func (b *BatchManager) NextBatchNumberContext(ctx context.Context) (int, error) {
+	last, err := b.tracker.GetLastBatchNumberContext(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("next batch number: %w", err)
 	}
@@ -25,14 +33,19 @@ func (b *BatchManager) NextBatchNumber() (int, error) {
 // GetLastBatch returns all migration records from the most recent batch.
 // Returns an empty slice if no migrations have been applied.
 func (b *BatchManager) GetLastBatch() ([]MigrationRecord, error) {
-	last, err := b.tracker.GetLastBatchNumber()
+	return b.GetLastBatchContext(context.Background())
+}
+
+// GetLastBatchContext is like GetLastBatch but executes with ctx.
+func (b *BatchManager) GetLastBatchContext(ctx context.Context) ([]MigrationRecord, error) {
+	last, err := b.tracker.GetLastBatchNumberContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get last batch: %w", err)
 	}
 	if last == 0 {
 		return nil, nil
 	}
-	records, err := b.tracker.GetByBatch(last)
+	records, err := b.tracker.GetByBatchContext(ctx, last)
 	if err != nil {
 		return nil, fmt.Errorf("get last batch: %w", err)
 	}
@@ -42,10 +55,15 @@ func (b *BatchManager) GetLastBatch() ([]MigrationRecord, error) {
 // GetLastNMigrations returns the last N applied migrations in reverse
 // timestamp order. If fewer than N migrations exist, all are returned.
 func (b *BatchManager) GetLastNMigrations(n int) ([]MigrationRecord, error) {
+	return b.GetLastNMigrationsContext(context.Background(), n)
+}
+
+// GetLastNMigrationsContext is like GetLastNMigrations but executes with ctx.
+func (b *BatchManager) GetLastNMigrationsContext(ctx context.Context, n int) ([]MigrationRecord, error) {
 	if n <= 0 {
 		return nil, nil
 	}
-	applied, err := b.tracker.GetApplied()
+	applied, err := b.tracker.GetAppliedContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get last %d migrations: %w", n, err)
 	}
@@ -27,7 +27,7 @@ func TestNextBatchNumber_FirstBatch(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
@@ -42,7 +42,7 @@ func TestNextBatchNumber_SubsequentBatch(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(5))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
@@ -57,7 +57,7 @@ func TestNextBatchNumber_Error(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnError(errors.New("db error"))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
@@ -76,15 +76,15 @@ func TestGetLastBatch_WithRecords(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 
 	// GetLastBatchNumber
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(3))
 
 	// GetByBatch(3)
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1").
 		WithArgs(3).
-		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-			AddRow("20240315000000_add_posts", 3, now).
-			AddRow("20240316000000_add_comments", 3, now))
+		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+			AddRow("20240315000000_add_posts", 3, now, nil, nil).
+			AddRow("20240316000000_add_comments", 3, now, nil, nil))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
 	records, err := bm.GetLastBatch()
@@ -101,7 +101,7 @@ func TestGetLastBatch_NoRecords(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
@@ -116,7 +116,7 @@ func TestGetLastBatch_BatchNumberError(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnError(errors.New("db error"))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
@@ -130,10 +130,10 @@ func TestGetLastBatch_GetByBatchError(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1").
 		WithArgs(2).
 		WillReturnError(errors.New("db error"))
 
@@ -151,12 +151,12 @@ func TestGetLastNMigrations_ReturnsReversed(t *testing.T) {
 	defer db.Close()
 
 	now := time.Now().Truncate(time.Second)
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
-		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-			AddRow("20240101000000_first", 1, now).
-			AddRow("20240201000000_second", 1, now).
-			AddRow("20240301000000_third", 2, now).
-			AddRow("20240401000000_fourth", 2, now))
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
+		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+			AddRow("20240101000000_first", 1, now, nil, nil).
+			AddRow("20240201000000_second", 1, now, nil, nil).
+			AddRow("20240301000000_third", 2, now, nil, nil).
+			AddRow("20240401000000_fourth", 2, now, nil, nil))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
 	records, err := bm.GetLastNMigrations(2)
@@ -174,10 +174,10 @@ func TestGetLastNMigrations_NExceedsTotal(t *testing.T) {
 	defer db.Close()
 
 	now := time.Now().Truncate(time.Second)
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
-		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-			AddRow("20240101000000_first", 1, now).
-			AddRow("20240201000000_second", 1, now))
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
+		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+			AddRow("20240101000000_first", 1, now, nil, nil).
+			AddRow("20240201000000_second", 1, now, nil, nil))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
 	records, err := bm.GetLastNMigrations(10)
@@ -215,8 +215,8 @@ func TestGetLastNMigrations_NoApplied(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
-		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at"}))
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
+		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
 	records, err := bm.GetLastNMigrations(5)
@@ -230,7 +230,7 @@ func TestGetLastNMigrations_QueryError(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
 		WillReturnError(errors.New("db error"))
 
 	bm := NewBatchManager(NewTracker(db, "migrations"))
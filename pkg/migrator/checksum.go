@@ -0,0 +1,166 @@
+package migrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// ChecksumMismatch describes an applied migration whose SQL has changed
+// since it was applied.
+type ChecksumMismatch struct {
+	Name string
+	// Applied is the checksum recorded in the tracking table.
+	Applied string
+	// Current is the checksum of the SQL the migration compiles to now.
+	Current string
+}
+
+// Checksummer is implemented by migrations that identify their own source,
+// such as a hash of the file they were loaded from or a version string
+// bumped whenever the migration is edited. Its result is recorded and
+// compared instead of the checksum of the migration's compiled SQL.
+type Checksummer interface {
+	Checksum() string
+}
+
+// errNeedsDatabase is returned to read queries made while a migration is
+// compiled for its checksum, which must not depend on the live schema.
+var errNeedsDatabase = errors.New("migration reads the database")
+
+// offlineReader answers every read query with errNeedsDatabase.
+var offlineReader = sql.OpenDB(offlineConnector{})
+
+// offlineConnector is a driver.Connector that never connects.
+type offlineConnector struct{}
+
+func (offlineConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errNeedsDatabase
+}
+
+func (offlineConnector) Driver() driver.Driver { return offlineDriver{} }
+
+// offlineDriver is the driver.Driver of offlineConnector.
+type offlineDriver struct{}
+
+func (offlineDriver) Open(string) (driver.Conn, error) { return nil, errNeedsDatabase }
+
+// Checksum returns the hex-encoded SHA-256 of the given SQL statements.
+// Statement order is significant.
+func Checksum(statements []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(statements, ";\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify compares each applied migration's recorded checksum with its
+// current one and returns the migrations that differ. Migrations applied
+// before checksums were tracked, migrations whose checksum depends on the
+// database, and applied migrations that are no longer registered, such as
+// those pruned into a schema dump, are skipped and logged. Verify neither executes nor compiles against the database.
+func (m *Migrator) Verify() ([]ChecksumMismatch, error) {
+	return m.VerifyContext(context.Background())
+}
+
+// VerifyContext is like Verify but executes with ctx.
+func (m *Migrator) VerifyContext(ctx context.Context) ([]ChecksumMismatch, error) {
+	var mismatches []ChecksumMismatch
+	err := m.eachConnection(func(c *Migrator) error {
+		found, err := c.verify(ctx)
+		mismatches = append(mismatches, found...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mismatches, nil
+}
+
+// verify implements Verify for a single connection.
+func (m *Migrator) verify(ctx context.Context) ([]ChecksumMismatch, error) {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.tracker.GetAppliedContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []ChecksumMismatch
+	for _, rec := range applied {
+		if rec.Checksum == "" {
+			continue
+		}
+		migration, err := m.registry.Get(rec.Name)
+		if err != nil {
+			if m.logger != nil {
+				m.logger.Info("Skipping checksum of %s: the migration is not registered", rec.Name)
+			}
+			continue
+		}
+		current, err := m.currentChecksum(ctx, migration)
+		if err != nil {
+			return nil, fmt.Errorf("checksum %q: %w", rec.Name, err)
+		}
+		if current != "" && current != rec.Checksum {
+			mismatches = append(mismatches, ChecksumMismatch{
+				Name:    rec.Name,
+				Applied: rec.Checksum,
+				Current: current,
+			})
+		}
+	}
+	return mismatches, nil
+}
+
+// executedChecksum returns the checksum recorded for a migration that ran
+// on recorder: its own checksum when it implements Checksummer, and
+// otherwise the checksum of the statements it executed. It is empty when
+// the migration made read queries, like the checksum currentChecksum
+// computes for it, since its SQL then depends on the schema it ran on.
+func executedChecksum(migration Migration, recorder *schema.RecordingExecutor) string {
+	if c, ok := checksummerOf(migration); ok {
+		return c.Checksum()
+	}
+	if recorder.Reads > 0 {
+		return ""
+	}
+	return Checksum(recorder.Statements)
+}
+
+// currentChecksum returns the checksum of the migration's source. It is the
+// migration's own checksum when it implements Checksummer, and otherwise
+// the checksum of the SQL its Up method compiles to without a database.
+// The checksum is empty when compiling needs a read query such as
+// HasTable, since its SQL then depends on the schema it runs against.
+func (m *Migrator) currentChecksum(ctx context.Context, migration Migration) (string, error) {
+	if c, ok := checksummerOf(migration); ok {
+		return c.Checksum(), nil
+	}
+	statements, err := m.runner.compileWith(ctx, offlineReader, migration, "up")
+	if errors.Is(err, errNeedsDatabase) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return Checksum(statements), nil
+}
+
+// checksummerOf returns the Checksummer of migration, looking through the
+// adapter of a PhasedMigration.
+func checksummerOf(migration Migration) (Checksummer, bool) {
+	if pm, ok := migration.(*phasedMigration); ok {
+		c, ok := pm.PhasedMigration.(Checksummer)
+		return c, ok
+	}
+	c, ok := migration.(Checksummer)
+	return c, ok
+}
@@ -0,0 +1,148 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum_DependsOnStatementsAndOrder(t *testing.T) {
+	a := Checksum([]string{"CREATE TABLE a ()", "CREATE TABLE b ()"})
+	b := Checksum([]string{"CREATE TABLE b ()", "CREATE TABLE a ()"})
+
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, Checksum([]string{"CREATE TABLE a ()", "CREATE TABLE b ()"}))
+	assert.NotEqual(t, a, b)
+}
+
+func TestUp_RecordsChecksumOfExecutedSQL(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+	expectMaxBatch(mock, 0)
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO").
+		WithArgs("20240101000000_create_users", 1, Checksum([]string{"CREATE TABLE users ()"})).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, m.Up())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerify_ReportsModifiedMigrations(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_posts", &createTableMigration{}))
+	require.NoError(t, m.Register("20240103000000_legacy", &createTableMigration{}))
+
+	current := Checksum([]string{"CREATE TABLE users ()"})
+	expectEnsureTable(mock)
+	expectGetApplied(mock, []MigrationRecord{
+		{Name: "20240101000000_create_users", Batch: 1, CreatedAt: time.Now(), Checksum: current},
+		{Name: "20240102000000_create_posts", Batch: 1, CreatedAt: time.Now(), Checksum: "stale"},
+		{Name: "20240103000000_legacy", Batch: 1, CreatedAt: time.Now()},
+	})
+
+	mismatches, err := m.Verify()
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, ChecksumMismatch{
+		Name:    "20240102000000_create_posts",
+		Applied: "stale",
+		Current: current,
+	}, mismatches[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus_FlagsModifiedMigrations(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_posts", &createTableMigration{}))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, []MigrationRecord{
+		{Name: "20240101000000_create_users", Batch: 1, CreatedAt: time.Now(), Checksum: Checksum([]string{"CREATE TABLE users ()"})},
+		{Name: "20240102000000_create_posts", Batch: 1, CreatedAt: time.Now(), Checksum: "stale"},
+	})
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Modified)
+	assert.True(t, statuses[1].Modified)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus_SkipsChecksumsThatDependOnTheSchema(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_profiles", &profilesMigration{}))
+	require.NoError(t, m.Up())
+
+	var checksum string
+	require.NoError(t, db.QueryRow("SELECT COALESCE(checksum, '') FROM migrations WHERE migration = ?", "20240102000000_create_profiles").Scan(&checksum))
+	assert.Empty(t, checksum, "the SQL of a migration that reads the schema depends on the database")
+
+	// A checksum recorded by an older version, compiled against the live
+	// schema, is not compared either.
+	_, err := db.Exec("UPDATE migrations SET checksum = 'live' WHERE migration = ?", "20240102000000_create_profiles")
+	require.NoError(t, err)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, s := range statuses {
+		assert.False(t, s.Modified, s.Name)
+		assert.NoError(t, s.ChecksumErr, s.Name)
+	}
+
+	mismatches, err := m.Verify()
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestStatus_ReportsChecksumErrorPerMigration(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	require.NoError(t, m.Register("20240101000000_broken", &failingMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_users", &createTableMigration{}))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, []MigrationRecord{
+		{Name: "20240101000000_broken", Batch: 1, CreatedAt: time.Now(), Checksum: "recorded"},
+		{Name: "20240102000000_create_users", Batch: 1, CreatedAt: time.Now(), Checksum: "stale"},
+	})
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.EqualError(t, statuses[0].ChecksumErr, "up failed")
+	assert.False(t, statuses[0].Modified)
+	assert.NoError(t, statuses[1].ChecksumErr)
+	assert.True(t, statuses[1].Modified)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLMigration_ChecksumCoversTheUpScript(t *testing.T) {
+	a := NewSQLMigration("create_users", "CREATE TABLE users (id INTEGER);", "DROP TABLE users;")
+	b := NewSQLMigration("create_users", "CREATE TABLE users (id BIGINT);", "DROP TABLE users;")
+
+	assert.Equal(t, Checksum([]string{"CREATE TABLE users (id INTEGER)"}), a.Checksum())
+	assert.NotEqual(t, a.Checksum(), b.Checksum())
+}
@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// connectionOf returns the name of the connection a migration is routed
+// to: its ConnectionOption, or the connection passed to New.
+func (m *Migrator) connectionOf(migration Migration) string {
+	if opt, ok := migration.(ConnectionOption); ok && opt.Connection() != "" {
+		return opt.Connection()
+	}
+	return m.connName
+}
+
+// targets returns one Migrator per connection, the connection passed to
+// New first and the others in name order, each with a registry holding
+// only the migrations routed to it. With WithOnlyConnection only that
+// connection is returned.
+func (m *Migrator) targets() ([]*Migrator, error) {
+	groups := make(map[string]*Registry)
+	for _, reg := range m.registry.GetAll() {
+		name := m.connectionOf(reg.Migration)
+		if name != m.connName && m.connections[name] == nil {
+			return nil, fmt.Errorf("migration %q: connection %q: %w", reg.Name, name, ErrConnectionNotFound)
+		}
+		if groups[name] == nil {
+			groups[name] = NewRegistry()
+		}
+		// GetAll is sorted, so appending keeps each group sorted.
+		groups[name].migrations = append(groups[name].migrations, reg)
+	}
+
+	names := []string{m.connName}
+	if m.only != "" {
+		if m.only != m.connName && m.connections[m.only] == nil {
+			return nil, fmt.Errorf("connection %q: %w", m.only, ErrConnectionNotFound)
+		}
+		names = []string{m.only}
+	} else {
+		others := make([]string, 0, len(m.connections))
+		for name := range m.connections {
+			if name != m.connName {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(others)
+		names = append(names, others...)
+	}
+
+	targets := make([]*Migrator, 0, len(names))
+	for _, name := range names {
+		base := m
+		if name != m.connName {
+			base = m.connections[name]
+		}
+		t := *base
+		t.registry = groups[name]
+		if t.registry == nil {
+			t.registry = NewRegistry()
+		}
+		t.connections = nil
+		t.only = ""
+		targets = append(targets, &t)
+	}
+	return targets, nil
+}
+
+// eachConnection calls fn with the Migrator of every connection returned
+// by targets, stopping at the first error. Errors from connections other
+// than the one passed to New name the connection.
+func (m *Migrator) eachConnection(fn func(c *Migrator) error) error {
+	targets, err := m.targets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if err := fn(t); err != nil {
+			if t.connName != m.connName {
+				return fmt.Errorf("connection %q: %w", t.connName, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// requireGrammar fails when a connection returned by targets has no
+// grammar, naming op and, except for the connection passed to New, the
+// connection.
+func (m *Migrator) requireGrammar(op string) error {
+	targets, err := m.targets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if t.grammar != nil {
+			continue
+		}
+		if t.connName != m.connName {
+			return fmt.Errorf("connection %q: %s requires a grammar: pass one to WithConnection", t.connName, op)
+		}
+		return fmt.Errorf("%s requires a grammar: configure with WithGrammar", op)
+	}
+	return nil
+}
+
+// selected returns the Migrator of the connection chosen with
+// WithOnlyConnection, or m itself.
+func (m *Migrator) selected() (*Migrator, error) {
+	if m.only == "" || m.only == m.connName {
+		return m, nil
+	}
+	c, ok := m.connections[m.only]
+	if !ok {
+		return nil, fmt.Errorf("connection %q: %w", m.only, ErrConnectionNotFound)
+	}
+	return c, nil
+}
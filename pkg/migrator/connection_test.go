@@ -0,0 +1,136 @@
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createEventsMigration creates an "events" table on the analytics
+// connection.
+type createEventsMigration struct{}
+
+func (m *createEventsMigration) Up(b *schema.Builder) error {
+	return b.Create("events", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("kind", 50)
+	})
+}
+
+func (m *createEventsMigration) Down(b *schema.Builder) error {
+	return b.Drop("events")
+}
+
+func (m *createEventsMigration) Connection() string { return "analytics" }
+
+func hasSQLiteTable(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n))
+	return n == 1
+}
+
+func newConnectionsMigrator(t *testing.T, app, analytics *sql.DB, opts ...Option) *Migrator {
+	t.Helper()
+	opts = append([]Option{
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithDefaultConnection("app"),
+		WithConnection("analytics", analytics, grammars.NewSQLiteGrammar()),
+	}, opts...)
+	m := New(app, opts...)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_events", &createEventsMigration{}))
+	return m
+}
+
+func TestConnections_RouteMigrations(t *testing.T) {
+	app, analytics := openSQLite(t), openSQLite(t)
+	m := newConnectionsMigrator(t, app, analytics)
+
+	require.NoError(t, m.Up())
+
+	assert.True(t, hasSQLiteTable(t, app, "users"))
+	assert.False(t, hasSQLiteTable(t, app, "events"))
+	assert.True(t, hasSQLiteTable(t, analytics, "events"))
+	assert.False(t, hasSQLiteTable(t, analytics, "users"))
+
+	var tracked string
+	require.NoError(t, analytics.QueryRow(`SELECT migration FROM migrations`).Scan(&tracked))
+	assert.Equal(t, "20240102000000_create_events", tracked, "each connection has its own tracking table")
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "app", statuses[0].Connection)
+	assert.Equal(t, "analytics", statuses[1].Connection)
+	assert.True(t, statuses[0].Applied)
+	assert.True(t, statuses[1].Applied)
+
+	require.NoError(t, m.Rollback(0))
+	assert.False(t, hasSQLiteTable(t, app, "users"))
+	assert.False(t, hasSQLiteTable(t, analytics, "events"))
+}
+
+func TestConnections_OnlyConnection(t *testing.T) {
+	app, analytics := openSQLite(t), openSQLite(t)
+
+	only := newConnectionsMigrator(t, app, analytics, WithOnlyConnection("analytics"))
+	require.NoError(t, only.Up())
+	assert.True(t, hasSQLiteTable(t, analytics, "events"))
+	assert.False(t, hasSQLiteTable(t, app, "users"))
+
+	statuses, err := only.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "20240102000000_create_events", statuses[0].Name)
+
+	all := newConnectionsMigrator(t, app, analytics)
+	statuses, err = all.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Applied)
+	assert.True(t, statuses[1].Applied)
+
+	missing := newConnectionsMigrator(t, app, analytics, WithOnlyConnection("reporting"))
+	assert.True(t, errors.Is(missing.Up(), ErrConnectionNotFound))
+}
+
+func TestConnections_UnknownConnection(t *testing.T) {
+	m := New(openSQLite(t), WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240102000000_create_events", &createEventsMigration{}))
+
+	err := m.Up()
+	assert.True(t, errors.Is(err, ErrConnectionNotFound))
+	assert.Contains(t, err.Error(), `connection "analytics"`)
+}
+
+func TestConnections_RequireGrammar(t *testing.T) {
+	app, analytics := openSQLite(t), openSQLite(t)
+	m := New(app,
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithDefaultConnection("app"),
+		WithConnection("analytics", analytics, nil),
+	)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_events", &createEventsMigration{}))
+
+	err := m.Fresh()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `connection "analytics": fresh requires a grammar`)
+	assert.False(t, hasSQLiteTable(t, app, "users"), "nothing ran before the check")
+
+	_, err = m.Plan(PlanUp, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `connection "analytics": plan requires a grammar`)
+
+	linter, err := NewLinter(m)
+	require.NoError(t, err)
+	_, err = linter.Lint()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `connection "analytics": lint requires a grammar`)
+}
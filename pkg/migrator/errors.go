@@ -19,4 +19,9 @@ var (
 	ErrUnsupportedType      = errors.New("unsupported column type")
 	ErrConnectionNotFound   = errors.New("connection not found")
 	ErrConfigValidation     = errors.New("configuration validation failed")
+	ErrLockTimeout          = errors.New("timed out waiting for migration lock")
+	ErrUnknownPhase         = errors.New("unknown migration phase")
+	ErrTenantFailed         = errors.New("tenant migration failed")
+	ErrInvalidTenantName    = errors.New("invalid tenant name")
+	ErrSchemaNotEmpty       = errors.New("database already has tables")
 )
@@ -0,0 +1,97 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andrianprasetya/go-migration/pkg/cli/commands"
+	"github.com/andrianprasetya/go-migration/pkg/config"
+	"github.com/andrianprasetya/go-migration/pkg/database"
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
+)
+
+// Failure classes reported by ErrorClass and in the "error.class" field of
+// the CLI's JSON and YAML output.
+const (
+	ClassError       = "error"       // any failure not covered below
+	ClassConfig      = "config"      // invalid configuration
+	ClassConnection  = "connection"  // a connection could not be found or opened
+	ClassLock        = "lock"        // the migration lock was not acquired in time
+	ClassMigration   = "migration"   // a migration failed
+	ClassTenant      = "tenant"      // one or more tenants failed
+	ClassSeeder      = "seeder"      // a seeder failed
+	ClassRefused     = "refused"     // a destructive command was refused in production
+	ClassLint        = "lint"        // a pending migration breaks a lint rule
+	ClassInterrupted = "interrupted" // the run was cancelled, e.g. by SIGINT
+)
+
+// Exit codes of the CLI, one per failure class.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitConfig      = 2
+	ExitConnection  = 3
+	ExitLock        = 4
+	ExitMigration   = 5
+	ExitTenant      = 6
+	ExitSeeder      = 7
+	ExitRefused     = 8
+	ExitLint        = 9
+	ExitInterrupted = 130
+)
+
+var exitCodes = map[string]int{
+	ClassError:       ExitError,
+	ClassConfig:      ExitConfig,
+	ClassConnection:  ExitConnection,
+	ClassLock:        ExitLock,
+	ClassMigration:   ExitMigration,
+	ClassTenant:      ExitTenant,
+	ClassSeeder:      ExitSeeder,
+	ClassRefused:     ExitRefused,
+	ClassLint:        ExitLint,
+	ClassInterrupted: ExitInterrupted,
+}
+
+// ErrorClass returns the failure class of err, or the empty string when err
+// is nil. When err matches several classes the most specific one wins: an
+// interrupted migration is ClassInterrupted rather than ClassMigration.
+func ErrorClass(err error) string {
+	var migrationErr *MigrationError
+	var seederErr *seeder.SeederError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return ClassInterrupted
+	case errors.Is(err, commands.ErrProductionGuard):
+		return ClassRefused
+	case errors.Is(err, commands.ErrLintViolations):
+		return ClassLint
+	case errors.Is(err, ErrLockTimeout):
+		return ClassLock
+	case errors.Is(err, ErrTenantFailed):
+		return ClassTenant
+	case errors.As(err, &migrationErr), errors.Is(err, ErrTransactionFailed), errors.Is(err, database.ErrTransactionFailed):
+		return ClassMigration
+	case errors.As(err, &seederErr), errors.Is(err, seeder.ErrSeederNotFound), errors.Is(err, seeder.ErrCircularDependency):
+		return ClassSeeder
+	case errors.Is(err, ErrConnectionFailed), errors.Is(err, ErrConnectionNotFound),
+		errors.Is(err, database.ErrConnectionFailed), errors.Is(err, database.ErrConnectionNotFound),
+		errors.Is(err, database.ErrDriverNotFound), errors.Is(err, database.ErrNoDefault):
+		return ClassConnection
+	case errors.Is(err, ErrConfigValidation), errors.Is(err, config.ErrConfigValidation):
+		return ClassConfig
+	default:
+		return ClassError
+	}
+}
+
+// ExitCode returns the process exit code for err: ExitOK for nil, else the
+// code of its ErrorClass.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return exitCodes[ErrorClass(err)]
+}
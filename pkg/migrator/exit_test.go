@@ -0,0 +1,64 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/cli/commands"
+	"github.com/andrianprasetya/go-migration/pkg/config"
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClass(t *testing.T) {
+	migrationErr := wrapMigrationError("20240101000000_create_users", "CREATE TABLE users", errors.New("syntax error"))
+
+	tests := []struct {
+		name  string
+		err   error
+		class string
+		code  int
+	}{
+		{"nil", nil, "", ExitOK},
+		{"unclassified", errors.New("boom"), ClassError, ExitError},
+		{"config", fmt.Errorf("load: %w", config.ErrConfigValidation), ClassConfig, ExitConfig},
+		{"connection", fmt.Errorf("connection %q: %w", "analytics", ErrConnectionNotFound), ClassConnection, ExitConnection},
+		{"lock", fmt.Errorf("acquire migration lock: %w", ErrLockTimeout), ClassLock, ExitLock},
+		{"migration", fmt.Errorf("up: %w", migrationErr), ClassMigration, ExitMigration},
+		{"tenant", fmt.Errorf("1 of 2 tenants: %w", ErrTenantFailed), ClassTenant, ExitTenant},
+		{"lint", fmt.Errorf("%w: 2 violations", commands.ErrLintViolations), ClassLint, ExitLint},
+		{"refused", fmt.Errorf("migrate: %w", commands.ErrProductionGuard), ClassRefused, ExitRefused},
+		{"seeder", &seeder.SeederError{Seeder: "users", Cause: errors.New("duplicate key")}, ClassSeeder, ExitSeeder},
+		{"interrupted migration", wrapMigrationError("20240101000000_create_users", "", context.Canceled), ClassInterrupted, ExitInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.class, ErrorClass(tt.err))
+			assert.Equal(t, tt.code, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	err := fmt.Errorf("up: %w", &MigrationError{
+		MigrationName: "20240101000000_create_users",
+		SQL:           "CREATE TABLE users",
+		Position:      "line 1",
+		Cause:         errors.New("syntax error"),
+	})
+
+	info := describeError(err)
+	assert.Equal(t, ClassMigration, info.Class)
+	assert.Equal(t, ExitMigration, info.ExitCode)
+	assert.Equal(t, "20240101000000_create_users", info.Migration)
+	assert.Equal(t, "CREATE TABLE users", info.SQL)
+	assert.Equal(t, "line 1", info.Position)
+	assert.Equal(t, "syntax error", info.Cause)
+
+	info = describeError(&seeder.SeederError{Seeder: "users", Cause: errors.New("duplicate key")})
+	assert.Equal(t, ClassSeeder, info.Class)
+	assert.Equal(t, "users", info.Seeder)
+	assert.Equal(t, "duplicate key", info.Cause)
+}
@@ -16,3 +16,10 @@ type Migration interface {
 type TransactionOption interface {
 	DisableTransaction() bool
 }
+
+// ConnectionOption routes a migration to a named connection added with
+// WithConnection. Migrations that do not implement it, or whose Connection
+// returns an empty name, run on the connection passed to New.
+type ConnectionOption interface {
+	Connection() string
+}
@@ -0,0 +1,301 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+)
+
+// Lint rules checked by a Linter.
+const (
+	// LintIndexConcurrently flags CREATE INDEX without CONCURRENTLY on an
+	// existing PostgreSQL table, which blocks writes while the index builds.
+	// Table size is not checked: any table not created earlier in the run
+	// counts as existing, which approximates "large". It applies to Go and
+	// SQL migrations alike; a migration that knows its table is small
+	// suppresses the rule through LintOption.
+	LintIndexConcurrently = "index-concurrently"
+	// LintNotNullWithoutDefault flags adding a NOT NULL column without a
+	// default to an existing table, which fails once the table has rows.
+	LintNotNullWithoutDefault = "not-null-without-default"
+	// LintChangeColumnType flags changing the type of a column, which can
+	// rewrite the table under an exclusive lock.
+	LintChangeColumnType = "change-column-type"
+	// LintDropReferencedColumn flags dropping a column that a foreign key
+	// still references.
+	LintDropReferencedColumn = "drop-referenced-column"
+	// LintRenameTable flags renaming a table, which breaks code still
+	// using the old name during a deploy.
+	LintRenameTable = "rename-table"
+	// LintForeignKeyNotValid flags adding a foreign key to an existing
+	// PostgreSQL table without NOT VALID, which scans the table under lock.
+	// Like LintIndexConcurrently it treats every table not created earlier
+	// in the run as existing, whatever its size.
+	LintForeignKeyNotValid = "foreign-key-not-valid"
+)
+
+// LintRules lists every lint rule, in the order they are checked.
+var LintRules = []string{
+	LintIndexConcurrently,
+	LintNotNullWithoutDefault,
+	LintChangeColumnType,
+	LintDropReferencedColumn,
+	LintRenameTable,
+	LintForeignKeyNotValid,
+}
+
+// LintOption lets a migration suppress lint rules it breaks on purpose.
+// Rules returned by SuppressLint are not checked for the migration. SQL
+// migrations suppress rules with a "-- +lint:ignore rule,..." line.
+type LintOption interface {
+	SuppressLint() []string
+}
+
+// LintViolation is a risky statement found by a Linter.
+type LintViolation struct {
+	Rule       string
+	Migration  string
+	Connection string
+	Statement  string
+	Message    string
+}
+
+// String returns the violation as "migration: message [rule]".
+func (v LintViolation) String() string {
+	return fmt.Sprintf("%s: %s [%s]", v.Migration, v.Message, v.Rule)
+}
+
+// Linter checks pending migrations for statements that are risky to run
+// against a live database.
+type Linter struct {
+	m        *Migrator
+	disabled map[string]bool
+}
+
+// NewLinter creates a Linter for the pending migrations of m that checks
+// every rule of LintRules except disabled. An unknown rule is an error.
+func NewLinter(m *Migrator, disabled ...string) (*Linter, error) {
+	l := &Linter{m: m, disabled: make(map[string]bool, len(disabled))}
+	for _, rule := range disabled {
+		if !isLintRule(rule) {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+		l.disabled[rule] = true
+	}
+	return l, nil
+}
+
+// Lint compiles the migrations Up would run, as Plan does, and returns the
+// violations of their statements in order. Nothing is written.
+func (l *Linter) Lint() ([]LintViolation, error) {
+	return l.LintContext(context.Background())
+}
+
+// LintContext is like Lint but executes with ctx.
+func (l *Linter) LintContext(ctx context.Context) ([]LintViolation, error) {
+	if err := l.m.requireGrammar("lint"); err != nil {
+		return nil, err
+	}
+
+	var violations []LintViolation
+	err := l.m.eachConnection(func(c *Migrator) error {
+		planned, err := c.plan(ctx, PlanUp, "")
+		if err != nil {
+			return err
+		}
+		found, err := l.lint(ctx, c, planned)
+		violations = append(violations, found...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)`)
+	createIndexRe = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?.*?\bON\s+(?:ONLY\s+)?([^\s(]+)`)
+	addColumnRe   = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+([^\s]+)\s+ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?([^\s]+)\s+(.*)$`)
+	notNullRe     = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultRe     = regexp.MustCompile(`(?i)\bDEFAULT\b|\bGENERATED\b`)
+	changeTypeRe  = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+([^\s]+)\s+(?:ALTER\s+COLUMN\s+([^\s]+)\s+(?:SET\s+DATA\s+)?TYPE\b|MODIFY\s+(?:COLUMN\s+)?([^\s]+)|CHANGE\s+(?:COLUMN\s+)?([^\s]+))`)
+	dropColumnRe  = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+([^\s]+)\s+DROP\s+COLUMN\s+(?:IF\s+EXISTS\s+)?([^\s;,]+)`)
+	renameTableRe = regexp.MustCompile(`(?i)^\s*(?:ALTER\s+TABLE\s+([^\s]+)\s+RENAME\s+(?:TO|AS)\s+([^\s;]+)|RENAME\s+TABLE\s+([^\s]+)\s+TO\s+([^\s;]+))`)
+	foreignKeyRe  = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+([^\s]+)\s+ADD\s+(?:CONSTRAINT\s+[^\s]+\s+)?FOREIGN\s+KEY\b`)
+	notValidRe    = regexp.MustCompile(`(?i)\bNOT\s+VALID\b`)
+)
+
+// addKeywords are the words after ADD that start a constraint or index
+// rather than a column.
+var addKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "FOREIGN": true,
+	"INDEX": true, "KEY": true, "CHECK": true, "FULLTEXT": true, "SPATIAL": true,
+}
+
+// lint checks the planned migrations of connection c. Tables created by
+// an earlier statement of the run are new and empty, so statements on them
+// are not flagged.
+func (l *Linter) lint(ctx context.Context, c *Migrator, planned []PlannedMigration) ([]LintViolation, error) {
+	_, postgres := c.grammar.(*grammars.PostgresGrammar)
+	created := make(map[string]bool)
+	var refs map[string]bool
+
+	var violations []LintViolation
+	for _, pm := range planned {
+		if pm.Baseline {
+			continue
+		}
+		suppressed, err := l.suppressed(pm.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, compiled := range pm.Statements {
+			// A compiled CREATE TABLE carries its indexes in one string.
+			for _, stmt := range splitStatements(compiled) {
+				report := func(rule, format string, args ...any) {
+					if l.disabled[rule] || suppressed[rule] {
+						return
+					}
+					violations = append(violations, LintViolation{
+						Rule:       rule,
+						Migration:  pm.Name,
+						Connection: pm.Connection,
+						Statement:  stmt,
+						Message:    fmt.Sprintf(format, args...),
+					})
+				}
+
+				if match := createTableRe.FindStringSubmatch(stmt); match != nil {
+					created[identifier(match[1])] = true
+					continue
+				}
+				if match := createIndexRe.FindStringSubmatch(stmt); match != nil {
+					if table := identifier(match[2]); postgres && match[1] == "" && !created[table] {
+						report(LintIndexConcurrently, "index on existing table %q is built without CONCURRENTLY and blocks writes", table)
+					}
+					continue
+				}
+				if match := renameTableRe.FindStringSubmatch(stmt); match != nil {
+					from, to := match[1]+match[3], match[2]+match[4]
+					if !created[identifier(from)] {
+						report(LintRenameTable, "renaming table %q to %q breaks code still using the old name", identifier(from), identifier(to))
+					}
+					created[identifier(to)] = created[identifier(from)]
+					continue
+				}
+				if match := foreignKeyRe.FindStringSubmatch(stmt); match != nil {
+					if table := identifier(match[1]); postgres && !created[table] && !notValidRe.MatchString(stmt) {
+						report(LintForeignKeyNotValid, "foreign key on existing table %q is added without NOT VALID and scans the table under lock", table)
+					}
+					continue
+				}
+				if match := addColumnRe.FindStringSubmatch(stmt); match != nil && !addKeywords[strings.ToUpper(match[2])] {
+					table, column := identifier(match[1]), identifier(match[2])
+					if !created[table] && notNullRe.MatchString(match[3]) && !defaultRe.MatchString(match[3]) {
+						report(LintNotNullWithoutDefault, "NOT NULL column %q is added to existing table %q without a default", column, table)
+					}
+					continue
+				}
+				if match := changeTypeRe.FindStringSubmatch(stmt); match != nil {
+					table, column := identifier(match[1]), identifier(match[2]+match[3]+match[4])
+					if !created[table] {
+						report(LintChangeColumnType, "changing column %q of table %q can rewrite the table under lock", column, table)
+					}
+					continue
+				}
+				if match := dropColumnRe.FindStringSubmatch(stmt); match != nil {
+					table, column := identifier(match[1]), identifier(match[2])
+					if created[table] || l.disabled[LintDropReferencedColumn] || suppressed[LintDropReferencedColumn] {
+						continue
+					}
+					if refs == nil {
+						if refs, err = referencedColumns(ctx, c); err != nil {
+							return nil, err
+						}
+					}
+					if refs[table+"."+column] {
+						report(LintDropReferencedColumn, "column %q of table %q is still referenced by a foreign key", column, table)
+					}
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+// suppressed returns the rules the named migration suppresses.
+func (l *Linter) suppressed(name string) (map[string]bool, error) {
+	migration, err := l.m.registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	opt, ok := migration.(LintOption)
+	if !ok {
+		return nil, nil
+	}
+	rules := make(map[string]bool)
+	for _, rule := range opt.SuppressLint() {
+		if !isLintRule(rule) {
+			return nil, fmt.Errorf("migration %q suppresses unknown lint rule %q", name, rule)
+		}
+		rules[rule] = true
+	}
+	return rules, nil
+}
+
+// referencedColumns returns the "table.column" keys of the columns that a
+// foreign key of the database references. Without an inspecting grammar
+// no column is known to be referenced.
+func referencedColumns(ctx context.Context, c *Migrator) (map[string]bool, error) {
+	refs := make(map[string]bool)
+	inspector, err := schema.NewInspectorContext(ctx, c.db, c.grammar)
+	if errors.Is(err, schema.ErrInspectionUnsupported) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := inspector.Tables()
+	if err != nil {
+		return nil, fmt.Errorf("lint: %w", err)
+	}
+	for _, table := range tables {
+		fks, err := inspector.ForeignKeys(table)
+		if err != nil {
+			return nil, fmt.Errorf("lint: %w", err)
+		}
+		for _, fk := range fks {
+			for _, column := range fk.ReferencedColumns {
+				refs[fk.ReferencedTable+"."+column] = true
+			}
+		}
+	}
+	return refs, nil
+}
+
+// identifier returns the unquoted, unqualified name of a quoted or
+// schema-qualified identifier such as "public"."users".
+func identifier(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "\"`[]")
+}
+
+// isLintRule reports whether rule is one of LintRules.
+func isLintRule(rule string) bool {
+	for _, r := range LintRules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_Rules(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		rule      string
+	}{
+		{"plain index", `CREATE INDEX "users_email_index" ON "users" ("email")`, LintIndexConcurrently},
+		{"unique index", `CREATE UNIQUE INDEX "users_email_unique" ON "users" ("email")`, LintIndexConcurrently},
+		{"concurrent index", `CREATE INDEX CONCURRENTLY "users_email_index" ON "users" ("email")`, ""},
+		{"index on new table", `CREATE INDEX "accounts_name_index" ON "accounts" ("name")`, ""},
+		{"not null column", `ALTER TABLE "users" ADD COLUMN "age" INTEGER NOT NULL`, LintNotNullWithoutDefault},
+		{"not null column with default", `ALTER TABLE "users" ADD COLUMN "age" INTEGER NOT NULL DEFAULT 0`, ""},
+		{"nullable column", `ALTER TABLE "users" ADD COLUMN "age" INTEGER`, ""},
+		{"column type", `ALTER TABLE "users" ALTER COLUMN "age" TYPE BIGINT USING "age"::BIGINT`, LintChangeColumnType},
+		{"mysql modify", "ALTER TABLE `users` MODIFY COLUMN `age` BIGINT NOT NULL", LintChangeColumnType},
+		{"rename table", `ALTER TABLE "users" RENAME TO "members"`, LintRenameTable},
+		{"mysql rename table", "RENAME TABLE `users` TO `members`", LintRenameTable},
+		{"rename column", `ALTER TABLE "users" RENAME COLUMN "name" TO "full_name"`, ""},
+		{"foreign key", `ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY ("user_id") REFERENCES "users" ("id")`, LintForeignKeyNotValid},
+		{"foreign key not valid", `ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY ("user_id") REFERENCES "users" ("id") NOT VALID`, ""},
+		{"unique constraint", `ALTER TABLE "users" ADD CONSTRAINT "users_email_unique" UNIQUE ("email")`, ""},
+	}
+
+	m := New(nil, WithGrammar(grammars.NewPostgresGrammar()))
+	require.NoError(t, m.Register("20240101000000_change_users", &createTableMigration{}))
+	linter, err := NewLinter(m)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planned := []PlannedMigration{{
+				Name:       "20240101000000_change_users",
+				Statements: []string{`CREATE TABLE "accounts" ("name" VARCHAR(255) NOT NULL)`, tt.statement},
+			}}
+			violations, err := linter.lint(context.Background(), m, planned)
+			require.NoError(t, err)
+			if tt.rule == "" {
+				assert.Empty(t, violations)
+				return
+			}
+			require.Len(t, violations, 1)
+			assert.Equal(t, tt.rule, violations[0].Rule)
+			assert.Equal(t, tt.statement, violations[0].Statement)
+		})
+	}
+}
+
+func TestLinter_PostgresOnlyRules(t *testing.T) {
+	m := New(nil, WithGrammar(grammars.NewMySQLGrammar()))
+	require.NoError(t, m.Register("20240101000000_index_users", &createTableMigration{}))
+	linter, err := NewLinter(m)
+	require.NoError(t, err)
+
+	violations, err := linter.lint(context.Background(), m, []PlannedMigration{{
+		Name: "20240101000000_index_users",
+		Statements: []string{
+			"CREATE INDEX `users_email_index` ON `users` (`email`)",
+			"ALTER TABLE `posts` ADD CONSTRAINT `posts_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
+		},
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+// suppressingMigration is a Go migration that suppresses rules.
+type suppressingMigration struct {
+	noopMigration
+	rules []string
+}
+
+func (m *suppressingMigration) SuppressLint() []string { return m.rules }
+
+func TestLinter_GoMigrationsSuppressPostgresRules(t *testing.T) {
+	statements := []string{
+		`CREATE INDEX "users_email_index" ON "users" ("email")`,
+		`ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY ("user_id") REFERENCES "users" ("id")`,
+	}
+
+	m := New(nil, WithGrammar(grammars.NewPostgresGrammar()))
+	require.NoError(t, m.Register("20240101000000_index_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_index_lookups", &suppressingMigration{
+		rules: []string{LintIndexConcurrently, LintForeignKeyNotValid},
+	}))
+	linter, err := NewLinter(m)
+	require.NoError(t, err)
+
+	violations, err := linter.lint(context.Background(), m, []PlannedMigration{
+		{Name: "20240101000000_index_users", Statements: statements},
+		{Name: "20240102000000_index_lookups", Statements: statements},
+	})
+	require.NoError(t, err)
+	require.Len(t, violations, 2, "the Go migration is checked, the suppressing one is not")
+	assert.Equal(t, LintIndexConcurrently, violations[0].Rule)
+	assert.Equal(t, LintForeignKeyNotValid, violations[1].Rule)
+	assert.Equal(t, "20240101000000_index_users", violations[1].Migration)
+}
+
+func TestLinter_Lint(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id))`)
+	require.NoError(t, err)
+
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_drop_user_ids", NewSQLMigration("20240101000000_drop_user_ids",
+		"ALTER TABLE users DROP COLUMN id;\nALTER TABLE users DROP COLUMN name;", "")))
+	require.NoError(t, m.Register("20240102000000_rename_posts", NewSQLMigration("20240102000000_rename_posts",
+		"-- +lint:ignore rename-table\nALTER TABLE posts RENAME TO articles;", "")))
+	require.NoError(t, m.Register("20240103000000_add_age", NewSQLMigration("20240103000000_add_age",
+		"ALTER TABLE users ADD COLUMN age INTEGER NOT NULL;", "")))
+
+	linter, err := NewLinter(m)
+	require.NoError(t, err)
+	violations, err := linter.Lint()
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, LintDropReferencedColumn, violations[0].Rule)
+	assert.Equal(t, "20240101000000_drop_user_ids", violations[0].Migration)
+	assert.Equal(t, "ALTER TABLE users DROP COLUMN id", violations[0].Statement)
+	assert.Equal(t, LintNotNullWithoutDefault, violations[1].Rule)
+	assert.Equal(t, `20240103000000_add_age: NOT NULL column "age" is added to existing table "users" without a default [not-null-without-default]`, violations[1].String())
+
+	linter, err = NewLinter(m, LintNotNullWithoutDefault, LintDropReferencedColumn)
+	require.NoError(t, err)
+	violations, err = linter.Lint()
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	assert.False(t, hasSQLiteTable(t, db, "migrations"), "linting must not create the tracking table")
+}
+
+func TestNewLinter_UnknownRule(t *testing.T) {
+	_, err := NewLinter(New(nil), "no-such-rule")
+	assert.ErrorContains(t, err, `unknown lint rule "no-such-rule"`)
+}
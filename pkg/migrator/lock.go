@@ -0,0 +1,330 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/andrianprasetya/go-migration/pkg/config"
+)
+
+// DefaultLockTimeout is how long a Migrator waits for the migration lock
+// when no timeout is configured via WithLockTimeout.
+const DefaultLockTimeout = config.DefaultLockTimeout
+
+// lockPollInterval is the delay between acquisition attempts for lockers
+// that poll rather than block inside the database.
+const lockPollInterval = 250 * time.Millisecond
+
+// Locker serializes state-changing migration operations across processes.
+// The Migrator acquires the lock before Up, Rollback, Reset, Refresh and
+// Fresh and releases it when the operation finishes.
+type Locker interface {
+	// Lock blocks until the lock is held or ctx is done. When ctx's deadline
+	// expires before the lock is acquired, the error wraps ErrLockTimeout.
+	Lock(ctx context.Context) error
+
+	// Unlock releases a lock previously acquired with Lock.
+	Unlock(ctx context.Context) error
+}
+
+// ForceUnlocker is implemented by Lockers whose lock can outlive the
+// process holding it. ForceUnlock releases the lock whoever holds it and
+// reports whether a lock was held.
+type ForceUnlocker interface {
+	ForceUnlock(ctx context.Context) (bool, error)
+}
+
+// lockerMap maps database driver names to Locker constructor functions.
+var lockerMap = map[string]func(db *sql.DB, name string) Locker{
+	"postgres": func(db *sql.DB, name string) Locker { return NewPostgresLocker(db, name) },
+	"mysql":    func(db *sql.DB, name string) Locker { return NewMySQLLocker(db, name) },
+	"sqlite":   func(db *sql.DB, name string) Locker { return NewSQLiteLocker(db, name) },
+	"sqlite3":  func(db *sql.DB, name string) Locker { return NewSQLiteLocker(db, name) },
+}
+
+// ResolveLocker returns the Locker for the given database driver name.
+// The tracking table name scopes the lock, so migrators that use different
+// tracking tables on the same database do not block each other.
+// It returns an error if the driver is not recognized.
+func ResolveLocker(driver string, db *sql.DB, tableName string) (Locker, error) {
+	fn, ok := lockerMap[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver %q", driver)
+	}
+	return fn(db, tableName), nil
+}
+
+// lockWaitError converts the reason ctx ended into the error returned by Lock.
+func lockWaitError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("acquire migration lock: %w", ErrLockTimeout)
+	}
+	return fmt.Errorf("acquire migration lock: %w", ctx.Err())
+}
+
+// waitForRetry sleeps for lockPollInterval or until ctx is done.
+// It returns false if ctx ended first.
+func waitForRetry(ctx context.Context) bool {
+	timer := time.NewTimer(lockPollInterval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// --- PostgreSQL ---
+
+// PostgresLocker implements Locker using a session-level PostgreSQL advisory
+// lock. The lock is held on a dedicated connection so that it is released
+// automatically if the process dies.
+type PostgresLocker struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn
+}
+
+// NewPostgresLocker creates a PostgresLocker whose advisory lock key is
+// derived from the given name.
+func NewPostgresLocker(db *sql.DB, name string) *PostgresLocker {
+	return &PostgresLocker{db: db, key: advisoryLockKey(name)}
+}
+
+// Lock polls pg_try_advisory_lock until it succeeds or ctx is done.
+func (l *PostgresLocker) Lock(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return lockWaitError(ctx)
+		}
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	for {
+		var acquired bool
+		err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired)
+		if err != nil {
+			conn.Close()
+			if ctx.Err() != nil {
+				return lockWaitError(ctx)
+			}
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if acquired {
+			l.conn = conn
+			return nil
+		}
+		if !waitForRetry(ctx) {
+			conn.Close()
+			return lockWaitError(ctx)
+		}
+	}
+}
+
+// Unlock releases the advisory lock and returns its connection to the pool.
+func (l *PostgresLocker) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		return fmt.Errorf("release migration lock: %w", err)
+	}
+	return nil
+}
+
+// advisoryLockKey hashes a lock name into the int64 key space used by
+// PostgreSQL advisory locks.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("go-migration:" + name))
+	return int64(h.Sum64())
+}
+
+// --- MySQL ---
+
+// MySQLLocker implements Locker using MySQL's GET_LOCK named locks.
+// The lock is held on a dedicated connection for the same reason as
+// PostgresLocker.
+type MySQLLocker struct {
+	db   *sql.DB
+	name string
+	conn *sql.Conn
+}
+
+// NewMySQLLocker creates a MySQLLocker for the given lock name.
+func NewMySQLLocker(db *sql.DB, name string) *MySQLLocker {
+	lockName := "go-migration:" + name
+	// MySQL limits lock names to 64 characters.
+	if len(lockName) > 64 {
+		lockName = lockName[:64]
+	}
+	return &MySQLLocker{db: db, name: lockName}
+}
+
+// Lock calls GET_LOCK with a timeout derived from ctx's deadline.
+// Without a deadline it waits indefinitely.
+func (l *MySQLLocker) Lock(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return lockWaitError(ctx)
+		}
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	timeout := -1
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = int(math.Ceil(time.Until(deadline).Seconds()))
+		if timeout < 0 {
+			timeout = 0
+		}
+	}
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, l.name, timeout).Scan(&acquired)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return lockWaitError(ctx)
+		}
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	if !acquired.Valid {
+		conn.Close()
+		return fmt.Errorf("acquire migration lock: GET_LOCK(%q) returned NULL", l.name)
+	}
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return fmt.Errorf("acquire migration lock: %w", ErrLockTimeout)
+	}
+
+	l.conn = conn
+	return nil
+}
+
+// Unlock calls RELEASE_LOCK and returns the connection to the pool.
+func (l *MySQLLocker) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, l.name); err != nil {
+		return fmt.Errorf("release migration lock: %w", err)
+	}
+	return nil
+}
+
+// --- SQLite ---
+
+// SQLiteLocker implements Locker with a single-row lock table, since SQLite
+// has no named or advisory locks. Holding the lock means owning the row
+// with id 1 in "<name>_lock".
+//
+// Unlike the server-side locks, the row survives a crashed process. Run
+// migrate:unlock (ForceUnlock) if a migration run was killed while holding
+// the lock; a timed out Lock reports since when the row has been held.
+type SQLiteLocker struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLiteLocker creates a SQLiteLocker that stores its lock row in
+// the table "<name>_lock".
+func NewSQLiteLocker(db *sql.DB, name string) *SQLiteLocker {
+	return &SQLiteLocker{db: db, table: name + "_lock"}
+}
+
+// Lock inserts the lock row, retrying while another process holds it.
+func (l *SQLiteLocker) Lock(ctx context.Context) error {
+	create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id          INTEGER PRIMARY KEY,
+		acquired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`, l.table)
+	if _, err := l.db.ExecContext(ctx, create); err != nil {
+		if ctx.Err() != nil {
+			return lockWaitError(ctx)
+		}
+		return fmt.Errorf("create lock table %q: %w", l.table, err)
+	}
+
+	insert := fmt.Sprintf(`INSERT INTO %s (id) VALUES (1)`, l.table)
+	held := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE id = 1`, l.table)
+	for {
+		_, insertErr := l.db.ExecContext(ctx, insert)
+		if insertErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return lockWaitError(ctx)
+		}
+
+		// Only keep waiting if the insert failed because the row exists.
+		var count int
+		if err := l.db.QueryRowContext(ctx, held).Scan(&count); err != nil || count == 0 {
+			return fmt.Errorf("acquire migration lock: %w", insertErr)
+		}
+
+		if !waitForRetry(ctx) {
+			return l.heldError(ctx)
+		}
+	}
+}
+
+// heldError is lockWaitError with the time the lock row was acquired and
+// how to release it if its holder died.
+func (l *SQLiteLocker) heldError(ctx context.Context) error {
+	err := lockWaitError(ctx)
+	if !errors.Is(err, ErrLockTimeout) {
+		return err
+	}
+	var acquiredAt string
+	query := fmt.Sprintf(`SELECT acquired_at FROM %s WHERE id = 1`, l.table)
+	if l.db.QueryRowContext(context.Background(), query).Scan(&acquiredAt) != nil {
+		return err
+	}
+	return fmt.Errorf("acquire migration lock held since %s (run migrate:unlock if its process died): %w", acquiredAt, ErrLockTimeout)
+}
+
+// ForceUnlock deletes the lock row, whoever holds it.
+func (l *SQLiteLocker) ForceUnlock(ctx context.Context) (bool, error) {
+	var exists int
+	err := l.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, l.table).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("release migration lock: %w", err)
+	}
+	if exists == 0 {
+		return false, nil
+	}
+	res, err := l.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = 1`, l.table))
+	if err != nil {
+		return false, fmt.Errorf("release migration lock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("release migration lock: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Unlock deletes the lock row.
+func (l *SQLiteLocker) Unlock(ctx context.Context) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = 1`, l.table)
+	if _, err := l.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("release migration lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLocker is a Locker test double that records Lock/Unlock calls.
+type recordingLocker struct {
+	calls   []string
+	lockErr error
+}
+
+func (l *recordingLocker) Lock(ctx context.Context) error {
+	l.calls = append(l.calls, "lock")
+	return l.lockErr
+}
+
+func (l *recordingLocker) Unlock(ctx context.Context) error {
+	l.calls = append(l.calls, "unlock")
+	return nil
+}
+
+// --- ResolveLocker ---
+
+func TestResolveLocker(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	tests := []struct {
+		driver string
+		want   any
+	}{
+		{"postgres", &PostgresLocker{}},
+		{"mysql", &MySQLLocker{}},
+		{"sqlite", &SQLiteLocker{}},
+		{"sqlite3", &SQLiteLocker{}},
+	}
+	for _, tt := range tests {
+		l, err := ResolveLocker(tt.driver, db, "migrations")
+		require.NoError(t, err, tt.driver)
+		assert.IsType(t, tt.want, l, tt.driver)
+	}
+
+	_, err = ResolveLocker("oracle", db, "migrations")
+	assert.Error(t, err)
+}
+
+// --- PostgresLocker ---
+
+func TestPostgresLocker_LockUnlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	l := NewPostgresLocker(db, "migrations")
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(l.key).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(l.key).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, l.Lock(context.Background()))
+	require.NoError(t, l.Unlock(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresLocker_Timeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	l := NewPostgresLocker(db, "migrations")
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(l.key).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = l.Lock(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLockTimeout))
+}
+
+func TestAdvisoryLockKey_DependsOnName(t *testing.T) {
+	assert.Equal(t, advisoryLockKey("migrations"), advisoryLockKey("migrations"))
+	assert.NotEqual(t, advisoryLockKey("migrations"), advisoryLockKey("other_migrations"))
+}
+
+// --- MySQLLocker ---
+
+func TestMySQLLocker_LockUnlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	l := NewMySQLLocker(db, "migrations")
+
+	mock.ExpectQuery(`SELECT GET_LOCK\(\?, \?\)`).WithArgs("go-migration:migrations", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec(`SELECT RELEASE_LOCK\(\?\)`).WithArgs("go-migration:migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, l.Lock(ctx))
+	require.NoError(t, l.Unlock(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLLocker_Timeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	l := NewMySQLLocker(db, "migrations")
+
+	mock.ExpectQuery(`SELECT GET_LOCK`).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	err = l.Lock(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLockTimeout))
+}
+
+// --- SQLiteLocker ---
+
+func TestSQLiteLocker_ExcludesSecondHolder(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "lock.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	first := NewSQLiteLocker(db, "migrations")
+	second := NewSQLiteLocker(db, "migrations")
+
+	require.NoError(t, first.Lock(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = second.Lock(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLockTimeout))
+
+	require.NoError(t, first.Unlock(context.Background()))
+	require.NoError(t, second.Lock(context.Background()))
+	require.NoError(t, second.Unlock(context.Background()))
+}
+
+func TestSQLiteLocker_ForceUnlockReleasesStaleLock(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "lock.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := New(db, WithLocker(NewSQLiteLocker(db, "migrations")))
+	released, err := m.ForceUnlock()
+	require.NoError(t, err)
+	assert.False(t, released, "no lock table yet")
+
+	// A process that died while holding the lock never unlocks.
+	require.NoError(t, NewSQLiteLocker(db, "migrations").Lock(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = NewSQLiteLocker(db, "migrations").Lock(ctx)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+	assert.ErrorContains(t, err, "run migrate:unlock")
+
+	released, err = m.ForceUnlock()
+	require.NoError(t, err)
+	assert.True(t, released)
+	require.NoError(t, NewSQLiteLocker(db, "migrations").Lock(context.Background()))
+}
+
+func TestMigrator_ForceUnlockWithoutStaleLocks(t *testing.T) {
+	released, err := New(nil, WithLocker(&recordingLocker{})).ForceUnlock()
+	require.NoError(t, err)
+	assert.False(t, released)
+}
+
+// --- Migrator integration ---
+
+func TestMigrator_Up_AcquiresLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := &recordingLocker{}
+	m := New(db, WithGrammar(&mockGrammar{}), WithLocker(locker))
+	require.NoError(t, m.Register("20240101000000_create_users", &noopMigration{}))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+	expectMaxBatch(mock, 0)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
+
+	require.NoError(t, m.Up())
+	assert.Equal(t, []string{"lock", "unlock"}, locker.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_LockFailure_SkipsMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := &recordingLocker{lockErr: ErrLockTimeout}
+	m := New(db, WithGrammar(&mockGrammar{}), WithLocker(locker))
+	mig := &noopMigration{}
+	require.NoError(t, m.Register("20240101000000_create_users", mig))
+
+	err = m.Up()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLockTimeout))
+	assert.False(t, mig.upCalled)
+	assert.Equal(t, []string{"lock"}, locker.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Refresh_LocksOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := &recordingLocker{}
+	m := New(db, WithGrammar(&mockGrammar{}), WithLocker(locker))
+
+	// Reset phase with nothing applied, then Up with nothing registered.
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+
+	require.NoError(t, m.Refresh())
+	assert.Equal(t, []string{"lock", "unlock"}, locker.calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_DryRun_SkipsLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	locker := &recordingLocker{}
+	m := New(db, WithGrammar(&mockGrammar{}), WithLocker(locker), WithDryRun(io.Discard))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+
+	require.NoError(t, m.Up())
+	assert.Empty(t, locker.calls)
+}
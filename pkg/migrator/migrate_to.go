@@ -0,0 +1,101 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrateTo brings the database to the schema version identified by the
+// named migration: afterwards exactly the registered migrations up to and
+// including name are applied.
+//
+// Applied migrations that sort after name are rolled back first, newest
+// first. Pending migrations up to and including name are then applied as
+// one batch. Returns ErrMigrationNotFound if name is not registered.
+func (m *Migrator) MigrateTo(name string) error {
+	return m.MigrateToContext(context.Background(), name)
+}
+
+// MigrateToContext is like MigrateTo but stops when ctx is cancelled.
+func (m *Migrator) MigrateToContext(ctx context.Context, name string) error {
+	if _, err := m.registry.Get(name); err != nil {
+		return err
+	}
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error {
+			if err := c.rollbackTo(ctx, name); err != nil {
+				return err
+			}
+			return c.upTo(ctx, name)
+		})
+	})
+}
+
+// RollbackTo rolls back, newest first, every applied migration that sorts
+// after the named migration. The named migration itself stays applied.
+// Returns ErrMigrationNotFound if name is not registered.
+func (m *Migrator) RollbackTo(name string) error {
+	return m.RollbackToContext(context.Background(), name)
+}
+
+// RollbackToContext is like RollbackTo but stops when ctx is cancelled.
+func (m *Migrator) RollbackToContext(ctx context.Context, name string) error {
+	if _, err := m.registry.Get(name); err != nil {
+		return err
+	}
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error { return c.rollbackTo(ctx, name) })
+	})
+}
+
+// rollbackTo implements RollbackTo without acquiring the migration lock.
+func (m *Migrator) rollbackTo(ctx context.Context, name string) error {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.tracker.GetAppliedContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Applied records are sorted by name; keep those after the target.
+	var after []MigrationRecord
+	for _, rec := range applied {
+		if rec.Name > name {
+			after = append(after, rec)
+		}
+	}
+	reverseRecords(after)
+
+	if err := m.runDown(ctx, after); err != nil {
+		return fmt.Errorf("migrate to %q: %w", name, err)
+	}
+	return nil
+}
+
+// upTo applies pending migrations up to and including name.
+func (m *Migrator) upTo(ctx context.Context, name string) error {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
+		return err
+	}
+
+	applied, err := m.tracker.GetAppliedContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var registered []registeredMigration
+	for _, reg := range m.registry.GetAll() {
+		if reg.Name > name {
+			break
+		}
+		registered = append(registered, reg)
+	}
+
+	pending, reached := m.pendingMigrations(registered, applied)
+	if err := m.runUp(ctx, pending, reached); err != nil {
+		return fmt.Errorf("migrate to %q: %w", name, err)
+	}
+	return nil
+}
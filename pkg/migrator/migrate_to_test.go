@@ -0,0 +1,100 @@
+package migrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateTo_AppliesPendingUpToTarget(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	first, second, third := &noopMigration{}, &noopMigration{}, &noopMigration{}
+	require.NoError(t, m.Register("20240101000000_first", first))
+	require.NoError(t, m.Register("20240102000000_second", second))
+	require.NoError(t, m.Register("20240103000000_third", third))
+
+	// Rollback phase: nothing applied after the target.
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+
+	// Up phase: first and second in one batch.
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+	expectMaxBatch(mock, 0)
+	expectRecordTx(mock, "20240101000000_first", 1)
+	expectRecordTx(mock, "20240102000000_second", 1)
+
+	require.NoError(t, m.MigrateTo("20240102000000_second"))
+	assert.True(t, first.upCalled)
+	assert.True(t, second.upCalled)
+	assert.False(t, third.upCalled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateTo_RollsBackMigrationsAfterTarget(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	first, second, third := &noopMigration{}, &noopMigration{}, &noopMigration{}
+	require.NoError(t, m.Register("20240101000000_first", first))
+	require.NoError(t, m.Register("20240102000000_second", second))
+	require.NoError(t, m.Register("20240103000000_third", third))
+
+	applied := []MigrationRecord{
+		{Name: "20240101000000_first", Batch: 1, CreatedAt: time.Now()},
+		{Name: "20240102000000_second", Batch: 1, CreatedAt: time.Now()},
+		{Name: "20240103000000_third", Batch: 2, CreatedAt: time.Now()},
+	}
+
+	// Rollback phase: newest first.
+	expectEnsureTable(mock)
+	expectGetApplied(mock, applied)
+	expectRemoveTx(mock, "20240103000000_third")
+	expectRemoveTx(mock, "20240102000000_second")
+
+	// Up phase: target already applied, nothing pending.
+	expectEnsureTable(mock)
+	expectGetApplied(mock, applied[:1])
+
+	require.NoError(t, m.MigrateTo("20240101000000_first"))
+	assert.False(t, first.downCalled)
+	assert.True(t, second.downCalled)
+	assert.True(t, third.downCalled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateTo_UnknownTarget(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	err := m.MigrateTo("20240101000000_missing")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMigrationNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackTo_LeavesTargetApplied(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	first, second := &noopMigration{}, &noopMigration{}
+	require.NoError(t, m.Register("20240101000000_first", first))
+	require.NoError(t, m.Register("20240102000000_second", second))
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, []MigrationRecord{
+		{Name: "20240101000000_first", Batch: 1, CreatedAt: time.Now()},
+		{Name: "20240102000000_second", Batch: 2, CreatedAt: time.Now()},
+	})
+	expectRemoveTx(mock, "20240102000000_second")
+
+	require.NoError(t, m.RollbackTo("20240101000000_first"))
+	assert.False(t, first.downCalled)
+	assert.True(t, second.downCalled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,9 +1,11 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/andrianprasetya/go-migration/pkg/schema"
@@ -15,6 +17,23 @@ type MigrationStatus struct {
 	Applied   bool
 	Batch     int
 	AppliedAt *time.Time
+	// Modified is true when an applied migration's current SQL no longer
+	// matches the checksum recorded when it was applied.
+	Modified bool
+	// ChecksumErr is the error compiling an applied migration for its
+	// checksum, in which case Modified is false.
+	ChecksumErr error
+	// Phase is the last phase an applied PhasedMigration completed; it is
+	// empty for other migrations.
+	Phase Phase
+	// Connection is the name of the connection the migration runs on.
+	Connection string
+}
+
+// Partial reports whether the migration is a PhasedMigration that has run
+// some, but not all, of its phases.
+func (s MigrationStatus) Partial() bool {
+	return s.Phase != "" && s.Phase != PhaseContract
 }
 
 // Migrator is the top-level orchestrator that wires together the Registry,
@@ -45,6 +64,35 @@ type Migrator struct {
 	progressFn   ProgressFunc
 	dryRun       bool
 	dryRunWriter io.Writer
+	locker       Locker
+	lockTimeout  time.Duration
+
+	// tableName and trackerDialect configure the tracker, which New builds
+	// after all options have been applied.
+	tableName      string
+	trackerDialect TrackerDialect
+
+	schemaDumpPath string
+	schemaDumper   SchemaDumper
+
+	// phase is the furthest phase Up runs phased migrations to.
+	phase Phase
+
+	// dropAll selects what Fresh drops besides the tables.
+	dropAll schema.DropAllOptions
+
+	// connName names the connection passed to New. connections holds the
+	// other connections migrations are routed to, each run by its own
+	// Migrator, and only restricts operations to one connection.
+	connName    string
+	connections map[string]*Migrator
+	only        string
+
+	// tenants and openTenant configure UpTenants and StatusTenants, which
+	// run on up to tenantParallelism tenants at once.
+	tenants           TenantSource
+	openTenant        TenantOpener
+	tenantParallelism int
 }
 
 // Option configures a Migrator.
@@ -53,12 +101,21 @@ type Option func(*Migrator)
 // WithTableName sets the migration tracking table name (default: "migrations").
 func WithTableName(name string) Option {
 	return func(m *Migrator) {
-		m.tracker = NewTracker(m.db, name)
-		m.batch = NewBatchManager(m.tracker)
+		m.tableName = name
+	}
+}
+
+// WithTrackerDialect sets the SQL dialect of the migration tracking table.
+// Without it the dialect is derived from the grammar set by WithGrammar,
+// falling back to PostgreSQL for unknown grammars.
+func WithTrackerDialect(d TrackerDialect) Option {
+	return func(m *Migrator) {
+		m.trackerDialect = d
 	}
 }
 
 // WithGrammar sets the SQL grammar used by the Runner and Fresh().
+// For the built-in grammars it also selects the matching TrackerDialect.
 func WithGrammar(g schema.Grammar) Option {
 	return func(m *Migrator) {
 		m.grammar = g
@@ -104,33 +161,166 @@ func WithDryRun(w io.Writer) Option {
 	}
 }
 
+// WithLocker sets the Locker acquired around every state-changing operation
+// (Up, Rollback, Reset, Refresh, Fresh). Use ResolveLocker to pick the
+// implementation for a driver. Without a Locker no cross-process locking
+// is performed.
+func WithLocker(l Locker) Option {
+	return func(m *Migrator) {
+		m.locker = l
+	}
+}
+
+// WithLockTimeout sets how long to wait for the migration lock before
+// failing with ErrLockTimeout (default: DefaultLockTimeout).
+func WithLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = d
+	}
+}
+
+// WithSchemaDump sets the path of a baseline written by DumpSchema. When
+// no migration has been recorded yet, Up loads the baseline, records the
+// migrations it covers and then runs only the migrations that are newer.
+// A missing file is ignored.
+func WithSchemaDump(path string) Option {
+	return func(m *Migrator) {
+		m.schemaDumpPath = path
+	}
+}
+
+// WithSchemaDumper sets the SchemaDumper used by DumpSchema. Without it
+// the dumper is derived from the grammar set by WithGrammar.
+func WithSchemaDumper(d SchemaDumper) Option {
+	return func(m *Migrator) {
+		m.schemaDumper = d
+	}
+}
+
+// WithPhase sets how far Up runs migrations registered with Phased
+// (default: PhaseExpand). Phases before p that have not run yet run first,
+// so a migration that only reached PhaseExpand runs Backfill and then
+// Contract when p is PhaseContract. Other migrations are not affected.
+func WithPhase(p Phase) Option {
+	return func(m *Migrator) {
+		m.phase = p
+	}
+}
+
+// WithDropAll selects the objects Fresh drops besides the tables, such as
+// views, and tables to keep.
+func WithDropAll(opts schema.DropAllOptions) Option {
+	return func(m *Migrator) {
+		m.dropAll = opts
+	}
+}
+
+// WithConnection adds a named connection that migrations implementing
+// ConnectionOption are routed to. Each connection has its own tracking
+// table, named as set with WithTableName, and compiles migrations with its
+// own grammar. The Locker is only acquired on the connection passed to New.
+func WithConnection(name string, db *sql.DB, grammar schema.Grammar) Option {
+	return func(m *Migrator) {
+		if m.connections == nil {
+			m.connections = make(map[string]*Migrator)
+		}
+		m.connections[name] = &Migrator{db: db, grammar: grammar, connName: name}
+	}
+}
+
+// WithDefaultConnection names the connection passed to New (default:
+// "default"), so that migrations whose ConnectionOption returns that name
+// run on it.
+func WithDefaultConnection(name string) Option {
+	return func(m *Migrator) {
+		m.connName = name
+	}
+}
+
+// WithOnlyConnection restricts every operation to the migrations routed to
+// the named connection. Operations fail with ErrConnectionNotFound when no
+// such connection exists.
+func WithOnlyConnection(name string) Option {
+	return func(m *Migrator) {
+		m.only = name
+	}
+}
+
 // New creates a new Migrator with the given database connection and options.
-// Defaults: table name "migrations", nil grammar, nil logger.
+// Defaults: table name "migrations", nil grammar, nil logger, no Locker.
+//
+// Locking is opt-in: without WithLocker, concurrent calls to Up and the
+// other state-changing operations from several processes are not
+// serialized and may apply the same migration twice. The CLI always
+// configures one.
 func New(db *sql.DB, opts ...Option) *Migrator {
-	tracker := NewTracker(db, "migrations")
 	m := &Migrator{
-		db:       db,
-		registry: NewRegistry(),
-		tracker:  tracker,
-		batch:    NewBatchManager(tracker),
-		hooks:    NewHookManager(),
+		db:          db,
+		registry:    NewRegistry(),
+		hooks:       NewHookManager(),
+		lockTimeout: DefaultLockTimeout,
+		tableName:   "migrations",
+		phase:       PhaseExpand,
+		connName:    "default",
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
+	m.init()
+
+	// Other connections track their migrations in their own database.
+	for name, c := range m.connections {
+		m.connections[name] = m.derive(c.db, c.grammar, nil)
+		m.connections[name].connName = name
+	}
+
+	return m
+}
+
+// derive returns a Migrator for db that shares m's registry, hooks and
+// settings. It compiles migrations with grammar and tracks them with
+// dialect, derived from the grammar when nil. It takes no lock.
+func (m *Migrator) derive(db *sql.DB, grammar schema.Grammar, dialect TrackerDialect) *Migrator {
+	c := &Migrator{
+		db:             db,
+		registry:       m.registry,
+		hooks:          m.hooks,
+		grammar:        grammar,
+		logger:         m.logger,
+		progressFn:     m.progressFn,
+		dryRun:         m.dryRun,
+		dryRunWriter:   m.dryRunWriter,
+		lockTimeout:    m.lockTimeout,
+		tableName:      m.tableName,
+		trackerDialect: dialect,
+		phase:          m.phase,
+		dropAll:        m.dropAll,
+		connName:       m.connName,
+	}
+	c.init()
+	return c
+}
+
+// init builds the tracker, batch manager and runner once the options have
+// been applied.
+func (m *Migrator) init() {
+	dialect := m.trackerDialect
+	if dialect == nil {
+		dialect = trackerDialectForGrammar(m.grammar)
+	}
+	m.tracker = NewTrackerWithDialect(m.db, m.tableName, dialect)
+	m.batch = NewBatchManager(m.tracker)
 
 	// Ensure runner exists even if no grammar option was provided.
 	if m.runner == nil {
-		m.runner = NewRunner(db, m.grammar, m.logger)
+		m.runner = NewRunner(m.db, m.grammar, m.logger)
 	}
 
 	// Propagate dry-run settings to the runner.
 	if m.dryRun && m.dryRunWriter != nil {
 		m.runner.SetDryRun(m.dryRunWriter)
 	}
-
-	return m
 }
 
 // Register adds a migration to the registry.
@@ -160,63 +350,149 @@ func (m *Migrator) AfterMigrate(fn AfterHookFunc) {
 	m.hooks.RegisterAfter(fn)
 }
 
+// withLock runs fn while holding the migration lock, if a Locker is
+// configured. Dry runs do not change state and therefore skip locking.
+// The lock wait is bounded by both ctx and the configured lock timeout.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) (err error) {
+	if m.locker == nil || m.dryRun {
+		return fn()
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+	defer cancel()
+	if err := m.locker.Lock(lockCtx); err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := m.locker.Unlock(context.Background()); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+
+	return fn()
+}
+
+// ForceUnlock releases a migration lock left behind by a process that died
+// while holding it and reports whether a lock was held. Only Lockers that
+// implement ForceUnlocker keep such locks; the PostgreSQL and MySQL locks
+// are released when their session ends, so for them it reports false.
+func (m *Migrator) ForceUnlock() (bool, error) {
+	return m.ForceUnlockContext(context.Background())
+}
+
+// ForceUnlockContext is like ForceUnlock but executes with ctx.
+func (m *Migrator) ForceUnlockContext(ctx context.Context) (bool, error) {
+	fu, ok := m.locker.(ForceUnlocker)
+	if !ok {
+		return false, nil
+	}
+	return fu.ForceUnlock(ctx)
+}
+
 // Up runs all pending migrations in timestamp order.
 // All migrations executed in a single Up() call share the same batch number.
 func (m *Migrator) Up() error {
-	if err := m.tracker.EnsureTable(); err != nil {
+	return m.UpContext(context.Background())
+}
+
+// UpContext is like Up but stops when ctx is cancelled. The migration that
+// is running at that moment has its transaction rolled back.
+func (m *Migrator) UpContext(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error { return c.up(ctx) })
+	})
+}
+
+// up implements Up without acquiring the migration lock.
+func (m *Migrator) up(ctx context.Context) error {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
 		return err
 	}
 
 	registered := m.registry.GetAll()
-	applied, err := m.tracker.GetApplied()
+	applied, err := m.tracker.GetAppliedContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	// Build a set of applied migration names for fast lookup.
-	appliedSet := make(map[string]struct{}, len(applied))
+	// A database without recorded migrations starts from the baseline.
+	if len(applied) == 0 {
+		covered, err := m.loadSchemaDump(ctx)
+		if err != nil {
+			return err
+		}
+		for _, name := range covered {
+			applied = append(applied, MigrationRecord{Name: name})
+		}
+	}
+
+	pending, reached := m.pendingMigrations(registered, applied)
+	return m.runUp(ctx, pending, reached)
+}
+
+// pendingMigrations returns the registered migrations that are not applied
+// and the phased migrations whose recorded phase is before the target
+// phase. reached maps the latter to the phase they completed.
+func (m *Migrator) pendingMigrations(registered []registeredMigration, applied []MigrationRecord) (pending []registeredMigration, reached map[string]Phase) {
+	appliedMap := make(map[string]MigrationRecord, len(applied))
 	for _, rec := range applied {
-		appliedSet[rec.Name] = struct{}{}
+		appliedMap[rec.Name] = rec
 	}
 
-	// Compute pending migrations (registered but not applied).
-	var pending []registeredMigration
+	reached = make(map[string]Phase)
 	for _, reg := range registered {
-		if _, ok := appliedSet[reg.Name]; !ok {
+		rec, ok := appliedMap[reg.Name]
+		if !ok {
 			pending = append(pending, reg)
+			continue
+		}
+		if _, phased := reg.Migration.(*phasedMigration); phased && rec.Phase != "" &&
+			rec.Phase.index() < m.phase.index() {
+			pending = append(pending, reg)
+			reached[reg.Name] = rec.Phase
 		}
 	}
+	return pending, reached
+}
 
+// runUp applies the given pending migrations, in order, as one new batch.
+// Phased migrations listed in reached continue from the phase they
+// completed and keep their original batch.
+func (m *Migrator) runUp(ctx context.Context, pending []registeredMigration, reached map[string]Phase) error {
 	if len(pending) == 0 {
 		return nil
 	}
 
-	batchNumber, err := m.batch.NextBatchNumber()
+	batchNumber, err := m.batch.NextBatchNumberContext(ctx)
 	if err != nil {
 		return err
 	}
 
 	total := len(pending)
 	for i, p := range pending {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if err := m.hooks.RunBefore(p.Name, "up"); err != nil {
 			return fmt.Errorf("before hook for %q: %w", p.Name, err)
 		}
 
 		start := time.Now()
 
-		if m.dryRun {
-			if err := m.runner.ExecuteDryRun(p.Migration, "up", p.Name); err != nil {
+		if pm, ok := p.Migration.(*phasedMigration); ok {
+			if err := m.runPhases(ctx, p.Name, pm, reached[p.Name], batchNumber); err != nil {
+				return err
+			}
+		} else if m.dryRun {
+			if err := m.runner.ExecuteDryRunContext(ctx, p.Migration, "up", p.Name); err != nil {
 				return fmt.Errorf("migration %q up: %w", p.Name, err)
 			}
 		} else {
-			if err := m.runner.Execute(p.Migration, "up", p.Name); err != nil {
-				return fmt.Errorf("migration %q up: %w", p.Name, err)
+			record := func(ctx context.Context, exec trackerExecutor, checksum string) error {
+				return m.tracker.on(exec).RecordContext(ctx, p.Name, batchNumber, checksum)
 			}
-		}
-
-		if !m.dryRun {
-			if err := m.tracker.Record(p.Name, batchNumber); err != nil {
-				return err
+			if _, err := m.runner.execute(ctx, p.Migration, "up", p.Name, record); err != nil {
+				return fmt.Errorf("migration %q up: %w", p.Name, err)
 			}
 		}
 
@@ -240,8 +516,22 @@ func (m *Migrator) Up() error {
 // Rollback rolls back migrations.
 // If steps == 0, rolls back the last batch.
 // If steps > 0, rolls back the last N individual migrations.
+// Rollback stops at migrations pruned into the schema dump configured
+// with WithSchemaDump, which stay applied as the baseline.
 func (m *Migrator) Rollback(steps int) error {
-	if err := m.tracker.EnsureTable(); err != nil {
+	return m.RollbackContext(context.Background(), steps)
+}
+
+// RollbackContext is like Rollback but stops when ctx is cancelled.
+func (m *Migrator) RollbackContext(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error { return c.rollback(ctx, steps) })
+	})
+}
+
+// rollback implements Rollback without acquiring the migration lock.
+func (m *Migrator) rollback(ctx context.Context, steps int) error {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
 		return err
 	}
 
@@ -249,9 +539,9 @@ func (m *Migrator) Rollback(steps int) error {
 	var err error
 
 	if steps == 0 {
-		records, err = m.batch.GetLastBatch()
+		records, err = m.batch.GetLastBatchContext(ctx)
 	} else {
-		records, err = m.batch.GetLastNMigrations(steps)
+		records, err = m.batch.GetLastNMigrationsContext(ctx, steps)
 	}
 	if err != nil {
 		return err
@@ -266,9 +556,20 @@ func (m *Migrator) Rollback(steps int) error {
 	if steps == 0 {
 		reverseRecords(records)
 	}
+	if records, err = m.stopAtBaseline(records); err != nil {
+		return err
+	}
 
+	return m.runDown(ctx, records)
+}
+
+// runDown rolls back the given applied migrations in the order given.
+func (m *Migrator) runDown(ctx context.Context, records []MigrationRecord) error {
 	total := len(records)
 	for i, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		migration, err := m.registry.Get(rec.Name)
 		if err != nil {
 			return err
@@ -281,19 +582,11 @@ func (m *Migrator) Rollback(steps int) error {
 		start := time.Now()
 
 		if m.dryRun {
-			if err := m.runner.ExecuteDryRun(migration, "down", rec.Name); err != nil {
-				return fmt.Errorf("migration %q down: %w", rec.Name, err)
-			}
-		} else {
-			if err := m.runner.Execute(migration, "down", rec.Name); err != nil {
+			if err := m.runner.ExecuteDryRunContext(ctx, migration, "down", rec.Name); err != nil {
 				return fmt.Errorf("migration %q down: %w", rec.Name, err)
 			}
-		}
-
-		if !m.dryRun {
-			if err := m.tracker.Remove(rec.Name); err != nil {
-				return err
-			}
+		} else if _, err := m.runner.execute(ctx, migration, "down", rec.Name, m.remove(rec.Name)); err != nil {
+			return fmt.Errorf("migration %q down: %w", rec.Name, err)
 		}
 
 		duration := time.Since(start)
@@ -313,22 +606,49 @@ func (m *Migrator) Rollback(steps int) error {
 	return nil
 }
 
-// Reset rolls back all applied migrations in reverse order.
+// remove returns the finishFunc that removes the record of a migration
+// rolled back.
+func (m *Migrator) remove(name string) finishFunc {
+	return func(ctx context.Context, exec trackerExecutor, _ string) error {
+		return m.tracker.on(exec).RemoveContext(ctx, name)
+	}
+}
+
+// Reset rolls back all applied migrations in reverse order, down to the
+// baseline when migrations were pruned into the schema dump.
 func (m *Migrator) Reset() error {
-	if err := m.tracker.EnsureTable(); err != nil {
+	return m.ResetContext(context.Background())
+}
+
+// ResetContext is like Reset but stops when ctx is cancelled.
+func (m *Migrator) ResetContext(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error { return c.reset(ctx) })
+	})
+}
+
+// reset implements Reset without acquiring the migration lock.
+func (m *Migrator) reset(ctx context.Context) error {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
 		return err
 	}
 
-	applied, err := m.tracker.GetApplied()
+	applied, err := m.tracker.GetAppliedContext(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Reverse to execute Down() in reverse timestamp order.
 	reverseRecords(applied)
+	if applied, err = m.stopAtBaseline(applied); err != nil {
+		return err
+	}
 
 	total := len(applied)
 	for i, rec := range applied {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		migration, err := m.registry.Get(rec.Name)
 		if err != nil {
 			return err
@@ -337,19 +657,11 @@ func (m *Migrator) Reset() error {
 		start := time.Now()
 
 		if m.dryRun {
-			if err := m.runner.ExecuteDryRun(migration, "down", rec.Name); err != nil {
-				return fmt.Errorf("migration %q down: %w", rec.Name, err)
-			}
-		} else {
-			if err := m.runner.Execute(migration, "down", rec.Name); err != nil {
+			if err := m.runner.ExecuteDryRunContext(ctx, migration, "down", rec.Name); err != nil {
 				return fmt.Errorf("migration %q down: %w", rec.Name, err)
 			}
-		}
-
-		if !m.dryRun {
-			if err := m.tracker.Remove(rec.Name); err != nil {
-				return err
-			}
+		} else if _, err := m.runner.execute(ctx, migration, "down", rec.Name, m.remove(rec.Name)); err != nil {
+			return fmt.Errorf("migration %q down: %w", rec.Name, err)
 		}
 
 		duration := time.Since(start)
@@ -371,44 +683,114 @@ func (m *Migrator) Reset() error {
 
 // Refresh resets all migrations and then runs them all up again.
 func (m *Migrator) Refresh() error {
-	if err := m.Reset(); err != nil {
-		return fmt.Errorf("refresh reset phase: %w", err)
-	}
-	if err := m.Up(); err != nil {
-		return fmt.Errorf("refresh up phase: %w", err)
-	}
-	return nil
+	return m.RefreshContext(context.Background())
+}
+
+// RefreshContext is like Refresh but stops when ctx is cancelled.
+func (m *Migrator) RefreshContext(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		if err := m.eachConnection(func(c *Migrator) error { return c.reset(ctx) }); err != nil {
+			return fmt.Errorf("refresh reset phase: %w", err)
+		}
+		if err := m.eachConnection(func(c *Migrator) error { return c.up(ctx) }); err != nil {
+			return fmt.Errorf("refresh up phase: %w", err)
+		}
+		return nil
+	})
 }
 
 // Fresh drops all tables and then runs all migrations up.
-// Requires a grammar to be configured via WithGrammar.
+// Requires a grammar on every connection: WithGrammar for the one passed
+// to New and the grammar argument of WithConnection for the others.
 func (m *Migrator) Fresh() error {
-	if m.grammar == nil {
-		return fmt.Errorf("fresh requires a grammar: configure with WithGrammar")
+	return m.FreshContext(context.Background())
+}
+
+// FreshContext is like Fresh but stops when ctx is cancelled.
+func (m *Migrator) FreshContext(ctx context.Context) error {
+	if err := m.requireGrammar("fresh"); err != nil {
+		return err
 	}
+	return m.withLock(ctx, func() error {
+		return m.eachConnection(func(c *Migrator) error { return c.fresh(ctx) })
+	})
+}
 
-	dropSQL := m.grammar.CompileDropAllTables()
+// fresh implements Fresh without acquiring the migration lock.
+func (m *Migrator) fresh(ctx context.Context) error {
+	opts := m.dropAll
+	// The SQLite lock row must survive until Unlock.
+	if l, ok := m.locker.(*SQLiteLocker); ok {
+		opts.Except = append(append([]string(nil), opts.Except...), l.table)
+	}
 
 	if m.dryRun {
-		fmt.Fprintf(m.dryRunWriter, "-- Fresh: drop all tables\n%s;\n", dropSQL)
-	} else {
-		if _, err := m.db.Exec(dropSQL); err != nil {
+		statements, err := m.grammar.CompileDropAll(ctx, m.db, opts)
+		if err != nil {
 			return fmt.Errorf("drop all tables: %w", err)
 		}
+		fmt.Fprintf(m.dryRunWriter, "-- Fresh: drop all tables\n")
+		for _, stmt := range statements {
+			fmt.Fprintf(m.dryRunWriter, "%s;\n", stmt)
+		}
+	} else if err := m.dropAllTables(ctx, opts); err != nil {
+		return err
 	}
 
-	return m.Up()
+	return m.up(ctx)
+}
+
+// dropAllTables runs the grammar's drop-all procedure on a single
+// connection, since it changes session settings such as foreign key
+// checks.
+func (m *Migrator) dropAllTables(ctx context.Context, opts schema.DropAllOptions) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("drop all tables: %w", err)
+	}
+	defer conn.Close()
+
+	statements, err := m.grammar.CompileDropAll(ctx, conn, opts)
+	if err != nil {
+		return fmt.Errorf("drop all tables: %w", err)
+	}
+	for _, stmt := range statements {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("drop all tables: %w", err)
+		}
+	}
+	return nil
 }
 
 // Status returns the status of all registered migrations, indicating
 // whether each has been applied, its batch number, and applied timestamp.
 func (m *Migrator) Status() ([]MigrationStatus, error) {
-	if err := m.tracker.EnsureTable(); err != nil {
+	return m.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but executes with ctx.
+func (m *Migrator) StatusContext(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.eachConnection(func(c *Migrator) error {
+		s, err := c.status(ctx)
+		statuses = append(statuses, s...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// status implements Status for a single connection.
+func (m *Migrator) status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.tracker.EnsureTableContext(ctx); err != nil {
 		return nil, err
 	}
 
 	registered := m.registry.GetAll()
-	applied, err := m.tracker.GetApplied()
+	applied, err := m.tracker.GetAppliedContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -421,12 +803,20 @@ func (m *Migrator) Status() ([]MigrationStatus, error) {
 
 	statuses := make([]MigrationStatus, 0, len(registered))
 	for _, reg := range registered {
-		status := MigrationStatus{Name: reg.Name}
+		status := MigrationStatus{Name: reg.Name, Connection: m.connName}
 		if rec, ok := appliedMap[reg.Name]; ok {
 			status.Applied = true
 			status.Batch = rec.Batch
 			t := rec.CreatedAt
 			status.AppliedAt = &t
+			status.Phase = rec.Phase
+			if rec.Checksum != "" {
+				current, err := m.currentChecksum(ctx, reg.Migration)
+				if err != nil {
+					status.ChecksumErr = err
+				}
+				status.Modified = current != "" && current != rec.Checksum
+			}
 		}
 		statuses = append(statuses, status)
 	}
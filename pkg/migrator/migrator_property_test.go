@@ -128,8 +128,7 @@ func TestProperty5_UpExecutesOnlyPendingInOrder(t *testing.T) {
 		if len(expectedPending) > 0 {
 			expectMaxBatch(mock, maxBatch)
 			for _, name := range expectedPending {
-				expectMigrationTx(mock)
-				expectRecord(mock, name, maxBatch+1)
+				expectRecordTx(mock, name, maxBatch+1)
 			}
 		}
 
@@ -183,9 +182,8 @@ func TestProperty6_BatchNumberConsistency(t *testing.T) {
 		if len(pendingNames) > 0 {
 			expectMaxBatch(mock, maxBatch)
 			for _, name := range pendingNames {
-				expectMigrationTx(mock)
 				// Each pending migration must be recorded with exactly expectedBatch
-				expectRecord(mock, name, expectedBatch)
+				expectRecordTx(mock, name, expectedBatch)
 			}
 		}
 
@@ -255,11 +253,11 @@ func TestProperty7_RollbackByBatchReversesLastBatch(t *testing.T) {
 		expectEnsureTable(mock)
 		expectMaxBatch(mock, maxBatch)
 
-		batchRows := sqlmock.NewRows([]string{"migration", "batch", "created_at"})
+		batchRows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"})
 		for _, r := range lastBatchRecords {
-			batchRows.AddRow(r.Name, r.Batch, r.CreatedAt)
+			batchRows.AddRow(r.Name, r.Batch, r.CreatedAt, nil, nil)
 		}
-		mock.ExpectQuery("SELECT migration, batch, created_at FROM .* WHERE batch").
+		mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM .* WHERE batch").
 			WithArgs(maxBatch).WillReturnRows(batchRows)
 
 		// Rollback reverses the lastBatchRecords (they come in ascending order from GetByBatch)
@@ -270,8 +268,7 @@ func TestProperty7_RollbackByBatchReversesLastBatch(t *testing.T) {
 		}
 
 		for _, r := range reversed {
-			expectMigrationTx(mock)
-			expectRemove(mock, r.Name)
+			expectRemoveTx(mock, r.Name)
 		}
 
 		err = m.Rollback(0)
@@ -337,8 +334,7 @@ func TestProperty8_RollbackByStepCount(t *testing.T) {
 		}
 
 		for _, name := range expectedRolledBack {
-			expectMigrationTx(mock)
-			expectRemove(mock, name)
+			expectRemoveTx(mock, name)
 		}
 
 		err = m.Rollback(steps)
@@ -394,10 +390,9 @@ func TestProperty9_RollbackRemovesTrackerRecords(t *testing.T) {
 		expectGetApplied(mock, records)
 
 		for i := len(names) - 1; i >= len(names)-expectedCount; i-- {
-			expectMigrationTx(mock)
 			// Track the expected removes
 			removedNames = append(removedNames, names[i])
-			expectRemove(mock, names[i])
+			expectRemoveTx(mock, names[i])
 		}
 
 		err = m.Rollback(steps)
@@ -443,8 +438,7 @@ func TestProperty10_ResetClearsAllApplied(t *testing.T) {
 		}
 
 		for _, r := range reversed {
-			expectMigrationTx(mock)
-			expectRemove(mock, r.Name)
+			expectRemoveTx(mock, r.Name)
 		}
 
 		err = m.Reset()
@@ -498,8 +492,7 @@ func TestProperty11_RefreshEqualsResetThenUp(t *testing.T) {
 		}
 
 		for _, r := range reversed {
-			expectMigrationTx(mock)
-			expectRemove(mock, r.Name)
+			expectRemoveTx(mock, r.Name)
 		}
 
 		// --- Up phase expectations ---
@@ -509,8 +502,7 @@ func TestProperty11_RefreshEqualsResetThenUp(t *testing.T) {
 		expectMaxBatch(mock, 0)     // fresh start
 
 		for _, name := range names {
-			expectMigrationTx(mock)
-			expectRecord(mock, name, 1)
+			expectRecordTx(mock, name, 1)
 		}
 
 		err = m.Refresh()
@@ -1,6 +1,7 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,17 +25,29 @@ func newTestMigrator(t *testing.T) (*Migrator, *sql.DB, sqlmock.Sqlmock) {
 }
 
 // expectEnsureTable sets up the sqlmock expectation for CREATE TABLE IF NOT EXISTS.
+// It includes the probes for the checksum and phase columns, reporting them
+// as present.
 func expectEnsureTable(mock sqlmock.Sqlmock) {
 	mock.ExpectExec("CREATE TABLE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM").WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectQuery("SELECT phase FROM").WillReturnRows(sqlmock.NewRows([]string{"phase"}))
 }
 
 // expectGetApplied sets up a query expectation returning the given migration names/batches.
 func expectGetApplied(mock sqlmock.Sqlmock, records []MigrationRecord) {
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"})
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"})
 	for _, r := range records {
-		rows.AddRow(r.Name, r.Batch, r.CreatedAt)
+		var checksum any
+		if r.Checksum != "" {
+			checksum = r.Checksum
+		}
+		var phase any
+		if r.Phase != "" {
+			phase = string(r.Phase)
+		}
+		rows.AddRow(r.Name, r.Batch, r.CreatedAt, checksum, phase)
 	}
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM").WillReturnRows(rows)
 }
 
 // expectMaxBatch sets up the COALESCE(MAX(batch), 0) query.
@@ -45,7 +59,7 @@ func expectMaxBatch(mock sqlmock.Sqlmock, batch int) {
 
 // expectRecord sets up an INSERT expectation for recording a migration.
 func expectRecord(mock sqlmock.Sqlmock, name string, batch int) {
-	mock.ExpectExec("INSERT INTO").WithArgs(name, batch).
+	mock.ExpectExec("INSERT INTO").WithArgs(name, batch, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 }
 
@@ -61,6 +75,22 @@ func expectMigrationTx(mock sqlmock.Sqlmock) {
 	mock.ExpectCommit()
 }
 
+// expectRecordTx sets up a migration transaction that records the
+// migration before it commits.
+func expectRecordTx(mock sqlmock.Sqlmock, name string, batch int) {
+	mock.ExpectBegin()
+	expectRecord(mock, name, batch)
+	mock.ExpectCommit()
+}
+
+// expectRemoveTx sets up a migration transaction that removes the
+// migration's record before it commits.
+func expectRemoveTx(mock sqlmock.Sqlmock, name string) {
+	mock.ExpectBegin()
+	expectRemove(mock, name)
+	mock.ExpectCommit()
+}
+
 // --- Up Tests ---
 
 func TestUp_PendingMigrations(t *testing.T) {
@@ -75,12 +105,10 @@ func TestUp_PendingMigrations(t *testing.T) {
 	expectMaxBatch(mock, 0)     // first batch
 
 	// Migration 1
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_create_users", 1)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
 
 	// Migration 2
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240102000000_create_posts", 1)
+	expectRecordTx(mock, "20240102000000_create_posts", 1)
 
 	err := m.Up()
 	assert.NoError(t, err)
@@ -117,14 +145,39 @@ func TestUp_PartiallyApplied(t *testing.T) {
 	expectMaxBatch(mock, 1) // next batch = 2
 
 	// Only migration 2 should run
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240102000000_create_posts", 2)
+	expectRecordTx(mock, "20240102000000_create_posts", 2)
 
 	err := m.Up()
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpContext_CancelStopsBeforeNextMigration(t *testing.T) {
+	m, db, mock := newTestMigrator(t)
+	defer db.Close()
+
+	second := &noopMigration{}
+	require.NoError(t, m.Register("20240101000000_create_users", &noopMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_posts", second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.AfterMigrate(func(name, direction string, d time.Duration) error {
+		cancel()
+		return nil
+	})
+
+	expectEnsureTable(mock)
+	expectGetApplied(mock, nil)
+	expectMaxBatch(mock, 0)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
+
+	err := m.UpContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, second.upCalled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // --- Rollback Tests ---
 
 func TestRollback_ByBatch(t *testing.T) {
@@ -138,18 +191,16 @@ func TestRollback_ByBatch(t *testing.T) {
 
 	// GetLastBatch: max batch = 1, then GetByBatch(1)
 	expectMaxBatch(mock, 1)
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-		AddRow("20240101000000_create_users", 1, time.Now()).
-		AddRow("20240102000000_create_posts", 1, time.Now())
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM .* WHERE batch").
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+		AddRow("20240101000000_create_users", 1, time.Now(), nil, nil).
+		AddRow("20240102000000_create_posts", 1, time.Now(), nil, nil)
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM .* WHERE batch").
 		WithArgs(1).WillReturnRows(rows)
 
 	// Rollback in reverse order: posts first, then users
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240102000000_create_posts")
+	expectRemoveTx(mock, "20240102000000_create_posts")
 
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240101000000_create_users")
+	expectRemoveTx(mock, "20240101000000_create_users")
 
 	err := m.Rollback(0)
 	assert.NoError(t, err)
@@ -174,11 +225,9 @@ func TestRollback_BySteps(t *testing.T) {
 	})
 
 	// Rollback: tags first, then posts (reverse order)
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240103000000_create_tags")
+	expectRemoveTx(mock, "20240103000000_create_tags")
 
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240102000000_create_posts")
+	expectRemoveTx(mock, "20240102000000_create_posts")
 
 	err := m.Rollback(2)
 	assert.NoError(t, err)
@@ -201,11 +250,9 @@ func TestReset(t *testing.T) {
 	})
 
 	// Reverse order: posts first, then users
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240102000000_create_posts")
+	expectRemoveTx(mock, "20240102000000_create_posts")
 
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240101000000_create_users")
+	expectRemoveTx(mock, "20240101000000_create_users")
 
 	err := m.Reset()
 	assert.NoError(t, err)
@@ -259,8 +306,7 @@ func TestFresh(t *testing.T) {
 	expectEnsureTable(mock)
 	expectGetApplied(mock, nil)
 	expectMaxBatch(mock, 0)
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_create_users", 1)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
 
 	err := m.Fresh()
 	assert.NoError(t, err)
@@ -279,6 +325,40 @@ func TestFresh_DropFails(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// TestFresh_SQLite drops related tables and views on SQLite, which the
+// grammar enumerates, while the SQLite lock is held.
+func TestFresh_SQLite(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE legacy (id INTEGER PRIMARY KEY);
+		CREATE TABLE legacy_items (id INTEGER PRIMARY KEY, legacy_id INTEGER REFERENCES legacy (id));
+		CREATE VIEW legacy_view AS SELECT id FROM legacy`)
+	require.NoError(t, err)
+
+	m := New(db,
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithLocker(NewSQLiteLocker(db, "migrations")),
+		WithDropAll(schema.DropAllOptions{Views: true}),
+	)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Fresh())
+
+	var names []string
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE name NOT LIKE 'sqlite_%' ORDER BY name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"migrations", "migrations_lock", "users"}, names)
+
+	var held int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM migrations_lock`).Scan(&held))
+	assert.Equal(t, 0, held, "the lock was released")
+}
+
 func TestFresh_NoGrammar(t *testing.T) {
 	db, _, err := sqlmock.New()
 	require.NoError(t, err)
@@ -303,15 +383,13 @@ func TestRefresh(t *testing.T) {
 	expectGetApplied(mock, []MigrationRecord{
 		{Name: "20240101000000_create_users", Batch: 1, CreatedAt: time.Now()},
 	})
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240101000000_create_users")
+	expectRemoveTx(mock, "20240101000000_create_users")
 
 	// Up phase: EnsureTable + GetApplied (empty) + NextBatch + Execute + Record
 	expectEnsureTable(mock)
 	expectGetApplied(mock, nil)
 	expectMaxBatch(mock, 0)
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_create_users", 1)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
 
 	err := m.Refresh()
 	assert.NoError(t, err)
@@ -355,8 +433,7 @@ func TestUp_AfterHookCalled(t *testing.T) {
 	expectEnsureTable(mock)
 	expectGetApplied(mock, nil)
 	expectMaxBatch(mock, 0)
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_create_users", 1)
+	expectRecordTx(mock, "20240101000000_create_users", 1)
 
 	err := m.Up()
 	assert.NoError(t, err)
@@ -375,24 +452,28 @@ func TestWithTableName(t *testing.T) {
 	require.NoError(t, m.Register("20240101000000_create_users", &noopMigration{}))
 
 	// EnsureTable should use custom table name
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS custom_migrations").
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"custom_migrations\"").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM \"custom_migrations\"").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectQuery("SELECT phase FROM \"custom_migrations\"").
+		WillReturnRows(sqlmock.NewRows([]string{"phase"}))
 
 	// GetApplied from custom table
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM custom_migrations").
-		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at"}))
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"custom_migrations\"").
+		WillReturnRows(sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}))
 
 	// NextBatchNumber
 	mock.ExpectQuery("SELECT COALESCE").WillReturnRows(
 		sqlmock.NewRows([]string{"max"}).AddRow(0),
 	)
 
-	expectMigrationTx(mock)
-
-	// Record into custom table
-	mock.ExpectExec("INSERT INTO custom_migrations").
-		WithArgs("20240101000000_create_users", 1).
+	// Record into custom table, inside the migration's transaction
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO \"custom_migrations\"").
+		WithArgs("20240101000000_create_users", 1, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	err = m.Up()
 	assert.NoError(t, err)
@@ -463,8 +544,7 @@ func TestUp_FailureStopsSubsequent(t *testing.T) {
 	expectMaxBatch(mock, 0)
 
 	// First migration succeeds
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_first", 1)
+	expectRecordTx(mock, "20240101000000_first", 1)
 
 	// Second migration fails: begin + rollback (no commit, no record)
 	mock.ExpectBegin()
@@ -500,11 +580,11 @@ func TestRollback_FailureStopsFurtherRollback(t *testing.T) {
 
 	// Rollback by batch: all 3 in batch 1
 	expectMaxBatch(mock, 1)
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-		AddRow("20240101000000_first", 1, time.Now()).
-		AddRow("20240102000000_second", 1, time.Now()).
-		AddRow("20240103000000_third", 1, time.Now())
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM .* WHERE batch").
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+		AddRow("20240101000000_first", 1, time.Now(), nil, nil).
+		AddRow("20240102000000_second", 1, time.Now(), nil, nil).
+		AddRow("20240103000000_third", 1, time.Now(), nil, nil)
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM .* WHERE batch").
 		WithArgs(1).WillReturnRows(rows)
 
 	// Reverse order: third rolls back first — but it fails
@@ -537,8 +617,7 @@ func TestRefresh_UpPhaseFailure(t *testing.T) {
 	expectGetApplied(mock, []MigrationRecord{
 		{Name: "20240101000000_first", Batch: 1, CreatedAt: time.Now()},
 	})
-	expectMigrationTx(mock)
-	expectRemove(mock, "20240101000000_first")
+	expectRemoveTx(mock, "20240101000000_first")
 
 	// Up phase: both pending, first succeeds, second fails
 	expectEnsureTable(mock)
@@ -546,8 +625,7 @@ func TestRefresh_UpPhaseFailure(t *testing.T) {
 	expectMaxBatch(mock, 0)
 
 	// First migration succeeds
-	expectMigrationTx(mock)
-	expectRecord(mock, "20240101000000_first", 1)
+	expectRecordTx(mock, "20240101000000_first", 1)
 
 	// Second migration fails
 	mock.ExpectBegin()
@@ -560,3 +638,70 @@ func TestRefresh_UpPhaseFailure(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// --- Interruption Tests ---
+
+// interruptedMigration creates a table and then cancels the context of the
+// run, as an interrupt arriving right after the schema change would.
+type interruptedMigration struct {
+	cancel context.CancelFunc
+	noTx   bool
+}
+
+func (m *interruptedMigration) Up(b *schema.Builder) error {
+	if err := b.Create("widgets", func(bp *schema.Blueprint) { bp.ID() }); err != nil {
+		return err
+	}
+	m.cancel()
+	return nil
+}
+
+func (m *interruptedMigration) Down(b *schema.Builder) error {
+	if err := b.Drop("widgets"); err != nil {
+		return err
+	}
+	m.cancel()
+	return nil
+}
+
+func (m *interruptedMigration) DisableTransaction() bool { return m.noTx }
+
+func TestUp_InterruptKeepsSchemaAndRecordConsistent(t *testing.T) {
+	for _, noTx := range []bool{false, true} {
+		db := openSQLite(t)
+		m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithTrackerDialect(SQLiteTrackerDialect{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, m.Register("20240101000000_create_widgets", &interruptedMigration{cancel: cancel, noTx: noTx}))
+
+		_ = m.UpContext(ctx)
+
+		statuses, err := m.Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		assert.Equal(t, hasSQLiteTable(t, db, "widgets"), statuses[0].Applied,
+			"without a transaction %v: the table exists exactly when the migration is recorded", noTx)
+		if noTx {
+			assert.True(t, statuses[0].Applied, "a change that cannot roll back is still recorded")
+		}
+	}
+}
+
+func TestRollback_InterruptKeepsSchemaAndRecordConsistent(t *testing.T) {
+	for _, noTx := range []bool{false, true} {
+		db := openSQLite(t)
+		mig := &interruptedMigration{cancel: func() {}, noTx: noTx}
+		m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithTrackerDialect(SQLiteTrackerDialect{}))
+		require.NoError(t, m.Register("20240101000000_create_widgets", mig))
+		require.NoError(t, m.Up())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mig.cancel = cancel
+		_ = m.RollbackContext(ctx, 0)
+
+		statuses, err := m.Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		assert.Equal(t, hasSQLiteTable(t, db, "widgets"), statuses[0].Applied,
+			"without a transaction %v: the table exists exactly when the migration is recorded", noTx)
+	}
+}
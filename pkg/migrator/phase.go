@@ -0,0 +1,190 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// Phase identifies one step of a PhasedMigration.
+type Phase string
+
+// The phases of an expand/contract migration, in the order they run.
+const (
+	// PhaseExpand makes additive, backwards-compatible changes such as
+	// adding the new column.
+	PhaseExpand Phase = "expand"
+	// PhaseBackfill copies existing data into the expanded schema.
+	PhaseBackfill Phase = "backfill"
+	// PhaseContract removes what the application no longer uses, such as
+	// the old column.
+	PhaseContract Phase = "contract"
+)
+
+// phaseOrder lists the phases in the order they run.
+var phaseOrder = []Phase{PhaseExpand, PhaseBackfill, PhaseContract}
+
+// ParsePhase returns the Phase named s. It returns ErrUnknownPhase for
+// any other value.
+func ParsePhase(s string) (Phase, error) {
+	for _, p := range phaseOrder {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%q: %w", s, ErrUnknownPhase)
+}
+
+// index returns the position of p in phaseOrder, or -1 for the empty
+// phase of a migration that has not started.
+func (p Phase) index() int {
+	for i, q := range phaseOrder {
+		if q == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// PhasedMigration is a zero-downtime migration split into phases that run
+// in separate deploys: Expand adds the new schema, Backfill copies the
+// existing data and Contract drops what the application no longer reads.
+// Register it with Phased.
+//
+// Backfill always runs outside a transaction so that each chunk commits
+// on its own; see BackfillInChunks. Expand and Contract run in a
+// transaction unless the migration implements TransactionOption.
+//
+// Down is called when the migration is rolled back, whichever phase it
+// reached, and must undo every phase that may have run.
+type PhasedMigration interface {
+	Expand(schema *schema.Builder) error
+	Backfill(schema *schema.Builder) error
+	Contract(schema *schema.Builder) error
+	Down(schema *schema.Builder) error
+}
+
+// Phased adapts a PhasedMigration for registration. The Migrator runs its
+// phases up to the phase set by WithPhase and records the phase reached;
+// later runs with a later phase continue where it stopped. Up runs all
+// phases at once and is only used outside the Migrator, for example by
+// Runner.Execute.
+func Phased(pm PhasedMigration) Migration {
+	return &phasedMigration{PhasedMigration: pm}
+}
+
+// phasedMigration implements Migration for a PhasedMigration.
+type phasedMigration struct {
+	PhasedMigration
+}
+
+func (p *phasedMigration) Up(s *schema.Builder) error {
+	for _, phase := range phaseOrder {
+		if err := p.run(phase, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisableTransaction forwards to the PhasedMigration when it implements
+// TransactionOption.
+func (p *phasedMigration) DisableTransaction() bool {
+	opt, ok := p.PhasedMigration.(TransactionOption)
+	return ok && opt.DisableTransaction()
+}
+
+// Connection forwards to the PhasedMigration when it implements
+// ConnectionOption.
+func (p *phasedMigration) Connection() string {
+	if opt, ok := p.PhasedMigration.(ConnectionOption); ok {
+		return opt.Connection()
+	}
+	return ""
+}
+
+// run calls the method of the given phase.
+func (p *phasedMigration) run(phase Phase, s *schema.Builder) error {
+	switch phase {
+	case PhaseExpand:
+		return p.Expand(s)
+	case PhaseBackfill:
+		return p.Backfill(s)
+	case PhaseContract:
+		return p.Contract(s)
+	default:
+		return fmt.Errorf("%q: %w", phase, ErrUnknownPhase)
+	}
+}
+
+// BackfillInChunks executes query until it affects no rows and returns the
+// total number of rows affected. query should update a bounded chunk of
+// the rows that still need backfilling, for example
+//
+//	UPDATE users SET full_name = first_name || ' ' || last_name
+//	WHERE id IN (SELECT id FROM users WHERE full_name IS NULL LIMIT 1000)
+//
+// Because Backfill runs outside a transaction every chunk commits on its
+// own, keeping locks short on busy tables. Dry runs print the statement
+// once.
+func BackfillInChunks(s *schema.Builder, query string, args ...any) (int64, error) {
+	var total int64
+	for {
+		if err := s.Context().Err(); err != nil {
+			return total, err
+		}
+		n, err := s.Exec(query, args...)
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, nil
+		}
+		total += n
+	}
+}
+
+// runPhases runs the phases of a phased migration that come after from, up
+// to and including the Migrator's target phase, recording each phase as it
+// completes. A migration that has not started is recorded in batch.
+func (m *Migrator) runPhases(ctx context.Context, name string, pm *phasedMigration, from Phase, batch int) error {
+	recorded := from != ""
+	var checksum string
+	if !recorded && !m.dryRun {
+		// The checksum covers all phases, like the one Status compares it
+		// to. It is compiled without the database, whose schema does not
+		// have the later phases' prerequisites yet; a migration whose
+		// checksum cannot be computed is recorded without one.
+		checksum, _ = m.currentChecksum(ctx, pm)
+	}
+
+	for _, phase := range phaseOrder[from.index()+1:] {
+		if phase.index() > m.phase.index() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if m.dryRun {
+			label := fmt.Sprintf("%s (%s)", name, phase)
+			if err := m.runner.ExecuteDryRunContext(ctx, pm, string(phase), label); err != nil {
+				return fmt.Errorf("migration %q %s: %w", name, phase, err)
+			}
+			continue
+		}
+
+		record := func(ctx context.Context, exec trackerExecutor, _ string) error {
+			if recorded {
+				return m.tracker.on(exec).SetPhaseContext(ctx, name, phase)
+			}
+			return m.tracker.on(exec).RecordPhaseContext(ctx, name, batch, checksum, phase)
+		}
+		if _, err := m.runner.execute(ctx, pm, string(phase), name, record); err != nil {
+			return fmt.Errorf("migration %q %s: %w", name, phase, err)
+		}
+		recorded = true
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createPeopleMigration creates a "people" table with a name split over
+// two columns.
+type createPeopleMigration struct{}
+
+func (m *createPeopleMigration) Up(b *schema.Builder) error {
+	return b.Create("people", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("first_name", 50)
+		bp.String("last_name", 50)
+	})
+}
+
+func (m *createPeopleMigration) Down(b *schema.Builder) error {
+	return b.Drop("people")
+}
+
+// fullNameMigration replaces last_name with full_name using
+// expand/contract.
+type fullNameMigration struct{}
+
+func (m *fullNameMigration) Expand(b *schema.Builder) error {
+	return b.Alter("people", func(bp *schema.Blueprint) {
+		bp.String("full_name", 101).Nullable()
+	})
+}
+
+func (m *fullNameMigration) Backfill(b *schema.Builder) error {
+	_, err := BackfillInChunks(b, `UPDATE people SET full_name = first_name || ' ' || last_name
+		WHERE id IN (SELECT id FROM people WHERE full_name IS NULL LIMIT 1)`)
+	return err
+}
+
+func (m *fullNameMigration) Contract(b *schema.Builder) error {
+	return b.Alter("people", func(bp *schema.Blueprint) {
+		bp.DropColumn("last_name")
+	})
+}
+
+func (m *fullNameMigration) Down(b *schema.Builder) error {
+	return b.Alter("people", func(bp *schema.Blueprint) {
+		bp.DropColumn("full_name")
+	})
+}
+
+// newPhasedMigrator returns a Migrator over db with the people migrations
+// registered, running phased migrations up to phase.
+func newPhasedMigrator(t *testing.T, db *sql.DB, pm *fullNameMigration, phase Phase) *Migrator {
+	t.Helper()
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithPhase(phase))
+	require.NoError(t, m.Register("20240101000000_create_people", &createPeopleMigration{}))
+	require.NoError(t, m.Register("20240102000000_full_name", Phased(pm)))
+	return m
+}
+
+func TestParsePhase(t *testing.T) {
+	for _, p := range []Phase{PhaseExpand, PhaseBackfill, PhaseContract} {
+		got, err := ParsePhase(string(p))
+		require.NoError(t, err)
+		assert.Equal(t, p, got)
+	}
+
+	_, err := ParsePhase("migrate")
+	assert.True(t, errors.Is(err, ErrUnknownPhase))
+}
+
+// TestPhasedMigration_SeparateDeploys runs each phase in its own Up call,
+// as three deploys would, and checks the phase recorded after each one.
+func TestPhasedMigration_SeparateDeploys(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	pm := &fullNameMigration{}
+
+	require.NoError(t, newPhasedMigrator(t, db, pm, PhaseExpand).UpContext(ctx))
+	_, err := db.Exec(`INSERT INTO people (first_name, last_name) VALUES ('Ada', 'Lovelace'), ('Alan', 'Turing'), ('Grace', 'Hopper')`)
+	require.NoError(t, err)
+
+	statuses, err := newPhasedMigrator(t, db, pm, PhaseExpand).StatusContext(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Partial())
+	assert.True(t, statuses[1].Applied)
+	assert.True(t, statuses[1].Partial())
+	assert.Equal(t, PhaseExpand, statuses[1].Phase)
+	assert.False(t, statuses[1].Modified)
+
+	// Running the same phase again does nothing; adding full_name twice
+	// would fail.
+	require.NoError(t, newPhasedMigrator(t, db, pm, PhaseExpand).UpContext(ctx))
+
+	require.NoError(t, newPhasedMigrator(t, db, pm, PhaseBackfill).UpContext(ctx))
+	var missing int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM people WHERE full_name IS NULL`).Scan(&missing))
+	assert.Equal(t, 0, missing)
+
+	m := newPhasedMigrator(t, db, pm, PhaseContract)
+	require.NoError(t, m.UpContext(ctx))
+
+	var fullName string
+	require.NoError(t, db.QueryRow(`SELECT full_name FROM people WHERE first_name = 'Grace'`).Scan(&fullName))
+	assert.Equal(t, "Grace Hopper", fullName)
+
+	statuses, err = m.StatusContext(ctx)
+	require.NoError(t, err)
+	assert.False(t, statuses[1].Partial())
+	assert.Equal(t, PhaseContract, statuses[1].Phase)
+	assert.Equal(t, 1, statuses[1].Batch, "later phases keep the original batch")
+	assert.False(t, statuses[1].Modified)
+}
+
+// checkedFullNameMigration is a fullNameMigration whose Contract refuses
+// to run before Expand added full_name.
+type checkedFullNameMigration struct {
+	fullNameMigration
+}
+
+func (m *checkedFullNameMigration) Contract(b *schema.Builder) error {
+	ok, err := b.HasColumn("people", "full_name")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("full_name has not been added")
+	}
+	return m.fullNameMigration.Contract(b)
+}
+
+func TestPhasedMigration_ExpandDoesNotCompileLaterPhasesAgainstTheDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithPhase(PhaseExpand))
+	require.NoError(t, m.Register("20240101000000_create_people", &createPeopleMigration{}))
+	require.NoError(t, m.Register("20240102000000_full_name", Phased(&checkedFullNameMigration{})))
+
+	require.NoError(t, m.UpContext(ctx))
+	var added int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('people') WHERE name = 'full_name'`).Scan(&added))
+	assert.Equal(t, 1, added)
+
+	statuses, err := m.StatusContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PhaseExpand, statuses[1].Phase)
+	assert.False(t, statuses[1].Modified)
+	assert.NoError(t, statuses[1].ChecksumErr)
+}
+
+func TestPhasedMigration_LaterPhaseRunsEarlierPhases(t *testing.T) {
+	db := openSQLite(t)
+	m := newPhasedMigrator(t, db, &fullNameMigration{}, PhaseContract)
+	require.NoError(t, m.Up())
+
+	var columns []string
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('people')`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		columns = append(columns, name)
+	}
+	assert.Equal(t, []string{"id", "first_name", "full_name"}, columns)
+
+	applied, err := m.tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, Phase(""), applied[0].Phase)
+	assert.Equal(t, PhaseContract, applied[1].Phase)
+}
+
+func TestPhasedMigration_RollbackPartial(t *testing.T) {
+	db := openSQLite(t)
+	m := newPhasedMigrator(t, db, &fullNameMigration{}, PhaseExpand)
+	require.NoError(t, m.Up())
+
+	require.NoError(t, m.Rollback(1))
+
+	applied, err := m.tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	var columns int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('people') WHERE name = 'full_name'`).Scan(&columns))
+	assert.Equal(t, 0, columns)
+}
@@ -0,0 +1,312 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// Plan directions.
+const (
+	PlanUp   = "up"
+	PlanDown = "down"
+)
+
+// Plan describes what running or rolling back migrations would do, without
+// doing it.
+type Plan struct {
+	// Direction is PlanUp or PlanDown.
+	Direction string
+	// Target is the migration the plan stops at, or empty.
+	Target string
+	// Migrations lists the planned migrations in the order they would run.
+	Migrations []PlannedMigration
+}
+
+// PlannedMigration is one migration of a Plan.
+type PlannedMigration struct {
+	Name       string
+	Connection string
+	// Batch is the batch the migration would be recorded in when planning
+	// up, or the batch it is recorded in when planning down.
+	Batch int
+	// Transactional reports whether the migration would run in a
+	// transaction.
+	Transactional bool
+	// Baseline is true for the schema dump that would be loaded before
+	// the first migration of an empty database; Name is then its path.
+	Baseline bool
+	// Phases lists the phases of a phased migration that would run.
+	Phases     []Phase
+	Statements []string
+	// Destructive is true when a statement drops a table or column or
+	// deletes rows.
+	Destructive bool
+	// Warnings flags risks such as destructive statements.
+	Warnings []string
+}
+
+// Warnings returns the warnings of every planned migration, each prefixed
+// with the migration's name.
+func (p *Plan) Warnings() []string {
+	var warnings []string
+	for _, pm := range p.Migrations {
+		for _, w := range pm.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", pm.Name, w))
+		}
+	}
+	return warnings
+}
+
+// Plan returns what Up (PlanUp) or Rollback (PlanDown) would do. With a
+// target, PlanUp stops after the target migration and PlanDown rolls back
+// every migration applied after it, like RollbackTo; without one, PlanDown
+// covers the last batch.
+//
+// Nothing is written, not even the tracking table. Migrations are compiled
+// inside a read-only transaction, so reads such as HasTable are answered
+// by the database as it is now, not as earlier planned migrations would
+// leave it.
+func (m *Migrator) Plan(direction, target string) (*Plan, error) {
+	return m.PlanContext(context.Background(), direction, target)
+}
+
+// PlanContext is like Plan but executes with ctx.
+func (m *Migrator) PlanContext(ctx context.Context, direction, target string) (*Plan, error) {
+	if direction != PlanUp && direction != PlanDown {
+		return nil, fmt.Errorf("plan direction %q: must be %q or %q", direction, PlanUp, PlanDown)
+	}
+	if err := m.requireGrammar("plan"); err != nil {
+		return nil, err
+	}
+	if target != "" {
+		if _, err := m.registry.Get(target); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{Direction: direction, Target: target}
+	err := m.eachConnection(func(c *Migrator) error {
+		planned, err := c.plan(ctx, direction, target)
+		plan.Migrations = append(plan.Migrations, planned...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// plan implements Plan for a single connection.
+func (m *Migrator) plan(ctx context.Context, direction, target string) ([]PlannedMigration, error) {
+	applied, err := m.appliedForPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("plan: begin read-only transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if direction == PlanDown {
+		return m.planDown(ctx, tx, applied, target)
+	}
+	return m.planUp(ctx, tx, applied, target)
+}
+
+// appliedForPlan returns the applied migrations without creating the
+// tracking table; a database without one has none applied.
+func (m *Migrator) appliedForPlan(ctx context.Context) ([]MigrationRecord, error) {
+	exists, err := schema.NewBuilderContext(ctx, m.db, m.grammar).HasTable(m.tableName)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	return m.tracker.GetAppliedContext(ctx)
+}
+
+// planUp plans the pending migrations up to and including target.
+func (m *Migrator) planUp(ctx context.Context, tx *sql.Tx, applied []MigrationRecord, target string) ([]PlannedMigration, error) {
+	batch := 1
+	appliedMap := make(map[string]MigrationRecord, len(applied))
+	for _, rec := range applied {
+		appliedMap[rec.Name] = rec
+		if rec.Batch >= batch {
+			batch = rec.Batch + 1
+		}
+	}
+
+	var planned []PlannedMigration
+
+	// A database without recorded migrations starts from the baseline.
+	if len(applied) == 0 {
+		dump, err := m.readSchemaDump()
+		if err != nil {
+			return nil, err
+		}
+		if dump != nil {
+			baseline := PlannedMigration{
+				Name:          m.schemaDumpPath,
+				Connection:    m.connName,
+				Transactional: true,
+				Baseline:      true,
+				Statements:    dump.Statements,
+			}
+			if len(dump.Migrations) > 0 {
+				baseline.Batch = batch
+				batch++
+			}
+			baseline.Warnings, baseline.Destructive = planWarnings(baseline)
+			planned = append(planned, baseline)
+			for _, name := range dump.Migrations {
+				applied = append(applied, MigrationRecord{Name: name})
+			}
+		}
+	}
+
+	var registered []registeredMigration
+	for _, reg := range m.registry.GetAll() {
+		if target != "" && reg.Name > target {
+			break
+		}
+		registered = append(registered, reg)
+	}
+
+	pending, reached := m.pendingMigrations(registered, applied)
+	for _, p := range pending {
+		step := PlannedMigration{
+			Name:          p.Name,
+			Connection:    m.connName,
+			Batch:         batch,
+			Transactional: isTransactional(p.Migration),
+		}
+
+		if pm, ok := p.Migration.(*phasedMigration); ok {
+			from, continued := reached[p.Name]
+			if continued {
+				step.Batch = appliedMap[p.Name].Batch
+			}
+			for _, phase := range phaseOrder[from.index()+1:] {
+				if phase.index() > m.phase.index() {
+					break
+				}
+				statements, err := m.runner.compileWith(ctx, tx, pm, string(phase))
+				if err != nil {
+					return nil, fmt.Errorf("plan migration %q %s: %w", p.Name, phase, err)
+				}
+				step.Phases = append(step.Phases, phase)
+				step.Statements = append(step.Statements, statements...)
+				if phase == PhaseBackfill {
+					step.Transactional = false
+				}
+			}
+		} else {
+			statements, err := m.runner.compileWith(ctx, tx, p.Migration, "up")
+			if err != nil {
+				return nil, fmt.Errorf("plan migration %q up: %w", p.Name, err)
+			}
+			step.Statements = statements
+		}
+
+		step.Warnings, step.Destructive = planWarnings(step)
+		planned = append(planned, step)
+	}
+	return planned, nil
+}
+
+// planDown plans rolling back the last batch, or every migration applied
+// after target.
+func (m *Migrator) planDown(ctx context.Context, tx *sql.Tx, applied []MigrationRecord, target string) ([]PlannedMigration, error) {
+	last := 0
+	for _, rec := range applied {
+		if rec.Batch > last {
+			last = rec.Batch
+		}
+	}
+
+	var records []MigrationRecord
+	for _, rec := range applied {
+		if (target == "" && rec.Batch == last) || (target != "" && rec.Name > target) {
+			records = append(records, rec)
+		}
+	}
+	reverseRecords(records)
+
+	planned := make([]PlannedMigration, 0, len(records))
+	for _, rec := range records {
+		migration, err := m.registry.Get(rec.Name)
+		if err != nil {
+			return nil, err
+		}
+		statements, err := m.runner.compileWith(ctx, tx, migration, "down")
+		if err != nil {
+			return nil, fmt.Errorf("plan migration %q down: %w", rec.Name, err)
+		}
+		step := PlannedMigration{
+			Name:          rec.Name,
+			Connection:    m.connName,
+			Batch:         rec.Batch,
+			Transactional: isTransactional(migration),
+			Statements:    statements,
+		}
+		step.Warnings, step.Destructive = planWarnings(step)
+		planned = append(planned, step)
+	}
+	return planned, nil
+}
+
+// isTransactional reports whether the Runner runs migration in a
+// transaction.
+func isTransactional(migration Migration) bool {
+	opt, ok := migration.(TransactionOption)
+	return !ok || !opt.DisableTransaction()
+}
+
+var (
+	// dropRe matches statements that drop a table or a larger object,
+	// capturing its name.
+	dropRe = regexp.MustCompile(`(?i)^\s*DROP\s+(?:TABLE|VIEW|SCHEMA|DATABASE)\s+(?:IF\s+EXISTS\s+)?([^\s;]+)`)
+	// destructiveRe matches the other statements that discard data.
+	destructiveRe = regexp.MustCompile(`(?i)^\s*TRUNCATE\b|\bDROP\s+COLUMN\b|^\s*DELETE\s+FROM\s+[^\s;]+\s*$`)
+)
+
+// planWarnings returns the warnings of a planned migration and whether any
+// of its statements is destructive.
+func planWarnings(step PlannedMigration) ([]string, bool) {
+	var warnings []string
+	found := false
+	for _, stmt := range step.Statements {
+		destructive := destructiveRe.MatchString(stmt)
+		if match := dropRe.FindStringSubmatch(stmt); match != nil {
+			// SQLite table rebuilds drop the table after copying it.
+			table := strings.Trim(match[1], "\"`[]")
+			destructive = !rebuilds(step.Statements, table)
+		}
+		if destructive {
+			found = true
+			warnings = append(warnings, "destructive statement: "+stmt)
+		}
+	}
+	if !step.Transactional {
+		warnings = append(warnings, "runs outside a transaction: a failure can leave it partly applied")
+	}
+	return warnings, found
+}
+
+// rebuilds reports whether statements copy table into a rebuilt table.
+func rebuilds(statements []string, table string) bool {
+	for _, stmt := range statements {
+		if strings.Contains(stmt, "__rebuild_"+table) {
+			return true
+		}
+	}
+	return false
+}
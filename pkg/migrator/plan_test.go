@@ -0,0 +1,129 @@
+package migrator
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// profilesMigration creates "profiles" when "users" exists and "users"
+// otherwise.
+type profilesMigration struct{}
+
+func (m *profilesMigration) Up(b *schema.Builder) error {
+	exists, err := b.HasTable("users")
+	if err != nil {
+		return err
+	}
+	table := "users"
+	if exists {
+		table = "profiles"
+	}
+	return b.Create(table, func(bp *schema.Blueprint) {
+		bp.ID()
+	})
+}
+
+func (m *profilesMigration) Down(b *schema.Builder) error {
+	return b.DropIfExists("profiles")
+}
+
+func TestPlan_Up(t *testing.T) {
+	db := openSQLite(t)
+	m := newPhasedMigrator(t, db, &fullNameMigration{}, PhaseExpand)
+
+	plan, err := m.Plan(PlanUp, "")
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 2)
+	assert.Equal(t, "20240101000000_create_people", plan.Migrations[0].Name)
+	assert.Equal(t, 1, plan.Migrations[0].Batch)
+	assert.True(t, plan.Migrations[0].Transactional)
+	require.Len(t, plan.Migrations[0].Statements, 1)
+	assert.Contains(t, plan.Migrations[0].Statements[0], `CREATE TABLE "people"`)
+	assert.Equal(t, []Phase{PhaseExpand}, plan.Migrations[1].Phases)
+	assert.Empty(t, plan.Warnings())
+	assert.False(t, plan.Migrations[0].Destructive)
+
+	assert.False(t, hasSQLiteTable(t, db, "migrations"), "planning must not create the tracking table")
+	assert.False(t, hasSQLiteTable(t, db, "people"))
+
+	require.NoError(t, m.Up())
+
+	contract := newPhasedMigrator(t, db, &fullNameMigration{}, PhaseContract)
+	plan, err = contract.Plan(PlanUp, "")
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 1)
+	step := plan.Migrations[0]
+	assert.Equal(t, "20240102000000_full_name", step.Name)
+	assert.Equal(t, []Phase{PhaseBackfill, PhaseContract}, step.Phases)
+	assert.Equal(t, 1, step.Batch, "a continued phased migration keeps its batch")
+	assert.False(t, step.Transactional)
+	assert.Contains(t, step.Warnings, "runs outside a transaction: a failure can leave it partly applied")
+}
+
+func TestPlan_Down(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_profiles", &profilesMigration{}))
+	require.NoError(t, m.Up())
+
+	plan, err := m.Plan(PlanDown, "")
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 2)
+	assert.Equal(t, "20240102000000_create_profiles", plan.Migrations[0].Name)
+	assert.Equal(t, "20240101000000_create_users", plan.Migrations[1].Name)
+	assert.Equal(t, 1, plan.Migrations[1].Batch)
+	assert.True(t, plan.Migrations[1].Destructive)
+	assert.Equal(t, []string{
+		`20240102000000_create_profiles: destructive statement: DROP TABLE IF EXISTS "profiles"`,
+		`20240101000000_create_users: destructive statement: DROP TABLE "users"`,
+	}, plan.Warnings())
+
+	plan, err = m.Plan(PlanDown, "20240101000000_create_users")
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 1)
+	assert.Equal(t, "20240102000000_create_profiles", plan.Migrations[0].Name)
+
+	assert.True(t, hasSQLiteTable(t, db, "users"))
+	assert.True(t, hasSQLiteTable(t, db, "profiles"))
+}
+
+func TestPlan_ReadsFromDatabase(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240102000000_create_profiles", &profilesMigration{}))
+
+	plan, err := m.Plan(PlanUp, "")
+	require.NoError(t, err)
+	require.Len(t, plan.Migrations, 1)
+	require.Len(t, plan.Migrations[0].Statements, 1)
+	assert.Contains(t, plan.Migrations[0].Statements[0], `CREATE TABLE "profiles"`)
+
+	// Dry runs answer HasTable from the database as well.
+	var out bytes.Buffer
+	dry := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithDryRun(&out))
+	require.NoError(t, dry.Register("20240102000000_create_profiles", &profilesMigration{}))
+	require.NoError(t, dry.Up())
+	assert.True(t, strings.Contains(out.String(), `CREATE TABLE "profiles"`), out.String())
+}
+
+func TestPlan_InvalidArguments(t *testing.T) {
+	m := New(openSQLite(t), WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	_, err := m.Plan("sideways", "")
+	assert.Error(t, err)
+
+	_, err = m.Plan(PlanUp, "20990101000000_missing")
+	assert.True(t, errors.Is(err, ErrMigrationNotFound))
+}
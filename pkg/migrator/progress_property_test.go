@@ -43,8 +43,7 @@ func TestPropertyProgress_UpEventsHaveCorrectIndicesAndTotals(t *testing.T) {
 		expectGetApplied(mock, nil)
 		expectMaxBatch(mock, 0)
 		for _, name := range names {
-			expectMigrationTx(mock)
-			expectRecord(mock, name, 1)
+			expectRecordTx(mock, name, 1)
 		}
 
 		err = m.Up()
@@ -97,8 +96,7 @@ func TestPropertyProgress_ResetEventsHaveCorrectIndicesAndTotals(t *testing.T) {
 
 		// Reset reverses applied, so Down runs in reverse order.
 		for i := count - 1; i >= 0; i-- {
-			expectMigrationTx(mock)
-			expectRemove(mock, names[i])
+			expectRemoveTx(mock, names[i])
 		}
 
 		err = m.Reset()
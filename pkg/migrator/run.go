@@ -1,8 +1,14 @@
 package migrator
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/andrianprasetya/go-migration/internal/generator"
 	"github.com/andrianprasetya/go-migration/internal/logger"
@@ -11,13 +17,14 @@ import (
 	"github.com/andrianprasetya/go-migration/pkg/config"
 	"github.com/andrianprasetya/go-migration/pkg/database"
 	"github.com/andrianprasetya/go-migration/pkg/database/drivers"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
 	"github.com/andrianprasetya/go-migration/pkg/seeder"
 	"github.com/spf13/cobra"
 )
 
 // commandsNeedingDB lists commands that require a database connection.
-// Commands not in this set (version, help, make:migration, make:seeder,
-// make:factory) skip the database setup entirely.
+// Commands not in this set (version, help, make:migration without --auto,
+// make:seeder, make:factory) skip the database setup entirely.
 var commandsNeedingDB = map[string]bool{
 	"migrate":          true,
 	"migrate:rollback": true,
@@ -26,9 +33,15 @@ var commandsNeedingDB = map[string]bool{
 	"migrate:fresh":    true,
 	"migrate:status":   true,
 	"migrate:install":  true,
+	"migrate:to":       true,
+	"migrate:plan":     true,
+	"migrate:lint":     true,
+	"migrate:unlock":   true,
+	"schema:dump":      true,
 	"db:seed":          true,
 	"db:seed:rollback": true,
 	"db:seed:truncate": true,
+	"db:seed:status":   true,
 }
 
 // migratorAdapter wraps *Migrator to satisfy commands.MigratorRunner.
@@ -37,26 +50,145 @@ var commandsNeedingDB = map[string]bool{
 // from commands to migrator.
 type migratorAdapter struct {
 	m *Migrator
+	// lintDisabled lists the lint rules disabled in the configuration.
+	lintDisabled []string
 }
 
-func (a *migratorAdapter) Up() error                { return a.m.Up() }
-func (a *migratorAdapter) Rollback(steps int) error { return a.m.Rollback(steps) }
-func (a *migratorAdapter) Reset() error             { return a.m.Reset() }
-func (a *migratorAdapter) Refresh() error           { return a.m.Refresh() }
-func (a *migratorAdapter) Fresh() error             { return a.m.Fresh() }
+func (a *migratorAdapter) Up(ctx context.Context) error {
+	return a.m.UpContext(ctx)
+}
+
+func (a *migratorAdapter) Rollback(ctx context.Context, steps int) error {
+	return a.m.RollbackContext(ctx, steps)
+}
+
+func (a *migratorAdapter) Reset(ctx context.Context) error {
+	return a.m.ResetContext(ctx)
+}
+
+func (a *migratorAdapter) Refresh(ctx context.Context) error {
+	return a.m.RefreshContext(ctx)
+}
+
+func (a *migratorAdapter) Fresh(ctx context.Context) error {
+	return a.m.FreshContext(ctx)
+}
 
-func (a *migratorAdapter) Status() ([]commands.MigrationStatusInfo, error) {
-	statuses, err := a.m.Status()
+func (a *migratorAdapter) MigrateTo(ctx context.Context, name string) error {
+	return a.m.MigrateToContext(ctx, name)
+}
+
+func (a *migratorAdapter) RollbackTo(ctx context.Context, name string) error {
+	return a.m.RollbackToContext(ctx, name)
+}
+
+func (a *migratorAdapter) DumpSchema(ctx context.Context, path string) ([]string, error) {
+	return a.m.DumpSchemaContext(ctx, path)
+}
+
+func (a *migratorAdapter) Status(ctx context.Context) ([]commands.MigrationStatusInfo, error) {
+	statuses, err := a.m.StatusContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	result := make([]commands.MigrationStatusInfo, len(statuses))
 	for i, s := range statuses {
 		result[i] = commands.MigrationStatusInfo{
-			Name:      s.Name,
-			Applied:   s.Applied,
-			Batch:     s.Batch,
-			AppliedAt: s.AppliedAt,
+			Name:       s.Name,
+			Applied:    s.Applied,
+			Batch:      s.Batch,
+			AppliedAt:  s.AppliedAt,
+			Modified:   s.Modified,
+			Phase:      string(s.Phase),
+			Partial:    s.Partial(),
+			Connection: s.Connection,
+		}
+		if s.ChecksumErr != nil {
+			result[i].ChecksumError = s.ChecksumErr.Error()
+		}
+	}
+	return result, nil
+}
+
+func (a *migratorAdapter) UpTenants(ctx context.Context) ([]commands.TenantReportInfo, error) {
+	reports, err := a.m.UpTenantsContext(ctx)
+	result := make([]commands.TenantReportInfo, len(reports))
+	for i, r := range reports {
+		result[i] = commands.TenantReportInfo{
+			Tenant:   r.Tenant,
+			Applied:  r.Applied,
+			Duration: r.Duration,
+			Err:      r.Err,
+		}
+	}
+	return result, err
+}
+
+func (a *migratorAdapter) StatusTenants(ctx context.Context) ([]commands.TenantStatusInfo, error) {
+	statuses, err := a.m.StatusTenantsContext(ctx)
+	result := make([]commands.TenantStatusInfo, len(statuses))
+	for i, s := range statuses {
+		result[i] = commands.TenantStatusInfo{
+			Tenant:  s.Tenant,
+			Applied: s.Applied(),
+			Pending: s.Pending(),
+			Err:     s.Err,
+		}
+	}
+	return result, err
+}
+
+func (a *migratorAdapter) Plan(ctx context.Context, direction, target string) (*commands.PlanInfo, error) {
+	plan, err := a.m.PlanContext(ctx, direction, target)
+	if err != nil {
+		return nil, err
+	}
+	result := &commands.PlanInfo{
+		Direction:  plan.Direction,
+		Target:     plan.Target,
+		Migrations: make([]commands.PlannedMigrationInfo, len(plan.Migrations)),
+	}
+	for i, pm := range plan.Migrations {
+		phases := make([]string, len(pm.Phases))
+		for j, phase := range pm.Phases {
+			phases[j] = string(phase)
+		}
+		result.Migrations[i] = commands.PlannedMigrationInfo{
+			Name:          pm.Name,
+			Connection:    pm.Connection,
+			Batch:         pm.Batch,
+			Transactional: pm.Transactional,
+			Baseline:      pm.Baseline,
+			Phases:        phases,
+			Statements:    append([]string{}, pm.Statements...),
+			Destructive:   pm.Destructive,
+			Warnings:      pm.Warnings,
+		}
+	}
+	return result, nil
+}
+
+func (a *migratorAdapter) Unlock(ctx context.Context) (bool, error) {
+	return a.m.ForceUnlockContext(ctx)
+}
+
+func (a *migratorAdapter) Lint(ctx context.Context, disabled []string) ([]commands.LintViolationInfo, error) {
+	linter, err := NewLinter(a.m, append(append([]string{}, a.lintDisabled...), disabled...)...)
+	if err != nil {
+		return nil, err
+	}
+	violations, err := linter.LintContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]commands.LintViolationInfo, len(violations))
+	for i, v := range violations {
+		result[i] = commands.LintViolationInfo{
+			Rule:       v.Rule,
+			Migration:  v.Migration,
+			Connection: v.Connection,
+			Statement:  v.Statement,
+			Message:    v.Message,
 		}
 	}
 	return result, nil
@@ -65,7 +197,8 @@ func (a *migratorAdapter) Status() ([]commands.MigrationStatusInfo, error) {
 // Run is the all-in-one entry point for the go-migration CLI.
 // It handles the full lifecycle: parse CLI args, load config, connect DB,
 // auto-discover migrations and seeders, and dispatch the command.
-// On fatal error it prints to stderr and exits with code 1.
+// On fatal error it prints to stderr and exits with the ExitCode of the
+// error's failure class.
 //
 // Usage in main.go:
 //
@@ -83,7 +216,7 @@ func (a *migratorAdapter) Status() ([]commands.MigrationStatusInfo, error) {
 func Run() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
 
@@ -118,17 +251,26 @@ func run() error {
 		commands.NewMigrateFreshCommand(getCtx),
 		commands.NewMigrateStatusCommand(getCtx),
 		commands.NewMigrateInstallCommand(getCtx),
+		commands.NewMigrateToCommand(getCtx),
+		commands.NewMigratePlanCommand(getCtx),
+		commands.NewMigrateLintCommand(getCtx),
+		commands.NewMigrateUnlockCommand(getCtx),
+		commands.NewSchemaDumpCommand(getCtx),
 		commands.NewMakeMigrationCommand(getCtx),
 		commands.NewMakeSeederCommand(getCtx),
 		commands.NewMakeFactoryCommand(getCtx),
 		commands.NewSeedCommand(getCtx),
 		commands.NewSeedRollbackCommand(getCtx),
 		commands.NewSeedTruncateCommand(getCtx),
+		commands.NewSeedStatusCommand(getCtx),
 	)
 
 	// --- PersistentPreRunE: config loading, DB connection, auto-discover (task 6.3 will expand) ---
-	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if !commandsNeedingDB[cmd.Name()] {
+	setup := func(cmd *cobra.Command) error {
+		// make:migration --auto inspects the database like the migrate commands.
+		auto, _ := cmd.Flags().GetBool("auto")
+		autoDiff := cmd.Name() == "make:migration" && auto
+		if !commandsNeedingDB[cmd.Name()] && !autoDiff {
 			// For make:* commands we still need the generator.
 			if cmd.Name() == "make:migration" || cmd.Name() == "make:seeder" || cmd.Name() == "make:factory" {
 				cfg, err := loadConfigLightweight(cmd)
@@ -153,14 +295,23 @@ func run() error {
 			return err
 		}
 
+		// With --output=json|yaml stdout carries the result document, so
+		// the log and the dry-run SQL go to stderr.
+		output, _ := cmd.Flags().GetString("output")
+		structured := output == commands.OutputJSON || output == commands.OutputYAML
+		logWriter := io.Writer(os.Stdout)
+		if structured {
+			logWriter = os.Stderr
+		}
+
 		// Set up logger.
-		log := setupLogger(cfg)
+		log := setupLogger(cfg, logWriter)
 
 		// Set up database connection manager with all drivers.
 		connManager = database.NewManager()
-		connManager.RegisterDriver("postgres", drivers.NewPostgresDriver())
-		connManager.RegisterDriver("mysql", drivers.NewMySQLDriver())
-		connManager.RegisterDriver("sqlite3", drivers.NewSQLiteDriver())
+		for name, driver := range cliDrivers {
+			connManager.RegisterDriver(name, driver)
+		}
 
 		// Add all configured connections.
 		for name, connCfg := range cfg.Connections {
@@ -183,15 +334,117 @@ func run() error {
 			return fmt.Errorf("get default connection: %w", err)
 		}
 
+		// The grammar, lock, tracking table SQL and seeder dialect must all
+		// match the database the CLI talks to. A connection's grammar setting
+		// overrides its driver for wire-compatible databases.
+		defaultName := connManager.DefaultName()
+		dialect, err := connectionDialect(cfg, connManager, defaultName)
+		if err != nil {
+			return fmt.Errorf("get default connection: %w", err)
+		}
+
+		grammar, err := ResolveGrammar(dialect)
+		if err != nil {
+			return fmt.Errorf("resolve grammar: %w", err)
+		}
+		// Serialize concurrent CLI runs against the same database.
+		locker, err := ResolveLocker(dialect, db, cfg.MigrationTable)
+		if err != nil {
+			return fmt.Errorf("resolve migration lock: %w", err)
+		}
+		trackerDialect, err := ResolveTrackerDialect(dialect)
+		if err != nil {
+			return fmt.Errorf("resolve tracker dialect: %w", err)
+		}
+		schemaDumper, err := ResolveSchemaDumper(dialect)
+		if err != nil {
+			return fmt.Errorf("resolve schema dumper: %w", err)
+		}
+		seederDialect, err := seeder.ResolveDialect(dialect)
+		if err != nil {
+			return fmt.Errorf("resolve seeder dialect: %w", err)
+		}
+
 		// Build Migrator options.
 		opts := []Option{
 			WithTableName(cfg.MigrationTable),
+			WithGrammar(grammar),
+			WithDefaultConnection(defaultName),
+			WithTrackerDialect(trackerDialect),
 			WithLogger(log),
+			WithLocker(locker),
+			WithLockTimeout(cfg.LockTimeout),
+			WithSchemaDump(cfg.SchemaDump),
+			WithSchemaDumper(schemaDumper),
+		}
+
+		// Migrations implementing ConnectionOption are routed to the other
+		// configured connections, each with its own grammar and tracker.
+		for name := range cfg.Connections {
+			if name == defaultName {
+				continue
+			}
+			connDB, err := connManager.Connection(name)
+			if err != nil {
+				return err
+			}
+			connDialect, err := connectionDialect(cfg, connManager, name)
+			if err != nil {
+				return err
+			}
+			connGrammar, err := ResolveGrammar(connDialect)
+			if err != nil {
+				return fmt.Errorf("connection %q: resolve grammar: %w", name, err)
+			}
+			opts = append(opts, WithConnection(name, connDB, connGrammar))
+		}
+
+		// --database restricts migrate:* commands to one connection.
+		database, _ := cmd.Flags().GetString("database")
+		if database != "" {
+			opts = append(opts, WithOnlyConnection(database))
+		}
+
+		// --tenants fans migrate and migrate:status out to the configured
+		// tenants, each reached through a connection of its own.
+		if tenants, _ := cmd.Flags().GetBool("tenants"); tenants {
+			tenantOpts, err := tenantOptions(cfg, connManager, db, defaultName, dialect)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, tenantOpts...)
 		}
 
 		// Enable dry-run mode if the command has --dry-run flag set.
 		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
-			opts = append(opts, WithDryRun(os.Stdout))
+			opts = append(opts, WithDryRun(logWriter))
+		}
+
+		// Record each applied or rolled back migration for --output.
+		events := &commands.EventRecorder{}
+		opts = append(opts, WithProgress(func(e ProgressEvent) {
+			events.Record(e.Name, e.Direction, e.Duration)
+		}))
+
+		// Run phased migrations up to the phase given with --phase.
+		if name, _ := cmd.Flags().GetString("phase"); name != "" {
+			phase, err := ParsePhase(name)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, WithPhase(phase))
+		}
+
+		// migrate:fresh drops the object kinds selected with --drop-*.
+		if cmd.Name() == "migrate:fresh" {
+			views, _ := cmd.Flags().GetBool("drop-views")
+			triggers, _ := cmd.Flags().GetBool("drop-triggers")
+			sequences, _ := cmd.Flags().GetBool("drop-sequences")
+			opts = append(opts, WithDropAll(schema.DropAllOptions{
+				Views:     views,
+				Triggers:  triggers,
+				Sequences: sequences,
+			}))
 		}
 
 		// Create Migrator with auto-discover.
@@ -205,6 +458,14 @@ func run() error {
 			}
 		}
 
+		// SQL migration files in the migration directory run alongside
+		// the Go migrations.
+		if info, err := os.Stat(cfg.MigrationDir); err == nil && info.IsDir() {
+			if err := m.AddSource(NewDirSource(cfg.MigrationDir)); err != nil {
+				return fmt.Errorf("register sql migrations: %w", err)
+			}
+		}
+
 		// Auto-discover seeders from global auto-registry.
 		seederRegistry := seeder.NewRegistry()
 		autoSeeders := seeder.GetAutoRegistered()
@@ -213,24 +474,78 @@ func run() error {
 				return fmt.Errorf("register seeder %q: %w", name, err)
 			}
 		}
-		seederRunner := seeder.NewRunner(seederRegistry, db, log)
+		// With seeder tracking configured, db:seed records the seeders it
+		// runs and skips those that already ran, unless --force is given.
+		// --transaction wraps them in transactions.
+		seederOpts := []seeder.RunnerOption{seeder.WithDialect(seederDialect)}
+		if cfg.SeederTracking {
+			seederOpts = append(seederOpts, seeder.WithTracker(seeder.NewTrackerWithDialect(db, cfg.SeederTable, seederDialect)))
+		}
+		if cmd.Name() == "db:seed" {
+			force, _ := cmd.Flags().GetBool("force")
+			transaction, _ := cmd.Flags().GetString("transaction")
+			txMode, err := seeder.ParseTransactionMode(transaction)
+			if err != nil {
+				return fmt.Errorf("invalid --transaction flag: %w", err)
+			}
+			seederOpts = append(seederOpts, seeder.WithForce(force), seeder.WithTransactions(txMode))
+		}
+		seederRunner := seeder.NewRunner(seederRegistry, db, log, seederOpts...)
 
 		// Create Generator.
 		gen := generator.NewGenerator(cfg.MigrationDir)
 
 		// Create Tracker for migrate:install command.
-		tracker := NewTracker(db, cfg.MigrationTable)
+		tracker := NewTrackerWithDialect(db, cfg.MigrationTable, trackerDialect)
+		if database != "" && database != defaultName {
+			connDB, err := connManager.Connection(database)
+			if err != nil {
+				return err
+			}
+			connDialect, err := connectionDialect(cfg, connManager, database)
+			if err != nil {
+				return err
+			}
+			connTrackerDialect, err := ResolveTrackerDialect(connDialect)
+			if err != nil {
+				return fmt.Errorf("resolve tracker dialect: %w", err)
+			}
+			tracker = NewTrackerWithDialect(connDB, cfg.MigrationTable, connTrackerDialect)
+		}
 
 		cmdCtx = &commands.CommandContext{
 			DB:             db,
-			Migrator:       &migratorAdapter{m: m},
+			Migrator:       &migratorAdapter{m: m, lintDisabled: cfg.Lint.Disable},
 			Seeder:         seederRunner,
 			Generator:      gen,
 			TrackerEnsurer: tracker,
+			MigrationDir:   cfg.MigrationDir,
+			SchemaDumpPath: cfg.SchemaDump,
+			Events:         events,
+			Production:     cfg.IsProduction(),
+			DescribeError:  describeError,
+		}
+
+		if autoDiff {
+			cmdCtx.DiffSchema = func(ctx context.Context) (*schema.SchemaDiff, error) {
+				inspector, err := schema.NewInspectorContext(ctx, db, grammar)
+				if err != nil {
+					return nil, err
+				}
+				return inspector.Diff(schema.DeclaredTables())
+			}
 		}
 
 		return nil
 	}
+	// Setup errors are reported like the command's own, so that
+	// --output=json|yaml still prints a result document.
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := setup(cmd); err != nil {
+			return commands.ReportError(cmd, &commands.CommandContext{DescribeError: describeError}, err)
+		}
+		return nil
+	}
 
 	// --- --seed flag integration ---
 	// Add --seed flag to migrate, migrate:fresh, and migrate:refresh commands.
@@ -254,7 +569,7 @@ func run() error {
 				if ctx == nil || ctx.Seeder == nil {
 					return fmt.Errorf("seeder not initialized")
 				}
-				if err := ctx.Seeder.RunAll(); err != nil {
+				if err := ctx.Seeder.RunAllContext(cmd.Context()); err != nil {
 					return fmt.Errorf("seeder: %w", err)
 				}
 			}
@@ -262,8 +577,11 @@ func run() error {
 		}
 	}
 
-	// Run the CLI.
-	err := app.Run(nil)
+	// Run the CLI. SIGINT/SIGTERM cancel the context so that the running
+	// migration's transaction is rolled back instead of being cut off.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err := app.RunContext(sigCtx, nil)
 
 	// Cleanup: close all database connections (task 6.5 will refine).
 	if connManager != nil {
@@ -273,6 +591,33 @@ func run() error {
 	return err
 }
 
+// describeError converts err into the ErrorInfo printed with
+// --output=json|yaml, with the details of a failed migration or seeder.
+func describeError(err error) commands.ErrorInfo {
+	info := commands.ErrorInfo{
+		Class:    ErrorClass(err),
+		ExitCode: ExitCode(err),
+		Message:  err.Error(),
+	}
+	var migrationErr *MigrationError
+	var seederErr *seeder.SeederError
+	switch {
+	case errors.As(err, &migrationErr):
+		info.Migration = migrationErr.MigrationName
+		info.SQL = migrationErr.SQL
+		info.Position = migrationErr.Position
+		if migrationErr.Cause != nil {
+			info.Cause = migrationErr.Cause.Error()
+		}
+	case errors.As(err, &seederErr):
+		info.Seeder = seederErr.Seeder
+		if seederErr.Cause != nil {
+			info.Cause = seederErr.Cause.Error()
+		}
+	}
+	return info
+}
+
 // loadConfig reads the --config flag and loads configuration.
 // It tries the file first, then falls back to environment variables.
 func loadConfig(cmd *cobra.Command) (*config.Config, error) {
@@ -314,8 +659,9 @@ func loadConfigLightweight(cmd *cobra.Command) (*config.Config, error) {
 	return cfg, nil
 }
 
-// setupLogger creates a logger based on the configuration.
-func setupLogger(cfg *config.Config) logger.Logger {
+// setupLogger creates a logger based on the configuration. The console
+// logger writes to w.
+func setupLogger(cfg *config.Config, w io.Writer) logger.Logger {
 	level := logger.ParseLevel(cfg.LogLevel)
 
 	if cfg.LogOutput != "" && cfg.LogOutput != "console" {
@@ -326,7 +672,62 @@ func setupLogger(cfg *config.Config) logger.Logger {
 		// Fall back to console if file logger fails.
 	}
 
-	return logger.NewConsoleLogger(level)
+	return logger.NewConsoleLoggerTo(level, w)
+}
+
+// cliDrivers are the database drivers the CLI registers, by name.
+var cliDrivers = map[string]database.Driver{
+	"postgres": drivers.NewPostgresDriver(),
+	"mysql":    drivers.NewMySQLDriver(),
+	"sqlite":   drivers.NewSQLiteDriver(),
+	"sqlite3":  drivers.NewSQLiteDriver(),
+}
+
+// tenantOptions returns the Migrator options for the tenants configured in
+// cfg. Tenants are reached by opening the default connection's driver
+// with the tenant as search_path (mode "schema", the PostgreSQL default)
+// or as database name (mode "database").
+func tenantOptions(cfg *config.Config, connManager *database.Manager, db *sql.DB, defaultName, dialect string) ([]Option, error) {
+	var source TenantSource
+	switch {
+	case len(cfg.Tenants.List) > 0:
+		source = StaticTenants(cfg.Tenants.List...)
+	case cfg.Tenants.Query != "":
+		source = QueryTenants(db, cfg.Tenants.Query)
+	default:
+		return nil, fmt.Errorf("--tenants requires tenants.list or tenants.query in the configuration")
+	}
+
+	base, err := connManager.Config(defaultName)
+	if err != nil {
+		return nil, err
+	}
+	mode := TenantMode(cfg.Tenants.Mode)
+	if mode == "" {
+		mode = TenantDatabase
+		if dialect == "postgres" {
+			mode = TenantSchema
+		}
+	}
+
+	return []Option{
+		WithTenants(source, NewTenantOpener(cliDrivers[base.Driver], base, mode)),
+		WithTenantParallelism(cfg.Tenants.Parallelism),
+	}, nil
+}
+
+// connectionDialect returns the name the grammar, lock and tracker dialect
+// of the named connection are resolved by: its grammar override, or its
+// driver.
+func connectionDialect(cfg *config.Config, connManager *database.Manager, name string) (string, error) {
+	if connCfg, ok := cfg.Connections[name]; ok {
+		return connCfg.GrammarName(), nil
+	}
+	dbCfg, err := connManager.Config(name)
+	if err != nil {
+		return "", err
+	}
+	return dbCfg.Driver, nil
 }
 
 // toDBConnectionConfig converts a config.ConnectionConfig to a database.ConnectionConfig.
@@ -1,10 +1,14 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
 
 	"github.com/andrianprasetya/go-migration/pkg/schema"
 )
@@ -63,31 +67,82 @@ func extractPosition(err error) string {
 // If the migration implements TransactionOption and DisableTransaction() returns true,
 // it executes without a transaction. Otherwise it delegates to ExecuteInTransaction.
 func (r *Runner) Execute(m Migration, direction string, migrationName ...string) error {
+	return r.ExecuteContext(context.Background(), m, direction, migrationName...)
+}
+
+// ExecuteContext is like Execute but runs the migration's statements with ctx.
+// If ctx is cancelled mid-migration, the surrounding transaction is rolled back.
+func (r *Runner) ExecuteContext(ctx context.Context, m Migration, direction string, migrationName ...string) error {
 	name := ""
 	if len(migrationName) > 0 {
 		name = migrationName[0]
 	}
+	_, err := r.execute(ctx, m, direction, name, nil)
+	return err
+}
+
+// finishFunc is called once a migration has run, with the executor it ran
+// on and the checksum of what it executed (see executedChecksum). The
+// Migrator records the migration in it so that the record commits
+// together with the schema change.
+type finishFunc func(ctx context.Context, exec trackerExecutor, checksum string) error
+
+// execute implements ExecuteContext and returns the statements the
+// migration executed. Dry runs return none and do not call finish.
+//
+// A transactional migration calls finish inside its transaction, before
+// the commit, so that a failed or interrupted record rolls the migration
+// back. Otherwise finish runs on the database once the migration has run,
+// with a ctx that is no longer cancelled: the schema change cannot be
+// undone at that point, so it must be recorded.
+func (r *Runner) execute(ctx context.Context, m Migration, direction string, name string, finish finishFunc) ([]string, error) {
 	if r.dryRun {
-		return r.executeDryRun(m, direction)
+		return nil, r.executeDryRun(ctx, m, direction)
 	}
 	if opt, ok := m.(TransactionOption); ok && opt.DisableTransaction() {
-		return r.executeWithoutTransaction(m, direction, name)
+		return r.executeWithoutTransaction(ctx, m, direction, name, finish)
+	}
+	// A backfill commits chunk by chunk rather than holding one long
+	// transaction on the table.
+	if direction == string(PhaseBackfill) {
+		return r.executeWithoutTransaction(ctx, m, direction, name, finish)
 	}
-	return r.ExecuteInTransaction(m, direction, name)
+	return r.executeInTransaction(ctx, m, direction, name, finish)
+}
+
+// compileWith runs a migration against a CaptureExecutor and returns the
+// statements it would execute, without changing the database. The
+// migration's read queries are answered by reader.
+func (r *Runner) compileWith(ctx context.Context, reader schema.Executor, m Migration, direction string) ([]string, error) {
+	executor := &schema.CaptureExecutor{Reader: reader}
+	builder := schema.NewBuilderContext(ctx, executor, r.grammar)
+	if err := r.runMigration(m, builder, direction); err != nil {
+		return nil, err
+	}
+	return executor.Statements, nil
 }
 
 // ExecuteDryRun runs a migration in the given direction ("up" or "down")
 // with a migration name prefix written to the dry-run writer.
 func (r *Runner) ExecuteDryRun(m Migration, direction string, migrationName string) error {
+	return r.ExecuteDryRunContext(context.Background(), m, direction, migrationName)
+}
+
+// ExecuteDryRunContext is like ExecuteDryRun but passes ctx to the migration's Builder.
+func (r *Runner) ExecuteDryRunContext(ctx context.Context, m Migration, direction string, migrationName string) error {
 	fmt.Fprintf(r.dryRunWriter, "-- Migration: %s\n", migrationName)
-	return r.executeDryRun(m, direction)
+	return r.executeDryRun(ctx, m, direction)
 }
 
 // executeDryRun runs a migration using a DryRunExecutor, writing SQL to the
-// configured writer instead of executing against the database.
-func (r *Runner) executeDryRun(m Migration, direction string) error {
+// configured writer instead of executing against the database. Read
+// queries such as HasTable are answered by the database.
+func (r *Runner) executeDryRun(ctx context.Context, m Migration, direction string) error {
 	executor := &schema.DryRunExecutor{Writer: r.dryRunWriter}
-	builder := schema.NewBuilder(executor, r.grammar)
+	if r.db != nil {
+		executor.Reader = r.db
+	}
+	builder := schema.NewBuilderContext(ctx, executor, r.grammar)
 	return r.runMigration(m, builder, direction)
 }
 
@@ -96,66 +151,192 @@ func (r *Runner) executeDryRun(m Migration, direction string) error {
 // is rolled back. If commit fails, a rollback is attempted and ErrTransactionFailed
 // is returned. SQL errors are wrapped in MigrationError when migrationName is provided.
 func (r *Runner) ExecuteInTransaction(m Migration, direction string, migrationName ...string) error {
+	return r.ExecuteInTransactionContext(context.Background(), m, direction, migrationName...)
+}
+
+// ExecuteInTransactionContext is like ExecuteInTransaction but begins the
+// transaction with ctx. Cancelling ctx aborts the running statement and
+// rolls the transaction back.
+func (r *Runner) ExecuteInTransactionContext(ctx context.Context, m Migration, direction string, migrationName ...string) error {
 	name := ""
 	if len(migrationName) > 0 {
 		name = migrationName[0]
 	}
+	_, err := r.executeInTransaction(ctx, m, direction, name, nil)
+	return err
+}
+
+// executeInTransaction implements ExecuteInTransactionContext and returns
+// the statements executed inside the committed transaction. finish, if not
+// nil, runs inside the transaction before it commits.
+func (r *Runner) executeInTransaction(ctx context.Context, m Migration, direction string, name string, finish finishFunc) ([]string, error) {
+	if r.disablesForeignKeys(ctx, m, direction) {
+		return r.executeWithoutForeignKeys(ctx, m, direction, name, finish)
+	}
+	return r.executeTx(ctx, r.db, m, direction, name, finish)
+}
 
-	tx, err := r.db.Begin()
+// txBeginner is implemented by *sql.DB and *sql.Conn.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// executeTx runs a migration in a transaction begun on db.
+func (r *Runner) executeTx(ctx context.Context, db txBeginner, m Migration, direction string, name string, finish finishFunc) ([]string, error) {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
+		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
 
 	recorder := schema.NewRecordingExecutor(tx)
-	builder := schema.NewBuilder(recorder, r.grammar)
+	builder := schema.NewBuilderContext(ctx, recorder, r.grammar)
 
 	if err := r.runMigration(m, builder, direction); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("rollback after migration error: %v, original: %w", rbErr, err)
+		// database/sql already rolls back when ctx is cancelled, in which
+		// case Rollback reports ErrTxDone; that is not a rollback failure.
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return nil, fmt.Errorf("rollback after migration error: %v, original: %w", rbErr, err)
 		}
 		if name != "" {
 			migErr := wrapMigrationError(name, recorder.LastSQL, err)
 			migErr.Position = extractPosition(err)
-			return migErr
+			return nil, migErr
+		}
+		return nil, err
+	}
+	if finish != nil {
+		if err := finish(ctx, tx, executedChecksum(m, recorder)); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				return nil, fmt.Errorf("rollback after record error: %v, original: %w", rbErr, err)
+			}
+			return nil, err
 		}
-		return err
 	}
 
 	if err := tx.Commit(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("commit: %w", ctxErr)
+		}
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("rollback after commit failure: %v: %w", rbErr, ErrTransactionFailed)
+			return nil, fmt.Errorf("rollback after commit failure: %v: %w", rbErr, ErrTransactionFailed)
 		}
-		return fmt.Errorf("commit: %w", ErrTransactionFailed)
+		return nil, fmt.Errorf("commit: %w", ErrTransactionFailed)
 	}
 
-	return nil
+	return recorder.Statements, nil
+}
+
+// foreignKeysOff is the statement a SQLite table rebuild starts with when
+// foreign key enforcement is on.
+const foreignKeysOff = "PRAGMA foreign_keys = OFF"
+
+// disablesForeignKeys reports whether the migration rebuilds a table with
+// foreign key enforcement on, which SQLite cannot switch off inside a
+// transaction: dropping the old table would then run the ON DELETE
+// actions of the tables that reference it. The migration is compiled
+// against the database to find out, for grammars that rebuild tables only.
+func (r *Runner) disablesForeignKeys(ctx context.Context, m Migration, direction string) bool {
+	if _, ok := r.grammar.(schema.TableRebuilder); !ok {
+		return false
+	}
+	// A migration that does not compile fails the same way when it runs.
+	statements, err := r.compileWith(ctx, r.db, m, direction)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(statements, foreignKeysOff)
+}
+
+// executeWithoutForeignKeys runs a migration in a transaction with foreign
+// key enforcement switched off, as the SQLite documentation prescribes for
+// table rebuilds: enforcement is switched off on a dedicated connection
+// before the transaction begins, the foreign keys are checked before it
+// commits, and enforcement is switched back on afterwards.
+func (r *Runner) executeWithoutForeignKeys(ctx context.Context, m Migration, direction string, name string, finish finishFunc) ([]string, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, foreignKeysOff); err != nil {
+		return nil, fmt.Errorf("disable foreign keys: %w", err)
+	}
+	statements, err := r.executeTx(ctx, conn, m, direction, name, func(ctx context.Context, exec trackerExecutor, checksum string) error {
+		if err := checkForeignKeys(ctx, exec); err != nil {
+			return err
+		}
+		if finish != nil {
+			return finish(ctx, exec, checksum)
+		}
+		return nil
+	})
+	if _, onErr := conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA foreign_keys = ON"); onErr != nil && err == nil {
+		// The connection must not return to the pool without enforcement.
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return nil, fmt.Errorf("enable foreign keys: %w", onErr)
+	}
+	return statements, err
+}
+
+// checkForeignKeys fails when a row violates a foreign key.
+func checkForeignKeys(ctx context.Context, exec trackerExecutor) error {
+	rows, err := exec.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("check foreign keys: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var table string
+		var rowID sql.NullInt64
+		var parent string
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return fmt.Errorf("check foreign keys: %w", err)
+		}
+		return fmt.Errorf("check foreign keys: row %d of %q references a missing row of %q", rowID.Int64, table, parent)
+	}
+	return rows.Err()
 }
 
 // executeWithoutTransaction runs a migration directly against the database
 // connection without wrapping it in a transaction. SQL errors are wrapped
-// in MigrationError when migrationName is provided.
-func (r *Runner) executeWithoutTransaction(m Migration, direction string, migrationName string) error {
+// in MigrationError when migrationName is provided. finish, if not nil,
+// runs once the migration succeeded.
+func (r *Runner) executeWithoutTransaction(ctx context.Context, m Migration, direction string, migrationName string, finish finishFunc) ([]string, error) {
 	recorder := schema.NewRecordingExecutor(r.db)
-	builder := schema.NewBuilder(recorder, r.grammar)
+	builder := schema.NewBuilderContext(ctx, recorder, r.grammar)
 
 	if err := r.runMigration(m, builder, direction); err != nil {
 		if migrationName != "" {
 			migErr := wrapMigrationError(migrationName, recorder.LastSQL, err)
 			migErr.Position = extractPosition(err)
-			return migErr
+			return nil, migErr
 		}
-		return err
+		return nil, err
 	}
-	return nil
+	if finish != nil {
+		if err := finish(context.WithoutCancel(ctx), r.db, executedChecksum(m, recorder)); err != nil {
+			return nil, err
+		}
+	}
+	return recorder.Statements, nil
 }
 
 // runMigration calls the appropriate migration method based on direction.
+// The direction may also name a phase of a migration registered with Phased.
 func (r *Runner) runMigration(m Migration, builder *schema.Builder, direction string) error {
 	switch direction {
 	case "up":
 		return m.Up(builder)
 	case "down":
 		return m.Down(builder)
+	case string(PhaseExpand), string(PhaseBackfill), string(PhaseContract):
+		pm, ok := m.(*phasedMigration)
+		if !ok {
+			return fmt.Errorf("migration direction %q: migration is not phased", direction)
+		}
+		return pm.run(Phase(direction), builder)
 	default:
 		return fmt.Errorf("unknown migration direction: %q", direction)
 	}
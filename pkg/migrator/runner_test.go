@@ -2,13 +2,16 @@ package migrator
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +36,9 @@ func (g *mockGrammar) CompileRename(from, to string) string {
 }
 func (g *mockGrammar) CompileHasTable(table string) string       { return "SELECT 1" }
 func (g *mockGrammar) CompileHasColumn(table, col string) string { return "SELECT 1" }
-func (g *mockGrammar) CompileDropAllTables() string              { return "DROP ALL" }
+func (g *mockGrammar) CompileDropAll(context.Context, schema.Queryer, schema.DropAllOptions) ([]string, error) {
+	return []string{"DROP ALL"}, nil
+}
 func (g *mockGrammar) CompileColumnType(col schema.ColumnDefinition) (string, error) {
 	return "TEXT", nil
 }
@@ -187,6 +192,41 @@ func TestExecuteInTransaction_BeginError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// cancellingMigration cancels its context after the first statement, as an
+// interrupt arriving mid-migration would.
+type cancellingMigration struct {
+	cancel context.CancelFunc
+}
+
+func (m *cancellingMigration) Up(b *schema.Builder) error {
+	if err := b.Drop("users"); err != nil {
+		return err
+	}
+	m.cancel()
+	return b.Drop("posts")
+}
+
+func (m *cancellingMigration) Down(b *schema.Builder) error { return nil }
+
+func TestExecuteContext_CancelledMidMigration_RollsBack(t *testing.T) {
+	db, mock := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(db, &mockGrammar{}, nil)
+	err := runner.ExecuteContext(ctx, &cancellingMigration{cancel: cancel}, "up", "20240101000000_drop_tables")
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // --- Dry-run tests ---
 
 // createTableMigration creates a table in Up and drops it in Down.
@@ -273,3 +313,102 @@ func TestExecuteDryRun_NoTransactionCommitted(t *testing.T) {
 	// mock has no expectations set — if Begin/Commit were called, it would fail.
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// nullableUserNameMigration makes users.name nullable, which SQLite
+// applies by rebuilding the table.
+type nullableUserNameMigration struct{}
+
+func (m *nullableUserNameMigration) Up(b *schema.Builder) error {
+	return b.Alter("users", func(bp *schema.Blueprint) {
+		bp.String("name", 50).Nullable().Change()
+	})
+}
+
+func (m *nullableUserNameMigration) Down(b *schema.Builder) error {
+	return b.Alter("users", func(bp *schema.Blueprint) {
+		bp.String("name", 50).Change()
+	})
+}
+
+// TestExecute_RebuildsReferencedTableWithoutCascading rebuilds a table
+// that another references with ON DELETE CASCADE. SQLite ignores
+// switching foreign keys off inside a transaction, so dropping the old
+// table would delete the referencing rows unless the Runner switches
+// enforcement off before the transaction begins.
+func TestExecute_RebuildsReferencedTableWithoutCascading(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "fk.db")+"?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("name", 50)
+	}))
+	require.NoError(t, b.Create("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.BigInteger("user_id")
+		bp.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
+	}))
+	_, err = db.Exec(`INSERT INTO users (name) VALUES ('ann'); INSERT INTO posts (user_id) VALUES (1)`)
+	require.NoError(t, err)
+
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_nullable_user_name", &nullableUserNameMigration{}))
+	require.NoError(t, m.Up())
+
+	var posts int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&posts))
+	assert.Equal(t, 1, posts)
+	var enforced int
+	require.NoError(t, db.QueryRow(`PRAGMA foreign_keys`).Scan(&enforced))
+	assert.Equal(t, 1, enforced, "enforcement is switched back on")
+
+	require.NoError(t, m.Rollback(0))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&posts))
+	assert.Equal(t, 1, posts)
+}
+
+// TestExecute_RebuildFailsOnForeignKeyViolations rolls back a rebuild that
+// leaves rows referencing missing ones.
+func TestExecute_RebuildFailsOnForeignKeyViolations(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "fk.db")+"?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("name", 50)
+	}))
+	require.NoError(t, b.Create("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.BigInteger("user_id")
+	}))
+	_, err = db.Exec(`INSERT INTO posts (user_id) VALUES (7)`)
+	require.NoError(t, err)
+
+	r := NewRunner(db, grammars.NewSQLiteGrammar(), nil)
+	err = r.Execute(&addPostsForeignKeyMigration{}, "up", "20240101000000_posts_user_fk")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `references a missing row of "users"`)
+
+	var fks int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pragma_foreign_key_list('posts')`).Scan(&fks))
+	assert.Zero(t, fks, "the rebuild is rolled back")
+}
+
+// addPostsForeignKeyMigration adds a foreign key from posts.user_id to
+// users.id, which SQLite applies by rebuilding posts.
+type addPostsForeignKeyMigration struct{}
+
+func (m *addPostsForeignKeyMigration) Up(b *schema.Builder) error {
+	return b.Alter("posts", func(bp *schema.Blueprint) {
+		bp.Foreign("user_id").References("id").On("users")
+	})
+}
+
+func (m *addPostsForeignKeyMigration) Down(b *schema.Builder) error {
+	return nil
+}
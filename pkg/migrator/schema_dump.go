@@ -0,0 +1,580 @@
+package migrator
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+)
+
+// SchemaDumper introspects a live database and returns the DDL statements
+// that recreate its current schema: tables, indexes, constraints and views.
+type SchemaDumper interface {
+	// Dump returns the statements, without trailing semicolons, in an order
+	// that can be executed as-is on an empty database. Tables named in
+	// exclude, and their indexes, are left out.
+	Dump(ctx context.Context, db *sql.DB, exclude []string) ([]string, error)
+}
+
+// schemaDumperMap maps database driver names to SchemaDumper implementations.
+var schemaDumperMap = map[string]SchemaDumper{
+	"postgres": PostgresSchemaDumper{},
+	"mysql":    MySQLSchemaDumper{},
+	"sqlite":   SQLiteSchemaDumper{},
+	"sqlite3":  SQLiteSchemaDumper{},
+}
+
+// ResolveSchemaDumper returns the SchemaDumper for the given database
+// driver name. It returns an error if the driver is not recognized.
+func ResolveSchemaDumper(driver string) (SchemaDumper, error) {
+	d, ok := schemaDumperMap[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver %q", driver)
+	}
+	return d, nil
+}
+
+// schemaDumperForGrammar returns the SchemaDumper matching one of the
+// built-in grammars, or nil for grammars it does not know.
+func schemaDumperForGrammar(g schema.Grammar) SchemaDumper {
+	switch g.(type) {
+	case *grammars.PostgresGrammar:
+		return PostgresSchemaDumper{}
+	case *grammars.MySQLGrammar:
+		return MySQLSchemaDumper{}
+	case *grammars.SQLiteGrammar:
+		return SQLiteSchemaDumper{}
+	default:
+		return nil
+	}
+}
+
+// SchemaDump is a baseline schema: the statements that recreate the
+// database plus the names of the migrations whose effect they contain.
+type SchemaDump struct {
+	Migrations []string
+	Statements []string
+}
+
+// schemaDumpMigrationPrefix marks a covered migration in the dump header.
+const schemaDumpMigrationPrefix = "-- migration: "
+
+// WriteTo writes the dump as a SQL script. The covered migrations are
+// listed as comments in the header so ReadSchemaDump can restore them.
+func (d *SchemaDump) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString("-- go-migration schema dump\n")
+	fmt.Fprintf(&b, "-- Generated at %s\n", time.Now().UTC().Format(time.RFC3339))
+	for _, name := range d.Migrations {
+		b.WriteString(schemaDumpMigrationPrefix + name + "\n")
+	}
+	for _, stmt := range d.Statements {
+		b.WriteString("\n" + stmt + ";\n")
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ReadSchemaDump parses a script written by SchemaDump.WriteTo.
+func ReadSchemaDump(r io.Reader) (*SchemaDump, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &SchemaDump{}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name, ok := strings.CutPrefix(line, schemaDumpMigrationPrefix); ok {
+			dump.Migrations = append(dump.Migrations, strings.TrimSpace(name))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	dump.Statements = splitStatements(string(content))
+	return dump, nil
+}
+
+// DumpSchema writes the current database schema to path as a baseline and
+// returns the applied migrations it covers. The migration tracking table
+// and its lock table are not part of the dump.
+//
+// Requires a SchemaDumper, set with WithSchemaDumper or derived from the
+// grammar set with WithGrammar. With WithOnlyConnection the named
+// connection is dumped.
+func (m *Migrator) DumpSchema(path string) ([]string, error) {
+	return m.DumpSchemaContext(context.Background(), path)
+}
+
+// DumpSchemaContext is like DumpSchema but executes with ctx.
+func (m *Migrator) DumpSchemaContext(ctx context.Context, path string) ([]string, error) {
+	t, err := m.selected()
+	if err != nil {
+		return nil, err
+	}
+	dumper := t.schemaDumper
+	if dumper == nil {
+		dumper = schemaDumperForGrammar(t.grammar)
+	}
+	if dumper == nil {
+		return nil, fmt.Errorf("schema dump requires a schema dumper: configure with WithSchemaDumper or WithGrammar")
+	}
+
+	var covered []string
+	err = m.withLock(ctx, func() error {
+		if err := t.tracker.EnsureTableContext(ctx); err != nil {
+			return err
+		}
+		applied, err := t.tracker.GetAppliedContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		statements, err := dumper.Dump(ctx, t.db, []string{t.tableName, t.tableName + "_lock"})
+		if err != nil {
+			return fmt.Errorf("dump schema: %w", err)
+		}
+
+		dump := &SchemaDump{Statements: statements}
+		for _, rec := range applied {
+			dump.Migrations = append(dump.Migrations, rec.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("dump schema: %w", err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("dump schema: %w", err)
+		}
+		if _, err := dump.WriteTo(f); err != nil {
+			f.Close()
+			return fmt.Errorf("dump schema: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("dump schema: %w", err)
+		}
+
+		covered = dump.Migrations
+		return nil
+	})
+	return covered, err
+}
+
+// readSchemaDump reads the baseline configured with WithSchemaDump. It
+// returns nil when no baseline is configured or the file does not exist.
+func (m *Migrator) readSchemaDump() (*SchemaDump, error) {
+	if m.schemaDumpPath == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(m.schemaDumpPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load schema dump: %w", err)
+	}
+	defer f.Close()
+	dump, err := ReadSchemaDump(f)
+	if err != nil {
+		return nil, fmt.Errorf("load schema dump: %w", err)
+	}
+	return dump, nil
+}
+
+// loadSchemaDump executes the baseline configured with WithSchemaDump and
+// records the migrations it covers as one batch. It returns the covered
+// migration names, or nil when no baseline is configured or the file does
+// not exist. A database that has tables other than the tracking table is
+// not a fresh one, even without recorded migrations, and the baseline is
+// refused with ErrSchemaNotEmpty rather than loaded over it.
+func (m *Migrator) loadSchemaDump(ctx context.Context) ([]string, error) {
+	dump, err := m.readSchemaDump()
+	if dump == nil || err != nil {
+		return nil, err
+	}
+
+	tables, err := m.userTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load schema dump: %w", err)
+	}
+	if len(tables) > 0 {
+		return nil, fmt.Errorf("load schema dump %s: %w but no recorded migrations (%s); record the migrations they came from, or load the baseline into an empty database",
+			m.schemaDumpPath, ErrSchemaNotEmpty, strings.Join(tables, ", "))
+	}
+
+	if m.dryRun {
+		fmt.Fprintf(m.dryRunWriter, "-- Schema dump: %s\n", m.schemaDumpPath)
+		for _, stmt := range dump.Statements {
+			fmt.Fprintf(m.dryRunWriter, "%s;\n", stmt)
+		}
+		return dump.Migrations, nil
+	}
+
+	batchNumber, err := m.batch.NextBatchNumberContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single connection keeps session settings such as MySQL's
+	// FOREIGN_KEY_CHECKS in effect for every statement. The covered
+	// migrations are recorded in the same transaction.
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load schema dump: %w", err)
+	}
+	for _, stmt := range dump.Statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("load schema dump: %w", err)
+		}
+	}
+	for _, name := range dump.Migrations {
+		if err := m.tracker.on(tx).RecordContext(ctx, name, batchNumber, ""); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("load schema dump: %w", err)
+	}
+
+	if m.logger != nil {
+		m.logger.Info("Loaded schema dump %s (%d migrations)", m.schemaDumpPath, len(dump.Migrations))
+	}
+	return dump.Migrations, nil
+}
+
+// stopAtBaseline returns records, given in rollback order, up to the
+// first one whose migration was pruned into the configured schema dump:
+// one the dump covers that is no longer registered, as after schema:dump
+// --prune. Its Down is gone, so it and the older records stay applied as
+// the baseline.
+func (m *Migrator) stopAtBaseline(records []MigrationRecord) ([]MigrationRecord, error) {
+	dump, err := m.readSchemaDump()
+	if dump == nil || err != nil {
+		return records, err
+	}
+	covered := excludeSet(dump.Migrations)
+	for i, rec := range records {
+		if _, ok := covered[rec.Name]; !ok {
+			continue
+		}
+		if _, err := m.registry.Get(rec.Name); err == nil {
+			continue
+		}
+		if m.logger != nil {
+			m.logger.Info("Stopping at %s: it was pruned into the schema dump %s", rec.Name, m.schemaDumpPath)
+		}
+		return records[:i], nil
+	}
+	return records, nil
+}
+
+// userTables returns the tables of the database other than the migration
+// tracking table and its lock table.
+func (m *Migrator) userTables(ctx context.Context) ([]string, error) {
+	inspector, err := schema.NewInspectorContext(ctx, m.db, m.grammar)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := inspector.Tables()
+	if err != nil {
+		return nil, err
+	}
+	skip := excludeSet([]string{m.tableName, m.tableName + "_lock"})
+	var user []string
+	for _, table := range tables {
+		if _, ok := skip[table]; !ok {
+			user = append(user, table)
+		}
+	}
+	return user, nil
+}
+
+// excludeSet returns names as a lookup set.
+func excludeSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		set[n] = struct{}{}
+	}
+	return set
+}
+
+// --- PostgreSQL ---
+
+// PostgresSchemaDumper dumps the tables, indexes, constraints, standalone
+// sequences and views of the current schema from the system catalogs.
+// Serial columns are written as SERIAL/BIGSERIAL/SMALLSERIAL so their
+// sequences are recreated with them.
+type PostgresSchemaDumper struct{}
+
+const pgTableOID = `(SELECT c.oid FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND c.relname = $1)`
+
+var pgSerialTypes = map[string]string{
+	"integer":  "SERIAL",
+	"bigint":   "BIGSERIAL",
+	"smallint": "SMALLSERIAL",
+}
+
+func (PostgresSchemaDumper) Dump(ctx context.Context, db *sql.DB, exclude []string) ([]string, error) {
+	skip := excludeSet(exclude)
+	var statements []string
+
+	sequences, err := queryStrings(ctx, db, `SELECT c.relname FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relkind = 'S'
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = c.oid AND d.deptype IN ('a', 'i'))
+		ORDER BY c.relname`)
+	if err != nil {
+		return nil, err
+	}
+	for _, seq := range sequences {
+		statements = append(statements, "CREATE SEQUENCE "+quoteParts(seq, `"`))
+	}
+
+	tables, err := queryStrings(ctx, db, `SELECT c.relname FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname`)
+	if err != nil {
+		return nil, err
+	}
+
+	var foreignKeys, indexes []string
+	for _, table := range tables {
+		if _, ok := skip[table]; ok {
+			continue
+		}
+		quoted := quoteParts(table, `"`)
+
+		var defs []string
+		rows, err := db.QueryContext(ctx, `SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), ''), a.attidentity
+			FROM pg_attribute a
+			LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+			WHERE a.attrelid = `+pgTableOID+` AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY a.attnum`, table)
+		if err != nil {
+			return nil, fmt.Errorf("columns of %q: %w", table, err)
+		}
+		for rows.Next() {
+			var name, typ, def, identity string
+			var notNull bool
+			if err := rows.Scan(&name, &typ, &notNull, &def, &identity); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("columns of %q: %w", table, err)
+			}
+			col := quoteParts(name, `"`) + " "
+			if serial, ok := pgSerialTypes[typ]; ok && strings.HasPrefix(def, "nextval(") {
+				col += serial
+				def = ""
+			} else {
+				col += typ
+			}
+			switch identity {
+			case "a":
+				col += " GENERATED ALWAYS AS IDENTITY"
+			case "d":
+				col += " GENERATED BY DEFAULT AS IDENTITY"
+			}
+			if notNull {
+				col += " NOT NULL"
+			}
+			if def != "" {
+				col += " DEFAULT " + def
+			}
+			defs = append(defs, col)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("columns of %q: %w", table, err)
+		}
+		rows.Close()
+
+		rows, err = db.QueryContext(ctx, `SELECT conname, contype, pg_get_constraintdef(oid)
+			FROM pg_constraint WHERE conrelid = `+pgTableOID+`
+			ORDER BY contype, conname`, table)
+		if err != nil {
+			return nil, fmt.Errorf("constraints of %q: %w", table, err)
+		}
+		for rows.Next() {
+			var name, kind, def string
+			if err := rows.Scan(&name, &kind, &def); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("constraints of %q: %w", table, err)
+			}
+			constraint := "CONSTRAINT " + quoteParts(name, `"`) + " " + def
+			if kind == "f" {
+				// Foreign keys are added after every table exists.
+				foreignKeys = append(foreignKeys, "ALTER TABLE "+quoted+" ADD "+constraint)
+				continue
+			}
+			defs = append(defs, constraint)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("constraints of %q: %w", table, err)
+		}
+		rows.Close()
+
+		statements = append(statements, fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", quoted, strings.Join(defs, ",\n    ")))
+
+		tableIndexes, err := queryStrings(ctx, db, `SELECT indexdef FROM pg_indexes
+			WHERE schemaname = current_schema() AND tablename = $1
+			AND indexname NOT IN (SELECT conname FROM pg_constraint WHERE conrelid = `+pgTableOID+`)
+			ORDER BY indexname`, table)
+		if err != nil {
+			return nil, fmt.Errorf("indexes of %q: %w", table, err)
+		}
+		indexes = append(indexes, tableIndexes...)
+	}
+	statements = append(statements, indexes...)
+	statements = append(statements, foreignKeys...)
+
+	rows, err := db.QueryContext(ctx, `SELECT viewname, definition FROM pg_views
+		WHERE schemaname = current_schema() ORDER BY viewname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, def string
+		if err := rows.Scan(&name, &def); err != nil {
+			return nil, err
+		}
+		def = strings.TrimSuffix(strings.TrimSpace(def), ";")
+		statements = append(statements, "CREATE VIEW "+quoteParts(name, `"`)+" AS\n"+def)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+// --- MySQL ---
+
+// MySQLSchemaDumper dumps tables and views with SHOW CREATE TABLE/VIEW.
+// Foreign key checks are disabled while the dump is loaded so tables can
+// be created in name order.
+type MySQLSchemaDumper struct{}
+
+var (
+	mysqlAutoIncrement = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+	mysqlDefiner       = regexp.MustCompile(` DEFINER=\S+`)
+)
+
+func (MySQLSchemaDumper) Dump(ctx context.Context, db *sql.DB, exclude []string) ([]string, error) {
+	skip := excludeSet(exclude)
+
+	rows, err := db.QueryContext(ctx, "SHOW FULL TABLES")
+	if err != nil {
+		return nil, err
+	}
+	var tables, views []string
+	for rows.Next() {
+		var name, kind string
+		if err := rows.Scan(&name, &kind); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := skip[name]; ok {
+			continue
+		}
+		if kind == "VIEW" {
+			views = append(views, name)
+		} else {
+			tables = append(tables, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	statements := []string{"SET FOREIGN_KEY_CHECKS = 0"}
+	for _, table := range tables {
+		var name, ddl string
+		if err := db.QueryRowContext(ctx, "SHOW CREATE TABLE "+quoteParts(table, "`")).Scan(&name, &ddl); err != nil {
+			return nil, fmt.Errorf("show create table %q: %w", table, err)
+		}
+		statements = append(statements, mysqlAutoIncrement.ReplaceAllString(ddl, ""))
+	}
+	for _, view := range views {
+		var name, ddl, charset, collation string
+		if err := db.QueryRowContext(ctx, "SHOW CREATE VIEW "+quoteParts(view, "`")).Scan(&name, &ddl, &charset, &collation); err != nil {
+			return nil, fmt.Errorf("show create view %q: %w", view, err)
+		}
+		statements = append(statements, mysqlDefiner.ReplaceAllString(ddl, ""))
+	}
+	statements = append(statements, "SET FOREIGN_KEY_CHECKS = 1")
+
+	return statements, nil
+}
+
+// --- SQLite ---
+
+// SQLiteSchemaDumper dumps the DDL stored in sqlite_master: tables first,
+// then indexes, views and triggers.
+type SQLiteSchemaDumper struct{}
+
+func (SQLiteSchemaDumper) Dump(ctx context.Context, db *sql.DB, exclude []string) ([]string, error) {
+	skip := excludeSet(exclude)
+
+	rows, err := db.QueryContext(ctx, `SELECT tbl_name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'view' THEN 2 ELSE 3 END, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []string
+	for rows.Next() {
+		var table, ddl string
+		if err := rows.Scan(&table, &ddl); err != nil {
+			return nil, err
+		}
+		if _, ok := skip[table]; ok {
+			continue
+		}
+		statements = append(statements, ddl)
+	}
+	return statements, rows.Err()
+}
+
+// queryStrings runs a query returning a single string column.
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,306 @@
+package migrator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createPostsMigration creates a "posts" table with an index.
+type createPostsMigration struct{}
+
+func (m *createPostsMigration) Up(b *schema.Builder) error {
+	return b.Create("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("title", 255)
+		bp.Index("title")
+	})
+}
+
+func (m *createPostsMigration) Down(b *schema.Builder) error {
+	return b.Drop("posts")
+}
+
+func TestResolveSchemaDumper(t *testing.T) {
+	for driver, want := range map[string]SchemaDumper{
+		"postgres": PostgresSchemaDumper{},
+		"mysql":    MySQLSchemaDumper{},
+		"sqlite":   SQLiteSchemaDumper{},
+		"sqlite3":  SQLiteSchemaDumper{},
+	} {
+		d, err := ResolveSchemaDumper(driver)
+		require.NoError(t, err, driver)
+		assert.Equal(t, want, d, driver)
+	}
+
+	_, err := ResolveSchemaDumper("oracle")
+	assert.Error(t, err)
+}
+
+func TestSchemaDump_WriteAndRead(t *testing.T) {
+	dump := &SchemaDump{
+		Migrations: []string{"20240101000000_create_users", "20240102000000_create_posts"},
+		Statements: []string{"CREATE TABLE users (name TEXT DEFAULT 'a;b')", "CREATE INDEX idx ON users (name)"},
+	}
+
+	var buf bytes.Buffer
+	_, err := dump.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "-- migration: 20240101000000_create_users\n")
+
+	got, err := ReadSchemaDump(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, dump.Migrations, got.Migrations)
+	assert.Equal(t, dump.Statements, got.Statements)
+}
+
+func TestDumpSchema_RequiresDumper(t *testing.T) {
+	m, db, _ := newTestMigrator(t)
+	defer db.Close()
+
+	_, err := m.DumpSchema(filepath.Join(t.TempDir(), "schema.sql"))
+	assert.Error(t, err)
+}
+
+// TestSchemaDump_SQLite dumps a migrated SQLite database, loads the
+// baseline into an empty database and checks that only newer migrations
+// run afterwards.
+func TestSchemaDump_SQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "schema", "sqlite-schema.sql")
+
+	source := openSQLite(t)
+	m := New(source, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_posts", &createPostsMigration{}))
+	require.NoError(t, m.Up())
+	// The trigger body holds semicolons of its own.
+	_, err := source.Exec(`CREATE TRIGGER posts_title AFTER INSERT ON posts BEGIN
+	UPDATE posts SET title = upper(NEW.title) WHERE id = NEW.id;
+	INSERT INTO users (name) VALUES (CASE WHEN NEW.title = '' THEN 'anon;' ELSE NEW.title END);
+END`)
+	require.NoError(t, err)
+
+	covered, err := m.DumpSchema(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20240101000000_create_users", "20240102000000_create_posts"}, covered)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `CREATE TABLE "posts"`)
+	assert.Contains(t, string(content), "CREATE INDEX")
+	assert.Contains(t, string(content), "CREATE TRIGGER posts_title")
+	assert.NotContains(t, string(content), `"migrations"`, "the tracking table is not dumped")
+
+	// A fresh database only knows the newest migration; the dumped ones
+	// may already have been pruned.
+	target := openSQLite(t)
+	newest := &noopMigration{}
+	m2 := New(target, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(path))
+	require.NoError(t, m2.Register("20240103000000_add_tags", newest))
+	require.NoError(t, m2.UpContext(ctx))
+	assert.True(t, newest.upCalled)
+
+	var count int
+	require.NoError(t, target.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('users', 'posts', 'idx_posts_title')`).Scan(&count))
+	assert.Equal(t, 3, count)
+
+	_, err = target.Exec(`INSERT INTO posts (title) VALUES ('hello')`)
+	require.NoError(t, err)
+	var title, name string
+	require.NoError(t, target.QueryRow(`SELECT title FROM posts`).Scan(&title))
+	require.NoError(t, target.QueryRow(`SELECT name FROM users`).Scan(&name))
+	assert.Equal(t, "HELLO", title, "the trigger is loaded whole")
+	assert.Equal(t, "hello", name)
+
+	applied, err := m2.tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	assert.Equal(t, 1, applied[0].Batch)
+	assert.Equal(t, 1, applied[1].Batch)
+	assert.Equal(t, "20240103000000_add_tags", applied[2].Name)
+	assert.Equal(t, 2, applied[2].Batch)
+
+	// With migrations recorded, the baseline is not loaded again.
+	require.NoError(t, m2.Up())
+}
+
+// TestRollback_StopsAtPrunedMigrations dumps a migrated database, drops
+// the covered migrations from the registry as schema:dump --prune does,
+// and checks that rollbacks leave the baseline in place.
+func TestRollback_StopsAtPrunedMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(path))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Register("20240102000000_create_posts", &createPostsMigration{}))
+	require.NoError(t, m.Up())
+	_, err := m.DumpSchema(path)
+	require.NoError(t, err)
+
+	newest := &noopMigration{}
+	pruned := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(path))
+	require.NoError(t, pruned.Register("20240103000000_add_tags", newest))
+	require.NoError(t, pruned.Up())
+
+	require.NoError(t, pruned.Rollback(0))
+	assert.True(t, newest.downCalled)
+	require.NoError(t, pruned.Rollback(0), "the baseline batch is not rolled back")
+	require.NoError(t, pruned.Rollback(5))
+	require.NoError(t, pruned.Reset())
+	require.NoError(t, pruned.Refresh())
+
+	applied, err := pruned.tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	assert.Equal(t, "20240101000000_create_users", applied[0].Name)
+	assert.True(t, hasSQLiteTable(t, db, "posts"))
+
+	// Without the dump the pruned migrations are unknown.
+	unknown := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, unknown.Register("20240103000000_add_tags", &noopMigration{}))
+	require.NoError(t, unknown.Rollback(0))
+	assert.ErrorIs(t, unknown.Rollback(0), ErrMigrationNotFound)
+}
+
+// TestUp_SchemaDumpRefusesDatabaseWithTables checks that the baseline is
+// not loaded over tables that exist without recorded migrations.
+func TestUp_SchemaDumpRefusesDatabaseWithTables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	dump := &SchemaDump{
+		Migrations: []string{"20240101000000_create_users"},
+		Statements: []string{"CREATE TABLE users (name TEXT)"},
+	}
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = dump.WriteTo(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	db := openSQLite(t)
+	_, err = db.Exec(`CREATE TABLE legacy (id INTEGER)`)
+	require.NoError(t, err)
+
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(path))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	err = m.Up()
+	require.ErrorIs(t, err, ErrSchemaNotEmpty)
+	assert.Contains(t, err.Error(), "legacy")
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'users'`).Scan(&count))
+	assert.Zero(t, count)
+	applied, err := m.tracker.GetApplied()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestUp_MissingSchemaDumpIsIgnored(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(filepath.Join(t.TempDir(), "missing.sql")))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Up())
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+}
+
+func TestUp_SchemaDumpDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	dump := &SchemaDump{
+		Migrations: []string{"20240101000000_create_users"},
+		Statements: []string{"CREATE TABLE users (name TEXT)"},
+	}
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = dump.WriteTo(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	db := openSQLite(t)
+	var buf bytes.Buffer
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()), WithSchemaDump(path), WithDryRun(&buf))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.Up())
+
+	assert.Contains(t, buf.String(), "CREATE TABLE users (name TEXT);")
+	assert.NotContains(t, buf.String(), "-- Migration: 20240101000000_create_users", "covered migrations are not run")
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'users'`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestMySQLSchemaDumper_Dump(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SHOW FULL TABLES").WillReturnRows(sqlmock.NewRows([]string{"Tables_in_app", "Table_type"}).
+		AddRow("migrations", "BASE TABLE").
+		AddRow("users", "BASE TABLE").
+		AddRow("active_users", "VIEW"))
+	mock.ExpectQuery("SHOW CREATE TABLE `users`").WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).
+		AddRow("users", "CREATE TABLE `users` (\n  `id` bigint NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4"))
+	mock.ExpectQuery("SHOW CREATE VIEW `active_users`").WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+		AddRow("active_users", "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `active_users` AS select `users`.`id` AS `id` from `users`", "utf8mb4", "utf8mb4_0900_ai_ci"))
+
+	statements, err := MySQLSchemaDumper{}.Dump(context.Background(), db, []string{"migrations"})
+	require.NoError(t, err)
+	require.Len(t, statements, 4)
+	assert.Equal(t, "SET FOREIGN_KEY_CHECKS = 0", statements[0])
+	assert.NotContains(t, statements[1], "AUTO_INCREMENT=42")
+	assert.Contains(t, statements[1], "AUTO_INCREMENT,")
+	assert.NotContains(t, statements[2], "DEFINER=`root`")
+	assert.Equal(t, "SET FOREIGN_KEY_CHECKS = 1", statements[3])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresSchemaDumper_Dump(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("relkind = 'S'").WillReturnRows(sqlmock.NewRows([]string{"relname"}))
+	mock.ExpectQuery("relkind IN \\('r', 'p'\\)").WillReturnRows(sqlmock.NewRows([]string{"relname"}).
+		AddRow("migrations").
+		AddRow("posts"))
+	mock.ExpectQuery("FROM pg_attribute").WithArgs("posts").WillReturnRows(
+		sqlmock.NewRows([]string{"attname", "format_type", "attnotnull", "default", "attidentity"}).
+			AddRow("id", "bigint", true, "nextval('posts_id_seq'::regclass)", "").
+			AddRow("user_id", "bigint", true, "", "").
+			AddRow("title", "character varying(255)", false, "'untitled'::character varying", ""))
+	mock.ExpectQuery("FROM pg_constraint").WithArgs("posts").WillReturnRows(
+		sqlmock.NewRows([]string{"conname", "contype", "def"}).
+			AddRow("posts_user_id_foreign", "f", "FOREIGN KEY (user_id) REFERENCES users(id)").
+			AddRow("posts_pkey", "p", "PRIMARY KEY (id)"))
+	mock.ExpectQuery("FROM pg_indexes").WithArgs("posts").WillReturnRows(
+		sqlmock.NewRows([]string{"indexdef"}).
+			AddRow("CREATE INDEX idx_posts_title ON public.posts USING btree (title)"))
+	mock.ExpectQuery("FROM pg_views").WillReturnRows(sqlmock.NewRows([]string{"viewname", "definition"}))
+
+	statements, err := PostgresSchemaDumper{}.Dump(context.Background(), db, []string{"migrations"})
+	require.NoError(t, err)
+	require.Len(t, statements, 3)
+	assert.Equal(t, strings.Join([]string{
+		`CREATE TABLE "posts" (`,
+		`    "id" BIGSERIAL NOT NULL,`,
+		`    "user_id" bigint NOT NULL,`,
+		`    "title" character varying(255) DEFAULT 'untitled'::character varying,`,
+		`    CONSTRAINT "posts_pkey" PRIMARY KEY (id)`,
+		`)`,
+	}, "\n"), statements[0])
+	assert.Equal(t, "CREATE INDEX idx_posts_title ON public.posts USING btree (title)", statements[1])
+	assert.Equal(t, `ALTER TABLE "posts" ADD CONSTRAINT "posts_user_id_foreign" FOREIGN KEY (user_id) REFERENCES users(id)`, statements[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+package migrator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// Source supplies migrations to a Migrator, keyed by migration name.
+// Migrations from several sources are merged into one registry and run in
+// name order; a name supplied twice is an ErrDuplicateMigration.
+type Source interface {
+	Migrations() (map[string]Migration, error)
+}
+
+// GoSource is a Source of Go migrations registered in memory, such as the
+// migrations a library module ships without relying on init() side
+// effects.
+type GoSource map[string]Migration
+
+// Migrations returns the migrations of the GoSource.
+func (s GoSource) Migrations() (map[string]Migration, error) {
+	return s, nil
+}
+
+// FSSource is a Source of SQL migration files at the root of a file
+// system, such as an embed.FS; see LoadSQLMigrations.
+type FSSource struct {
+	FS fs.FS
+}
+
+// NewFSSource returns a Source of the SQL migration files in fsys.
+func NewFSSource(fsys fs.FS) *FSSource {
+	return &FSSource{FS: fsys}
+}
+
+// Migrations loads the SQL migrations of the file system.
+func (s *FSSource) Migrations() (map[string]Migration, error) {
+	return LoadSQLMigrations(s.FS)
+}
+
+// DirSource is a Source of SQL migration files in a local directory.
+type DirSource struct {
+	Dir string
+}
+
+// NewDirSource returns a Source of the SQL migration files in dir.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{Dir: dir}
+}
+
+// Migrations loads the SQL migrations of the directory. A missing
+// directory is an error.
+func (s *DirSource) Migrations() (map[string]Migration, error) {
+	info, err := os.Stat(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("migration directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("migration directory %q is not a directory", s.Dir)
+	}
+	return LoadSQLMigrations(os.DirFS(s.Dir))
+}
+
+// WithSource returns an Option that registers the migrations of each
+// source. Panics if a source fails to load or supplies an invalid or
+// duplicate name (consistent with WithAutoDiscover); use AddSource to
+// handle the error instead.
+func WithSource(sources ...Source) Option {
+	return func(m *Migrator) {
+		for _, src := range sources {
+			if err := m.AddSource(src); err != nil {
+				panic(fmt.Sprintf("WithSource: %v", err))
+			}
+		}
+	}
+}
+
+// AddSource registers the migrations of src alongside those already
+// registered.
+func (m *Migrator) AddSource(src Source) error {
+	migrations, err := src.Migrations()
+	if err != nil {
+		return fmt.Errorf("load migration source: %w", err)
+	}
+
+	names := make([]string, 0, len(migrations))
+	for name := range migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := m.registry.Register(name, migrations[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package migrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSource_MergesSources(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20240103000000_create_tags.up.sql"),
+		[]byte(`CREATE TABLE tags (id INTEGER PRIMARY KEY)`), 0o644))
+
+	db := openSQLite(t)
+	m := New(db,
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithSource(
+			GoSource{"20240102000000_create_users": &createTableMigration{}},
+			NewFSSource(fstest.MapFS{
+				"20240101000000_create_roles.up.sql": {Data: []byte(`CREATE TABLE roles (id INTEGER PRIMARY KEY)`)},
+			}),
+			NewDirSource(dir),
+		),
+	)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	var names []string
+	for _, s := range statuses {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{
+		"20240101000000_create_roles",
+		"20240102000000_create_users",
+		"20240103000000_create_tags",
+	}, names)
+
+	require.NoError(t, m.Up())
+	for _, table := range []string{"roles", "users", "tags"} {
+		assert.True(t, hasSQLiteTable(t, db, table), table)
+	}
+}
+
+func TestAddSource_Duplicate(t *testing.T) {
+	m := New(openSQLite(t))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	err := m.AddSource(GoSource{"20240101000000_create_users": &createTableMigration{}})
+	assert.True(t, errors.Is(err, ErrDuplicateMigration))
+}
+
+func TestAddSource_MissingDirectory(t *testing.T) {
+	m := New(openSQLite(t))
+	err := m.AddSource(NewDirSource(filepath.Join(t.TempDir(), "missing")))
+	assert.ErrorContains(t, err, "load migration source")
+
+	assert.Panics(t, func() {
+		New(openSQLite(t), WithSource(NewDirSource(filepath.Join(t.TempDir(), "missing"))))
+	})
+}
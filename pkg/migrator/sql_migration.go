@@ -0,0 +1,152 @@
+package migrator
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/internal/scanner"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// nonTransactionalDirective is the comment line that makes an SQL
+// migration run outside a transaction, for statements such as
+// CREATE INDEX CONCURRENTLY.
+const nonTransactionalDirective = "-- +nontransactional"
+
+// lintIgnoreDirective starts the comment line that suppresses lint rules
+// for an SQL migration, e.g. "-- +lint:ignore rename-table".
+const lintIgnoreDirective = "-- +lint:ignore"
+
+// SQLMigration is a migration written as plain SQL: an up script and an
+// optional down script, each split into statements that are executed in
+// order through the schema Builder.
+type SQLMigration struct {
+	name             string
+	up               []string
+	down             []string
+	hasDown          bool
+	nonTransactional bool
+	lintIgnored      []string
+}
+
+// NewSQLMigration creates a migration named name from the given up and
+// down scripts; down may be empty for migrations that cannot be rolled
+// back. A "-- +nontransactional" line in either script runs the migration
+// outside a transaction, and a "-- +lint:ignore rule,..." line suppresses
+// the listed lint rules.
+func NewSQLMigration(name, up, down string) *SQLMigration {
+	return &SQLMigration{
+		name:             name,
+		up:               splitStatements(up),
+		down:             splitStatements(down),
+		hasDown:          strings.TrimSpace(down) != "",
+		nonTransactional: hasDirective(up, nonTransactionalDirective) || hasDirective(down, nonTransactionalDirective),
+		lintIgnored:      append(directiveArgs(up, lintIgnoreDirective), directiveArgs(down, lintIgnoreDirective)...),
+	}
+}
+
+// Up executes the statements of the up script.
+func (m *SQLMigration) Up(s *schema.Builder) error {
+	return execStatements(s, m.up)
+}
+
+// Down executes the statements of the down script. It fails when the
+// migration has none.
+func (m *SQLMigration) Down(s *schema.Builder) error {
+	if !m.hasDown {
+		return fmt.Errorf("sql migration %q has no down script", m.name)
+	}
+	return execStatements(s, m.down)
+}
+
+// DisableTransaction reports whether a script carries the
+// "-- +nontransactional" directive.
+func (m *SQLMigration) DisableTransaction() bool {
+	return m.nonTransactional
+}
+
+// Checksum returns the checksum of the up script, so that it is computed
+// from the file rather than the database.
+func (m *SQLMigration) Checksum() string {
+	return Checksum(m.up)
+}
+
+// SuppressLint returns the rules listed on "-- +lint:ignore" lines.
+func (m *SQLMigration) SuppressLint() []string {
+	return m.lintIgnored
+}
+
+// execStatements executes each statement through the Builder.
+func execStatements(s *schema.Builder, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := s.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDirective reports whether script has a line consisting of directive.
+func hasDirective(script, directive string) bool {
+	sc := bufio.NewScanner(strings.NewReader(script))
+	for sc.Scan() {
+		if strings.EqualFold(strings.TrimSpace(sc.Text()), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveArgs returns the comma-separated arguments of every line of
+// script that starts with directive.
+func directiveArgs(script, directive string) []string {
+	var args []string
+	sc := bufio.NewScanner(strings.NewReader(script))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) < len(directive) || !strings.EqualFold(line[:len(directive)], directive) {
+			continue
+		}
+		for _, arg := range strings.Split(line[len(directive):], ",") {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				args = append(args, arg)
+			}
+		}
+	}
+	return args
+}
+
+// LoadSQLMigrations reads the <name>.up.sql and <name>.down.sql files at
+// the root of fsys, such as an embed.FS or os.DirFS, and returns them as
+// migrations keyed by name.
+func LoadSQLMigrations(fsys fs.FS) (map[string]Migration, error) {
+	files, err := scanner.ScanSQLMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("scan sql migrations: %w", err)
+	}
+
+	migrations := make(map[string]Migration, len(files))
+	for _, f := range files {
+		up, err := fs.ReadFile(fsys, f.Up)
+		if err != nil {
+			return nil, fmt.Errorf("read sql migration: %w", err)
+		}
+		var down []byte
+		if f.Down != "" {
+			if down, err = fs.ReadFile(fsys, f.Down); err != nil {
+				return nil, fmt.Errorf("read sql migration: %w", err)
+			}
+		}
+		migrations[f.Name] = NewSQLMigration(f.Name, string(up), string(down))
+	}
+	return migrations, nil
+}
+
+// RegisterFS registers the SQL migrations at the root of fsys alongside
+// the Go migrations; all of them run in name order. It is shorthand for
+// AddSource(NewFSSource(fsys)).
+func (m *Migrator) RegisterFS(fsys fs.FS) error {
+	return m.AddSource(NewFSSource(fsys))
+}
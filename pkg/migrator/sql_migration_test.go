@@ -0,0 +1,108 @@
+package migrator
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sqlMigrationsFS = fstest.MapFS{
+	"20240101000000_create_roles.up.sql": {Data: []byte(`
+CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+-- Seed the built-in roles; a ; inside a string does not split.
+INSERT INTO roles (name) VALUES ('admin;root'), ('member');
+`)},
+	"20240101000000_create_roles.down.sql": {Data: []byte(`DROP TABLE roles;`)},
+	"20240103000000_index_users.up.sql": {Data: []byte(`-- +nontransactional
+-- +lint:ignore index-concurrently, rename-table
+CREATE INDEX idx_users_name ON users (name);
+`)},
+	"20240103000000_index_users.down.sql": {Data: []byte(`DROP INDEX idx_users_name;`)},
+	"README.md":                           {Data: []byte(`not a migration`)},
+}
+
+func TestSQLMigrations_RunAlongsideGoMigrations(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240102000000_create_users", &createTableMigration{}))
+	require.NoError(t, m.RegisterFS(sqlMigrationsFS))
+
+	require.NoError(t, m.Up())
+
+	var roles int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM roles WHERE name = 'admin;root'`).Scan(&roles))
+	assert.Equal(t, 1, roles)
+
+	var applied []string
+	rows, err := db.Query(`SELECT migration FROM migrations ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		applied = append(applied, name)
+	}
+	assert.Equal(t, []string{
+		"20240101000000_create_roles",
+		"20240102000000_create_users",
+		"20240103000000_index_users",
+	}, applied, "SQL and Go migrations run in name order")
+
+	require.NoError(t, m.Reset())
+	assert.False(t, hasSQLiteTable(t, db, "roles"))
+}
+
+func TestSQLMigration_Directives(t *testing.T) {
+	migrations, err := LoadSQLMigrations(sqlMigrationsFS)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.False(t, migrations["20240101000000_create_roles"].(TransactionOption).DisableTransaction())
+	assert.True(t, migrations["20240103000000_index_users"].(TransactionOption).DisableTransaction())
+
+	assert.Empty(t, migrations["20240101000000_create_roles"].(LintOption).SuppressLint())
+	assert.Equal(t, []string{LintIndexConcurrently, LintRenameTable},
+		migrations["20240103000000_index_users"].(LintOption).SuppressLint())
+}
+
+func TestSQLMigration_DryRun(t *testing.T) {
+	var out bytes.Buffer
+	m := New(openSQLite(t), WithGrammar(grammars.NewSQLiteGrammar()), WithDryRun(&out))
+	require.NoError(t, m.RegisterFS(sqlMigrationsFS))
+
+	require.NoError(t, m.Up())
+	assert.Contains(t, out.String(), "CREATE TABLE roles")
+	assert.Contains(t, out.String(), "CREATE INDEX idx_users_name ON users (name)")
+}
+
+func TestSQLMigration_NoDownScript(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.RegisterFS(fstest.MapFS{
+		"20240101000000_create_tags.up.sql": {Data: []byte(`CREATE TABLE tags (id INTEGER)`)},
+	}))
+
+	require.NoError(t, m.Up())
+	err := m.Rollback(0)
+	assert.ErrorContains(t, err, "has no down script")
+}
+
+func TestNewSQLMigration_DollarQuotedBody(t *testing.T) {
+	m := NewSQLMigration("20240101000000_touch_fn", `
+CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN
+  NEW.updated_at := now();
+  RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+CREATE TRIGGER users_touch BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch();
+`, "")
+
+	require.Len(t, m.up, 2)
+	assert.Contains(t, m.up[0], "RETURN NEW;\nEND;\n$body$ LANGUAGE plpgsql")
+	assert.False(t, m.hasDown)
+}
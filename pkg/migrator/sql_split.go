@@ -0,0 +1,191 @@
+package migrator
+
+import "strings"
+
+// splitStatements splits a SQL script into individual statements on
+// top-level semicolons. Semicolons inside quoted strings and identifiers
+// ('...', "...", `...`), comments (-- and /* */) and PostgreSQL
+// dollar-quoted bodies ($$...$$, $tag$...$tag$) do not end a statement.
+// The BEGIN ... END body of a CREATE TRIGGER statement, as SQLite and
+// MySQL write it, is kept whole as well. Comments before a statement's
+// first token and statements consisting only of comments are dropped; the
+// terminating semicolon is not included.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+
+	// words counts the statement's words, create and trigger record
+	// whether it is a CREATE ... TRIGGER and depth how many BEGIN or CASE
+	// blocks of the trigger body are open.
+	words, create, trigger, depth := 0, false, false, 0
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+		words, create, trigger, depth = 0, false, false, 0
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == ';':
+			if depth > 0 {
+				current.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			i++
+			continue
+
+		case isWordStart(c):
+			end := wordEnd(script, i)
+			word := strings.ToUpper(script[i:end])
+			words++
+			switch {
+			case words == 1:
+				create = word == "CREATE"
+			case !create:
+			case word == "TRIGGER" && !trigger:
+				trigger = true
+			case !trigger:
+			case word == "BEGIN" || word == "CASE":
+				depth++
+			case word == "END" && depth > 0:
+				// MySQL closes IF, LOOP, WHILE and REPEAT blocks with
+				// END IF and so on; only END and END CASE close a
+				// counted block.
+				switch nextWord(script, end) {
+				case "IF", "LOOP", "WHILE", "REPEAT":
+				default:
+					depth--
+				}
+			}
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+			continue
+
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			if hasCode {
+				current.WriteString(script[i : i+end])
+			}
+			i += end
+			continue
+
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 2
+			} else {
+				end += 2
+			}
+			if hasCode {
+				current.WriteString(script[i : i+2+end])
+			}
+			i += 2 + end
+			continue
+
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedEnd(script, i, c)
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+			continue
+
+		case c == '$':
+			if tag, ok := dollarTag(script, i); ok {
+				end := strings.Index(script[i+len(tag):], tag)
+				if end < 0 {
+					end = len(script) - i
+				} else {
+					end += 2 * len(tag)
+				}
+				current.WriteString(script[i : i+end])
+				hasCode = true
+				i += end
+				continue
+			}
+		}
+
+		current.WriteByte(c)
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			hasCode = true
+		}
+		i++
+	}
+	flush()
+
+	return statements
+}
+
+// quotedEnd returns the index just past the quoted section starting at
+// script[start], treating a doubled quote character as an escaped quote.
+func quotedEnd(script string, start int, quote byte) int {
+	for i := start + 1; i < len(script); i++ {
+		if script[i] == '\\' && quote == '\'' && i+1 < len(script) {
+			i++
+			continue
+		}
+		if script[i] == quote {
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(script)
+}
+
+// dollarTag reports whether a PostgreSQL dollar-quote opener ($$ or
+// $tag$) starts at script[start], and returns it.
+func dollarTag(script string, start int) (string, bool) {
+	for i := start + 1; i < len(script); i++ {
+		c := script[i]
+		if c == '$' {
+			return script[start : i+1], true
+		}
+		isIdent := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > start+1 && c >= '0' && c <= '9')
+		if !isIdent {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// isWordStart reports whether c can begin an unquoted SQL keyword or
+// identifier.
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// wordEnd returns the index just past the word starting at script[start].
+func wordEnd(script string, start int) int {
+	i := start
+	for i < len(script) && (isWordStart(script[i]) || (script[i] >= '0' && script[i] <= '9')) {
+		i++
+	}
+	return i
+}
+
+// nextWord returns the upper-cased word following script[start:] after
+// whitespace, or "" when something else comes first.
+func nextWord(script string, start int) string {
+	i := start
+	for i < len(script) && (script[i] == ' ' || script[i] == '\t' || script[i] == '\n' || script[i] == '\r') {
+		i++
+	}
+	if i == len(script) || !isWordStart(script[i]) {
+		return ""
+	}
+	return strings.ToUpper(script[i:wordEnd(script, i)])
+}
@@ -0,0 +1,78 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "missing final semicolon",
+			script: "SELECT 1;\nSELECT 2\n",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "semicolons in strings and identifiers",
+			script: "INSERT INTO t VALUES ('a;b', 'it''s;');\nCREATE TABLE \"x;y\" (`c;d` INT);",
+			want:   []string{"INSERT INTO t VALUES ('a;b', 'it''s;')", "CREATE TABLE \"x;y\" (`c;d` INT)"},
+		},
+		{
+			name:   "comment-only segments are dropped",
+			script: "-- header; with semicolon\n/* block; comment */\nSELECT 1 /* inline; */;\n-- trailing\n",
+			want:   []string{"SELECT 1 /* inline; */"},
+		},
+		{
+			name:   "dollar quoted bodies",
+			script: "CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN NEW.a := 1; RETURN NEW; END; $$ LANGUAGE plpgsql;\nDO $body$ BEGIN PERFORM 1; END $body$;",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN NEW.a := 1; RETURN NEW; END; $$ LANGUAGE plpgsql",
+				"DO $body$ BEGIN PERFORM 1; END $body$",
+			},
+		},
+		{
+			name: "trigger bodies",
+			script: "CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE a SET n = CASE WHEN NEW.n > 0 THEN 1 ELSE 0 END;\n  DELETE FROM b;\nEND;\n" +
+				"CREATE TEMP TRIGGER u BEFORE DELETE ON a BEGIN SELECT 1; END;\nCREATE TABLE end_dates (id INT);",
+			want: []string{
+				"CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE a SET n = CASE WHEN NEW.n > 0 THEN 1 ELSE 0 END;\n  DELETE FROM b;\nEND",
+				"CREATE TEMP TRIGGER u BEFORE DELETE ON a BEGIN SELECT 1; END",
+				"CREATE TABLE end_dates (id INT)",
+			},
+		},
+		{
+			name:   "mysql trigger with if block",
+			script: "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN IF NEW.n < 0 THEN SET NEW.n = 0; END IF; SET NEW.m = 1; END;\nSELECT 1;",
+			want: []string{
+				"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN IF NEW.n < 0 THEN SET NEW.n = 0; END IF; SET NEW.m = 1; END",
+				"SELECT 1",
+			},
+		},
+		{
+			name:   "begin outside a trigger",
+			script: "BEGIN;\nSELECT 1;\nEND;",
+			want:   []string{"BEGIN", "SELECT 1", "END"},
+		},
+		{
+			name:   "positional parameters are not dollar quotes",
+			script: "SELECT $1, $2;SELECT 3",
+			want:   []string{"SELECT $1, $2", "SELECT 3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.script))
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/andrianprasetya/go-migration/pkg/database"
+)
+
+// TenantSource lists the tenants UpTenants and StatusTenants fan out to.
+type TenantSource func(ctx context.Context) ([]string, error)
+
+// StaticTenants returns a TenantSource that lists the given tenants.
+func StaticTenants(tenants ...string) TenantSource {
+	return func(context.Context) ([]string, error) {
+		return tenants, nil
+	}
+}
+
+// QueryTenants returns a TenantSource that lists the values of the first
+// column of the rows query returns on db.
+func QueryTenants(db *sql.DB, query string) TenantSource {
+	return func(ctx context.Context) ([]string, error) {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var tenants []string
+		for rows.Next() {
+			var tenant string
+			if err := rows.Scan(&tenant); err != nil {
+				return nil, err
+			}
+			tenants = append(tenants, tenant)
+		}
+		return tenants, rows.Err()
+	}
+}
+
+// TenantOpener opens a connection to a tenant's schema or database. The
+// Migrator closes it once the tenant has been migrated.
+type TenantOpener func(ctx context.Context, tenant string) (*sql.DB, error)
+
+// TenantMode selects how NewTenantOpener points a connection at a tenant.
+type TenantMode string
+
+const (
+	// TenantSchema sets the PostgreSQL search_path to the tenant's schema,
+	// so that the tenant's tables and tracking table live in that schema.
+	TenantSchema TenantMode = "schema"
+	// TenantDatabase connects to the database named after the tenant.
+	TenantDatabase TenantMode = "database"
+)
+
+// tenantSchemaPattern matches the tenant names TenantSchema accepts: plain
+// PostgreSQL identifiers, which need no quoting in search_path or in the
+// connection string.
+var tenantSchemaPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// NewTenantOpener returns a TenantOpener that opens driver with base,
+// pointed at the tenant as selected by mode. With TenantSchema, a tenant
+// name that is not a plain identifier is rejected with
+// ErrInvalidTenantName.
+func NewTenantOpener(driver database.Driver, base database.ConnectionConfig, mode TenantMode) TenantOpener {
+	return func(_ context.Context, tenant string) (*sql.DB, error) {
+		cfg := base
+		if mode == TenantDatabase {
+			cfg.Database = tenant
+		} else {
+			if !tenantSchemaPattern.MatchString(tenant) {
+				return nil, fmt.Errorf("tenant %q: %w: a schema name must be a letter or underscore followed by up to 62 letters, digits or underscores", tenant, ErrInvalidTenantName)
+			}
+			cfg.Options = make(map[string]string, len(base.Options)+1)
+			for k, v := range base.Options {
+				cfg.Options[k] = v
+			}
+			cfg.Options["search_path"] = tenant
+		}
+		return driver.Open(cfg)
+	}
+}
+
+// WithTenants configures the tenants UpTenants and StatusTenants fan out
+// to and how a connection to each is opened.
+func WithTenants(source TenantSource, open TenantOpener) Option {
+	return func(m *Migrator) {
+		m.tenants = source
+		m.openTenant = open
+	}
+}
+
+// WithTenantParallelism sets how many tenants UpTenants and StatusTenants
+// work on at once (default: 1). With more than one, the ProgressFunc and
+// hooks may be called concurrently. Dry runs always work on one tenant at
+// a time so that their output is not interleaved.
+func WithTenantParallelism(n int) Option {
+	return func(m *Migrator) {
+		m.tenantParallelism = n
+	}
+}
+
+// TenantReport is the outcome of migrating one tenant.
+type TenantReport struct {
+	Tenant string
+	// Applied lists the migrations applied to the tenant, in order.
+	Applied  []string
+	Duration time.Duration
+	Err      error
+}
+
+// TenantStatus is the status of the migrations of one tenant.
+type TenantStatus struct {
+	Tenant   string
+	Statuses []MigrationStatus
+	Err      error
+}
+
+// Applied returns the number of migrations applied to the tenant.
+func (s TenantStatus) Applied() int {
+	n := 0
+	for _, st := range s.Statuses {
+		if st.Applied {
+			n++
+		}
+	}
+	return n
+}
+
+// Pending returns the number of migrations not yet applied to the tenant.
+func (s TenantStatus) Pending() int {
+	return len(s.Statuses) - s.Applied()
+}
+
+// UpTenants runs the pending migrations of every tenant, each tracked in
+// the tenant's own tracking table. A failing tenant does not stop the
+// others; the returned reports, in tenant order, describe each tenant and
+// the error wraps ErrTenantFailed when any tenant failed.
+//
+// Only migrations of the connection passed to New are run. The Locker is
+// acquired once, on that connection, around the whole fanout.
+func (m *Migrator) UpTenants() ([]TenantReport, error) {
+	return m.UpTenantsContext(context.Background())
+}
+
+// UpTenantsContext is like UpTenants but stops when ctx is cancelled.
+func (m *Migrator) UpTenantsContext(ctx context.Context) ([]TenantReport, error) {
+	tenants, err := m.listTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]TenantReport, len(tenants))
+	var errs []error
+	err = m.withLock(ctx, func() error {
+		var err error
+		errs, err = m.fanOut(ctx, tenants, func(i int, t *Migrator) error {
+			r := &reports[i]
+			progress := t.progressFn
+			t.progressFn = func(e ProgressEvent) {
+				r.Applied = append(r.Applied, e.Name)
+				if progress != nil {
+					progress(e)
+				}
+			}
+
+			start := time.Now()
+			defer func() { r.Duration = time.Since(start) }()
+			return t.up(ctx)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range reports {
+		reports[i].Tenant = tenants[i]
+		reports[i].Err = errs[i]
+	}
+	return reports, tenantsError(errs)
+}
+
+// StatusTenants returns the migration status of every tenant, in tenant
+// order. The error wraps ErrTenantFailed when the status of any tenant
+// could not be read.
+func (m *Migrator) StatusTenants() ([]TenantStatus, error) {
+	return m.StatusTenantsContext(context.Background())
+}
+
+// StatusTenantsContext is like StatusTenants but executes with ctx.
+func (m *Migrator) StatusTenantsContext(ctx context.Context) ([]TenantStatus, error) {
+	tenants, err := m.listTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]TenantStatus, len(tenants))
+	errs, err := m.fanOut(ctx, tenants, func(i int, t *Migrator) error {
+		var err error
+		statuses[i].Statuses, err = t.status(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range statuses {
+		statuses[i].Tenant = tenants[i]
+		statuses[i].Err = errs[i]
+	}
+	return statuses, tenantsError(errs)
+}
+
+// listTenants returns the tenants of the configured TenantSource.
+func (m *Migrator) listTenants(ctx context.Context) ([]string, error) {
+	if m.tenants == nil || m.openTenant == nil {
+		return nil, fmt.Errorf("tenant fanout requires tenants: configure with WithTenants")
+	}
+	tenants, err := m.tenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// fanOut calls fn with a Migrator for each tenant, running up to the
+// configured parallelism at once, and returns each tenant's error. fn
+// receives the tenant's index and a Migrator holding the migrations of the
+// connection passed to New.
+func (m *Migrator) fanOut(ctx context.Context, tenants []string, fn func(i int, t *Migrator) error) ([]error, error) {
+	targets, err := m.targets()
+	if err != nil {
+		return nil, err
+	}
+	base := targets[0]
+	if base.connName != m.connName {
+		return nil, fmt.Errorf("tenant fanout runs on connection %q only", m.connName)
+	}
+
+	parallelism := m.tenantParallelism
+	if parallelism < 1 || m.dryRun {
+		parallelism = 1
+	}
+
+	errs := make([]error, len(tenants))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	for i, tenant := range tenants {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = m.runTenant(ctx, base, tenant, func(t *Migrator) error { return fn(i, t) })
+		}()
+	}
+	wg.Wait()
+	return errs, nil
+}
+
+// runTenant opens the tenant's connection and calls fn with a Migrator for
+// it.
+func (m *Migrator) runTenant(ctx context.Context, base *Migrator, tenant string, fn func(t *Migrator) error) error {
+	db, err := m.openTenant(ctx, tenant)
+	if err != nil {
+		return fmt.Errorf("open tenant: %w", err)
+	}
+	defer db.Close()
+
+	t := base.derive(db, base.grammar, base.trackerDialect)
+	t.schemaDumpPath = base.schemaDumpPath
+	t.schemaDumper = base.schemaDumper
+	return fn(t)
+}
+
+// tenantsError returns an error wrapping ErrTenantFailed that counts the
+// tenants that failed, or nil when none did.
+func tenantsError(errs []error) error {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d tenants: %w", failed, len(errs), ErrTenantFailed)
+}
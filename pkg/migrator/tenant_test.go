@@ -0,0 +1,137 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/database"
+	"github.com/andrianprasetya/go-migration/pkg/database/drivers"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteTenants opens one SQLite database per tenant in dir. Opening
+// failOpen fails.
+type sqliteTenants struct {
+	dir      string
+	failOpen string
+}
+
+func (s *sqliteTenants) Open(_ context.Context, tenant string) (*sql.DB, error) {
+	if tenant == s.failOpen {
+		return nil, fmt.Errorf("no such tenant")
+	}
+	return sql.Open("sqlite3", filepath.Join(s.dir, tenant+".db"))
+}
+
+func (s *sqliteTenants) db(t *testing.T, tenant string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(s.dir, tenant+".db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUpTenants_MigratesEveryTenant(t *testing.T) {
+	tenants := &sqliteTenants{dir: t.TempDir()}
+	m := New(openSQLite(t),
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithTenants(StaticTenants("acme", "globex", "initech"), tenants.Open),
+		WithTenantParallelism(2),
+	)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	reports, err := m.UpTenants()
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+	for i, tenant := range []string{"acme", "globex", "initech"} {
+		assert.Equal(t, tenant, reports[i].Tenant)
+		assert.NoError(t, reports[i].Err)
+		assert.Equal(t, []string{"20240101000000_create_users"}, reports[i].Applied)
+		assert.True(t, hasSQLiteTable(t, tenants.db(t, tenant), "users"))
+	}
+
+	// A second run has nothing left to apply.
+	reports, err = m.UpTenants()
+	require.NoError(t, err)
+	for _, r := range reports {
+		assert.Empty(t, r.Applied)
+	}
+
+	statuses, err := m.StatusTenants()
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, 1, statuses[0].Applied())
+	assert.Equal(t, 0, statuses[0].Pending())
+}
+
+func TestUpTenants_ReportsFailingTenant(t *testing.T) {
+	tenants := &sqliteTenants{dir: t.TempDir(), failOpen: "globex"}
+	m := New(openSQLite(t),
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithTenants(StaticTenants("acme", "globex", "initech"), tenants.Open),
+	)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	reports, err := m.UpTenants()
+	assert.True(t, errors.Is(err, ErrTenantFailed))
+	assert.Contains(t, err.Error(), "1 of 3 tenants")
+	require.Len(t, reports, 3)
+	assert.NoError(t, reports[0].Err)
+	assert.Error(t, reports[1].Err)
+	assert.Equal(t, "globex", reports[1].Tenant)
+	assert.NoError(t, reports[2].Err, "the other tenants are still migrated")
+
+	statuses, err := m.StatusTenants()
+	assert.True(t, errors.Is(err, ErrTenantFailed))
+	assert.Error(t, statuses[1].Err)
+	assert.Equal(t, 1, statuses[2].Applied())
+}
+
+func TestUpTenants_QueryTenants(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE tenants (name TEXT); INSERT INTO tenants VALUES ('acme'), ('globex')`)
+	require.NoError(t, err)
+
+	tenants := &sqliteTenants{dir: t.TempDir()}
+	m := New(db,
+		WithGrammar(grammars.NewSQLiteGrammar()),
+		WithTenants(QueryTenants(db, `SELECT name FROM tenants ORDER BY name`), tenants.Open),
+	)
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	reports, err := m.UpTenants()
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, "globex", reports[1].Tenant)
+	assert.False(t, hasSQLiteTable(t, db, "users"), "the tenant list's database is not migrated")
+}
+
+func TestUpTenants_RequiresTenants(t *testing.T) {
+	_, err := New(openSQLite(t)).UpTenants()
+	assert.ErrorContains(t, err, "requires tenants")
+}
+
+func TestNewTenantOpener(t *testing.T) {
+	dir := t.TempDir()
+	open := NewTenantOpener(drivers.NewSQLiteDriver(), database.ConnectionConfig{Driver: "sqlite3"}, TenantDatabase)
+	db, err := open(context.Background(), filepath.Join(dir, "acme.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE t (id INTEGER)`)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "acme.db"))
+}
+
+func TestNewTenantOpener_RejectsSchemaNamesThatAreNotIdentifiers(t *testing.T) {
+	open := NewTenantOpener(drivers.NewPostgresDriver(), database.ConnectionConfig{Driver: "postgres"}, TenantSchema)
+	for _, tenant := range []string{"", "acme corp", "acme password=x", "a'b", "1acme", "public,pg_catalog"} {
+		_, err := open(context.Background(), tenant)
+		assert.ErrorIs(t, err, ErrInvalidTenantName, tenant)
+	}
+}
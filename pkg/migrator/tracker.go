@@ -1,57 +1,152 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // MigrationRecord represents a single row in the migration tracking table.
+// Checksum is empty for migrations applied before checksums were tracked.
+// Phase is the last phase a PhasedMigration completed and is empty for
+// other migrations.
 type MigrationRecord struct {
 	Name      string
 	Batch     int
 	CreatedAt time.Time
+	Checksum  string
+	Phase     Phase
+}
+
+// trackerExecutor runs the Tracker's statements. It is satisfied by both
+// *sql.DB and *sql.Tx, so that a migration can be recorded inside its
+// transaction.
+type trackerExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 // Tracker manages the migrations tracking table in the database.
 type Tracker struct {
-	db        *sql.DB
+	db        trackerExecutor
 	tableName string
+	dialect   TrackerDialect
 }
 
 // NewTracker creates a new Tracker that uses the given database connection
-// and stores records in the specified table.
+// and stores records in the specified table, using PostgreSQL SQL.
 func NewTracker(db *sql.DB, tableName string) *Tracker {
+	return NewTrackerWithDialect(db, tableName, PostgresTrackerDialect{})
+}
+
+// NewTrackerWithDialect creates a Tracker that generates its SQL with the
+// given dialect. A nil dialect falls back to PostgresTrackerDialect.
+func NewTrackerWithDialect(db *sql.DB, tableName string, dialect TrackerDialect) *Tracker {
+	if dialect == nil {
+		dialect = PostgresTrackerDialect{}
+	}
 	return &Tracker{
 		db:        db,
 		tableName: tableName,
+		dialect:   dialect,
 	}
 }
 
+// on returns a copy of t that executes through exec, such as the
+// transaction a migration runs in.
+func (t *Tracker) on(exec trackerExecutor) *Tracker {
+	c := *t
+	c.db = exec
+	return &c
+}
+
+// table returns the quoted tracking table name.
+func (t *Tracker) table() string {
+	return t.dialect.Quote(t.tableName)
+}
+
 // EnsureTable creates the migration tracking table if it does not already exist.
 // This operation is idempotent — calling it multiple times has no effect.
+// Tables created by earlier versions are upgraded with the checksum and
+// phase columns.
 func (t *Tracker) EnsureTable() error {
-	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
-		id         SERIAL PRIMARY KEY,
-		migration  VARCHAR(255) NOT NULL UNIQUE,
-		batch      INTEGER NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
-	)`, t.tableName)
-
-	if _, err := t.db.Exec(query); err != nil {
+	return t.EnsureTableContext(context.Background())
+}
+
+// EnsureTableContext is like EnsureTable but executes with ctx.
+func (t *Tracker) EnsureTableContext(ctx context.Context) error {
+	query := t.dialect.CompileCreateTable(t.table())
+
+	if _, err := t.db.ExecContext(ctx, query); err != nil {
 		return fmt.Errorf("ensure tracking table %q: %w", t.tableName, ErrTrackingTable)
 	}
+	if err := t.ensureColumn(ctx, "checksum", "VARCHAR(64)"); err != nil {
+		return err
+	}
+	return t.ensureColumn(ctx, "phase", "VARCHAR(16)")
+}
+
+// ensureColumn adds a column to tracking tables that predate it. The probe
+// selects no rows, so it is cheap on any table size. The column is only
+// added when the table's columns show it is missing; any other probe
+// failure, such as a lost connection or a missing privilege, is returned
+// with its cause.
+func (t *Tracker) ensureColumn(ctx context.Context, column, definition string) error {
+	probe := fmt.Sprintf(`SELECT %s FROM %s WHERE 1 = 0`, column, t.table())
+	rows, probeErr := t.db.QueryContext(ctx, probe)
+	if probeErr == nil {
+		return rows.Close()
+	}
+
+	exists, err := t.hasColumn(ctx, column)
+	if err != nil || exists {
+		return fmt.Errorf("check %s column of %q: %w: %w", column, t.tableName, ErrTrackingTable, probeErr)
+	}
+
+	alter := fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, t.table(), column, definition)
+	if _, err := t.db.ExecContext(ctx, alter); err != nil {
+		return fmt.Errorf("add %s column to %q: %w: %w", column, t.tableName, ErrTrackingTable, err)
+	}
 	return nil
 }
 
+// hasColumn reports whether the tracking table has the column, from the
+// column names of a query that selects no rows.
+func (t *Tracker) hasColumn(ctx context.Context, column string) (bool, error) {
+	rows, err := t.db.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %s WHERE 1 = 0`, t.table()))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	for _, c := range columns {
+		if strings.EqualFold(c, column) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // GetApplied returns all migration records ordered by name ascending.
 func (t *Tracker) GetApplied() ([]MigrationRecord, error) {
+	return t.GetAppliedContext(context.Background())
+}
+
+// GetAppliedContext is like GetApplied but executes with ctx.
+func (t *Tracker) GetAppliedContext(ctx context.Context) ([]MigrationRecord, error) {
 	query := fmt.Sprintf(
-		`SELECT migration, batch, created_at FROM %s ORDER BY migration ASC`,
-		t.tableName,
+		`SELECT migration, batch, created_at, checksum, phase FROM %s ORDER BY migration ASC`,
+		t.table(),
 	)
 
-	rows, err := t.db.Query(query)
+	rows, err := t.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("get applied migrations: %w", ErrTrackingTable)
 	}
@@ -59,9 +154,9 @@ func (t *Tracker) GetApplied() ([]MigrationRecord, error) {
 
 	var records []MigrationRecord
 	for rows.Next() {
-		var r MigrationRecord
-		if err := rows.Scan(&r.Name, &r.Batch, &r.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scan migration record: %w", ErrTrackingTable)
+		r, err := scanMigrationRecord(rows)
+		if err != nil {
+			return nil, err
 		}
 		records = append(records, r)
 	}
@@ -74,12 +169,17 @@ func (t *Tracker) GetApplied() ([]MigrationRecord, error) {
 // GetByBatch returns all migration records for the given batch number,
 // ordered by name ascending.
 func (t *Tracker) GetByBatch(batch int) ([]MigrationRecord, error) {
+	return t.GetByBatchContext(context.Background(), batch)
+}
+
+// GetByBatchContext is like GetByBatch but executes with ctx.
+func (t *Tracker) GetByBatchContext(ctx context.Context, batch int) ([]MigrationRecord, error) {
 	query := fmt.Sprintf(
-		`SELECT migration, batch, created_at FROM %s WHERE batch = $1 ORDER BY migration ASC`,
-		t.tableName,
+		`SELECT migration, batch, created_at, checksum, phase FROM %s WHERE batch = %s ORDER BY migration ASC`,
+		t.table(), t.dialect.Placeholder(1),
 	)
 
-	rows, err := t.db.Query(query, batch)
+	rows, err := t.db.QueryContext(ctx, query, batch)
 	if err != nil {
 		return nil, fmt.Errorf("get migrations for batch %d: %w", batch, ErrTrackingTable)
 	}
@@ -87,9 +187,9 @@ func (t *Tracker) GetByBatch(batch int) ([]MigrationRecord, error) {
 
 	var records []MigrationRecord
 	for rows.Next() {
-		var r MigrationRecord
-		if err := rows.Scan(&r.Name, &r.Batch, &r.CreatedAt); err != nil {
-			return nil, fmt.Errorf("scan migration record: %w", ErrTrackingTable)
+		r, err := scanMigrationRecord(rows)
+		if err != nil {
+			return nil, err
 		}
 		records = append(records, r)
 	}
@@ -102,40 +202,139 @@ func (t *Tracker) GetByBatch(batch int) ([]MigrationRecord, error) {
 // GetLastBatchNumber returns the highest batch number in the tracking table.
 // Returns 0 if no records exist.
 func (t *Tracker) GetLastBatchNumber() (int, error) {
+	return t.GetLastBatchNumberContext(context.Background())
+}
+
+// GetLastBatchNumberContext is like GetLastBatchNumber but executes with ctx.
+func (t *Tracker) GetLastBatchNumberContext(ctx context.Context) (int, error) {
 	query := fmt.Sprintf(
 		`SELECT COALESCE(MAX(batch), 0) FROM %s`,
-		t.tableName,
+		t.table(),
 	)
 
 	var batch int
-	if err := t.db.QueryRow(query).Scan(&batch); err != nil {
+	if err := t.db.QueryRowContext(ctx, query).Scan(&batch); err != nil {
 		return 0, fmt.Errorf("get last batch number: %w", ErrTrackingTable)
 	}
 	return batch, nil
 }
 
 // Record inserts a new migration record with the given name and batch number.
+// The record is stored without a checksum.
 func (t *Tracker) Record(name string, batch int) error {
+	return t.RecordContext(context.Background(), name, batch, "")
+}
+
+// RecordContext is like Record but executes with ctx and stores the
+// checksum of the migration's SQL. An empty checksum is stored as NULL.
+func (t *Tracker) RecordContext(ctx context.Context, name string, batch int, checksum string) error {
 	query := fmt.Sprintf(
-		`INSERT INTO %s (migration, batch) VALUES ($1, $2)`,
-		t.tableName,
+		`INSERT INTO %s (migration, batch, checksum) VALUES (%s, %s, %s)`,
+		t.table(), t.dialect.Placeholder(1), t.dialect.Placeholder(2), t.dialect.Placeholder(3),
 	)
 
-	if _, err := t.db.Exec(query, name, batch); err != nil {
+	sum := sql.NullString{String: checksum, Valid: checksum != ""}
+	if _, err := t.db.ExecContext(ctx, query, name, batch, sum); err != nil {
 		return fmt.Errorf("record migration %q: %w", name, ErrTrackingTable)
 	}
 	return nil
 }
 
+// RecordPhaseContext is like RecordContext but also stores the phase a
+// PhasedMigration reached.
+func (t *Tracker) RecordPhaseContext(ctx context.Context, name string, batch int, checksum string, phase Phase) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (migration, batch, checksum, phase) VALUES (%s, %s, %s, %s)`,
+		t.table(), t.dialect.Placeholder(1), t.dialect.Placeholder(2), t.dialect.Placeholder(3), t.dialect.Placeholder(4),
+	)
+
+	sum := sql.NullString{String: checksum, Valid: checksum != ""}
+	if _, err := t.db.ExecContext(ctx, query, name, batch, sum, string(phase)); err != nil {
+		return fmt.Errorf("record migration %q: %w", name, ErrTrackingTable)
+	}
+	return nil
+}
+
+// SetPhaseContext updates the phase recorded for an applied migration.
+func (t *Tracker) SetPhaseContext(ctx context.Context, name string, phase Phase) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET phase = %s WHERE migration = %s`,
+		t.table(), t.dialect.Placeholder(1), t.dialect.Placeholder(2),
+	)
+
+	if _, err := t.db.ExecContext(ctx, query, string(phase), name); err != nil {
+		return fmt.Errorf("set phase of migration %q: %w", name, ErrTrackingTable)
+	}
+	return nil
+}
+
 // Remove deletes a migration record by name.
 func (t *Tracker) Remove(name string) error {
+	return t.RemoveContext(context.Background(), name)
+}
+
+// RemoveContext is like Remove but executes with ctx.
+func (t *Tracker) RemoveContext(ctx context.Context, name string) error {
 	query := fmt.Sprintf(
-		`DELETE FROM %s WHERE migration = $1`,
-		t.tableName,
+		`DELETE FROM %s WHERE migration = %s`,
+		t.table(), t.dialect.Placeholder(1),
 	)
 
-	if _, err := t.db.Exec(query, name); err != nil {
+	if _, err := t.db.ExecContext(ctx, query, name); err != nil {
 		return fmt.Errorf("remove migration %q: %w", name, ErrTrackingTable)
 	}
 	return nil
 }
+
+// scanMigrationRecord scans one row of migration, batch, created_at,
+// checksum, phase.
+// created_at is scanned loosely because drivers differ in how they return
+// timestamps (MySQL returns text unless parseTime=true is set in the DSN).
+func scanMigrationRecord(rows *sql.Rows) (MigrationRecord, error) {
+	var r MigrationRecord
+	var createdAt any
+	var checksum, phase sql.NullString
+	if err := rows.Scan(&r.Name, &r.Batch, &createdAt, &checksum, &phase); err != nil {
+		return MigrationRecord{}, fmt.Errorf("scan migration record: %w", ErrTrackingTable)
+	}
+	t, err := parseTrackerTime(createdAt)
+	if err != nil {
+		return MigrationRecord{}, fmt.Errorf("scan migration record %q: %v: %w", r.Name, err, ErrTrackingTable)
+	}
+	r.CreatedAt = t
+	r.Checksum = checksum.String
+	r.Phase = Phase(phase.String)
+	return r, nil
+}
+
+// trackerTimeLayouts are the textual timestamp formats drivers return for
+// the created_at column.
+var trackerTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// parseTrackerTime converts a scanned created_at value to time.Time.
+func parseTrackerTime(v any) (time.Time, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case nil:
+		return time.Time{}, nil
+	case []byte:
+		return parseTrackerTime(string(x))
+	case string:
+		for _, layout := range trackerTimeLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognized timestamp %q", x)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", v)
+	}
+}
@@ -0,0 +1,127 @@
+package migrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+)
+
+// TrackerDialect supplies the database-specific SQL used by the Tracker:
+// the tracking table DDL, bind parameter style and identifier quoting.
+type TrackerDialect interface {
+	// CompileCreateTable returns a CREATE TABLE IF NOT EXISTS statement for
+	// the tracking table. The table name is already quoted.
+	CompileCreateTable(table string) string
+
+	// Placeholder returns the bind parameter for the n-th argument (1-based).
+	Placeholder(n int) string
+
+	// Quote quotes a table name. Dotted names are quoted per part.
+	Quote(name string) string
+}
+
+// trackerDialectMap maps database driver names to TrackerDialect implementations.
+var trackerDialectMap = map[string]TrackerDialect{
+	"postgres": PostgresTrackerDialect{},
+	"mysql":    MySQLTrackerDialect{},
+	"sqlite":   SQLiteTrackerDialect{},
+	"sqlite3":  SQLiteTrackerDialect{},
+}
+
+// ResolveTrackerDialect returns the TrackerDialect for the given database
+// driver name. It returns an error if the driver is not recognized.
+func ResolveTrackerDialect(driver string) (TrackerDialect, error) {
+	d, ok := trackerDialectMap[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver %q", driver)
+	}
+	return d, nil
+}
+
+// trackerDialectForGrammar returns the TrackerDialect matching one of the
+// built-in grammars, or nil for grammars it does not know.
+func trackerDialectForGrammar(g schema.Grammar) TrackerDialect {
+	switch g.(type) {
+	case *grammars.PostgresGrammar:
+		return PostgresTrackerDialect{}
+	case *grammars.MySQLGrammar:
+		return MySQLTrackerDialect{}
+	case *grammars.SQLiteGrammar:
+		return SQLiteTrackerDialect{}
+	default:
+		return nil
+	}
+}
+
+// quoteParts quotes each dot-separated part of name with q, doubling any
+// embedded quote characters.
+func quoteParts(name string, q string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = q + strings.ReplaceAll(p, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
+
+// --- PostgreSQL ---
+
+// PostgresTrackerDialect is the TrackerDialect for PostgreSQL. It is the
+// default when no dialect or known grammar is configured.
+type PostgresTrackerDialect struct{}
+
+func (PostgresTrackerDialect) CompileCreateTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id         SERIAL PRIMARY KEY,
+		migration  VARCHAR(255) NOT NULL UNIQUE,
+		batch      INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		checksum   VARCHAR(64),
+		phase      VARCHAR(16)
+	)`, table)
+}
+
+func (PostgresTrackerDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (PostgresTrackerDialect) Quote(name string) string { return quoteParts(name, `"`) }
+
+// --- MySQL ---
+
+// MySQLTrackerDialect is the TrackerDialect for MySQL and MariaDB.
+type MySQLTrackerDialect struct{}
+
+func (MySQLTrackerDialect) CompileCreateTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id         INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+		migration  VARCHAR(255) NOT NULL UNIQUE,
+		batch      INT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		checksum   VARCHAR(64),
+		phase      VARCHAR(16)
+	)`, table)
+}
+
+func (MySQLTrackerDialect) Placeholder(int) string { return "?" }
+
+func (MySQLTrackerDialect) Quote(name string) string { return quoteParts(name, "`") }
+
+// --- SQLite ---
+
+// SQLiteTrackerDialect is the TrackerDialect for SQLite.
+type SQLiteTrackerDialect struct{}
+
+func (SQLiteTrackerDialect) CompileCreateTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		migration  VARCHAR(255) NOT NULL UNIQUE,
+		batch      INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		checksum   VARCHAR(64),
+		phase      VARCHAR(16)
+	)`, table)
+}
+
+func (SQLiteTrackerDialect) Placeholder(int) string { return "?" }
+
+func (SQLiteTrackerDialect) Quote(name string) string { return quoteParts(name, `"`) }
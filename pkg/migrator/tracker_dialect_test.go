@@ -0,0 +1,220 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTrackerDialect(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   TrackerDialect
+	}{
+		{"postgres", PostgresTrackerDialect{}},
+		{"mysql", MySQLTrackerDialect{}},
+		{"sqlite", SQLiteTrackerDialect{}},
+		{"sqlite3", SQLiteTrackerDialect{}},
+	}
+	for _, tt := range tests {
+		d, err := ResolveTrackerDialect(tt.driver)
+		require.NoError(t, err, tt.driver)
+		assert.Equal(t, tt.want, d, tt.driver)
+	}
+
+	_, err := ResolveTrackerDialect("oracle")
+	assert.Error(t, err)
+}
+
+func TestTrackerDialect_Quote(t *testing.T) {
+	assert.Equal(t, `"migrations"`, PostgresTrackerDialect{}.Quote("migrations"))
+	assert.Equal(t, `"app"."migrations"`, PostgresTrackerDialect{}.Quote("app.migrations"))
+	assert.Equal(t, "`migrations`", MySQLTrackerDialect{}.Quote("migrations"))
+	assert.Equal(t, "`odd``name`", MySQLTrackerDialect{}.Quote("odd`name"))
+	assert.Equal(t, `"migrations"`, SQLiteTrackerDialect{}.Quote("migrations"))
+}
+
+func TestNew_DerivesTrackerDialectFromGrammar(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, PostgresTrackerDialect{}, New(db).tracker.dialect)
+	assert.Equal(t, MySQLTrackerDialect{}, New(db, WithGrammar(grammars.NewMySQLGrammar())).tracker.dialect)
+	assert.Equal(t, SQLiteTrackerDialect{}, New(db, WithGrammar(grammars.NewSQLiteGrammar())).tracker.dialect)
+
+	// An explicit dialect wins regardless of option order.
+	m := New(db, WithTrackerDialect(SQLiteTrackerDialect{}), WithGrammar(grammars.NewMySQLGrammar()))
+	assert.Equal(t, SQLiteTrackerDialect{}, m.tracker.dialect)
+}
+
+func TestTracker_MySQLDialect_UsesQuestionMarkPlaceholders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO `migrations` \\(migration, batch, checksum\\) VALUES \\(\\?, \\?, \\?\\)").
+		WithArgs("20240101000000_create_users", 1, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM `migrations` WHERE migration = \\?").
+		WithArgs("20240101000000_create_users").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tracker := NewTrackerWithDialect(db, "migrations", MySQLTrackerDialect{})
+	require.NoError(t, tracker.Record("20240101000000_create_users", 1))
+	require.NoError(t, tracker.Remove("20240101000000_create_users"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParseTrackerTime(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	got, err := parseTrackerTime(want)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// MySQL without parseTime=true returns DATETIME/TIMESTAMP as text.
+	got, err = parseTrackerTime([]byte("2024-01-15 10:30:00"))
+	require.NoError(t, err)
+	assert.True(t, want.Equal(got))
+
+	_, err = parseTrackerTime("yesterday")
+	assert.Error(t, err)
+}
+
+// TestTracker_SQLite runs every tracking operation against a real SQLite
+// database with the SQLite dialect.
+func TestTracker_SQLite(t *testing.T) {
+	db := openSQLite(t)
+	testTrackerLifecycle(t, db, SQLiteTrackerDialect{})
+}
+
+// TestTracker_MySQLDialectSQL runs the MySQL dialect's SQL (backtick
+// quoting, ? placeholders, AUTO_INCREMENT) on SQLite, whose parser accepts
+// that syntax, so the statements are executed without a MySQL server.
+func TestTracker_MySQLDialectSQL(t *testing.T) {
+	db := openSQLite(t)
+	testTrackerLifecycle(t, db, MySQLTrackerDialect{})
+}
+
+// TestTracker_MySQL runs against a real MySQL server when
+// GOMIGRATE_TEST_MYSQL_DSN is set, e.g. "root:secret@tcp(127.0.0.1:3306)/test".
+func TestTracker_MySQL(t *testing.T) {
+	dsn := os.Getenv("GOMIGRATE_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GOMIGRATE_TEST_MYSQL_DSN not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec("DROP TABLE IF EXISTS `tracker_test_migrations`")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec("DROP TABLE IF EXISTS `tracker_test_migrations`") })
+
+	testTrackerLifecycleTable(t, db, MySQLTrackerDialect{}, "tracker_test_migrations")
+}
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tracker.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testTrackerLifecycle(t *testing.T, db *sql.DB, dialect TrackerDialect) {
+	t.Helper()
+	testTrackerLifecycleTable(t, db, dialect, "migrations")
+}
+
+func testTrackerLifecycleTable(t *testing.T, db *sql.DB, dialect TrackerDialect, table string) {
+	t.Helper()
+	ctx := context.Background()
+	tracker := NewTrackerWithDialect(db, table, dialect)
+
+	require.NoError(t, tracker.EnsureTable())
+	require.NoError(t, tracker.EnsureTable(), "EnsureTable must be idempotent")
+
+	last, err := tracker.GetLastBatchNumber()
+	require.NoError(t, err)
+	assert.Equal(t, 0, last)
+
+	require.NoError(t, tracker.RecordContext(ctx, "20240101000000_create_users", 1, "sum-users"))
+	require.NoError(t, tracker.RecordContext(ctx, "20240102000000_create_posts", 1, ""))
+	require.NoError(t, tracker.RecordContext(ctx, "20240103000000_create_tags", 2, "sum-tags"))
+	assert.Error(t, tracker.Record("20240101000000_create_users", 3), "migration names are unique")
+
+	applied, err := tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 3)
+	assert.Equal(t, "20240101000000_create_users", applied[0].Name)
+	assert.Equal(t, "sum-users", applied[0].Checksum)
+	assert.Equal(t, "", applied[1].Checksum)
+	assert.False(t, applied[0].CreatedAt.IsZero())
+
+	batch, err := tracker.GetByBatch(1)
+	require.NoError(t, err)
+	require.Len(t, batch, 2)
+	assert.Equal(t, "20240102000000_create_posts", batch[1].Name)
+
+	last, err = tracker.GetLastBatchNumber()
+	require.NoError(t, err)
+	assert.Equal(t, 2, last)
+
+	require.NoError(t, tracker.Remove("20240103000000_create_tags"))
+	applied, err = tracker.GetApplied()
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+}
+
+func TestTracker_SQLite_UpgradesLegacyTable(t *testing.T) {
+	db := openSQLite(t)
+
+	_, err := db.Exec(`CREATE TABLE migrations (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		migration  VARCHAR(255) NOT NULL UNIQUE,
+		batch      INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO migrations (migration, batch) VALUES ('20240101000000_legacy', 1)`)
+	require.NoError(t, err)
+
+	tracker := NewTrackerWithDialect(db, "migrations", SQLiteTrackerDialect{})
+	require.NoError(t, tracker.EnsureTable())
+
+	applied, err := tracker.GetApplied()
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "20240101000000_legacy", applied[0].Name)
+	assert.Empty(t, applied[0].Checksum)
+}
+
+func TestMigrator_SQLite_UpStatusRollback(t *testing.T) {
+	db := openSQLite(t)
+	m := New(db, WithGrammar(grammars.NewSQLiteGrammar()))
+	require.NoError(t, m.Register("20240101000000_create_users", &createTableMigration{}))
+
+	require.NoError(t, m.Up())
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].Modified)
+
+	require.NoError(t, m.Rollback(0))
+	statuses, err = m.Status()
+	require.NoError(t, err)
+	assert.False(t, statuses[0].Applied)
+}
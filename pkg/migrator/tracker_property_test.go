@@ -24,8 +24,12 @@ func TestProperty12_EnsureTableIsIdempotent(t *testing.T) {
 
 		// Expect EnsureTable to be called n times, all succeeding.
 		for i := 0; i < n; i++ {
-			mock.ExpectExec("CREATE TABLE IF NOT EXISTS migrations").
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"migrations\"").
 				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT checksum FROM \"migrations\"").
+				WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+			mock.ExpectQuery("SELECT phase FROM \"migrations\"").
+				WillReturnRows(sqlmock.NewRows([]string{"phase"}))
 		}
 
 		tracker := NewTracker(db, "migrations")
@@ -78,12 +82,12 @@ func TestProperty14_QueryByBatchReturnsCorrectSubset(t *testing.T) {
 		defer db.Close()
 
 		// Mock the DB to return only the expected subset (simulating the WHERE clause).
-		rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"})
+		rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"})
 		for _, r := range expected {
-			rows.AddRow(r.name, r.batch, now)
+			rows.AddRow(r.name, r.batch, now, nil, nil)
 		}
 
-		mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1 ORDER BY migration ASC").
+		mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1 ORDER BY migration ASC").
 			WithArgs(targetBatch).
 			WillReturnRows(rows)
 
@@ -1,6 +1,7 @@
 package migrator
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -24,8 +25,12 @@ func TestEnsureTable_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS migrations").
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"migrations\"").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM \"migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectQuery("SELECT phase FROM \"migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"phase"}))
 
 	tracker := NewTracker(db, "migrations")
 	err = tracker.EnsureTable()
@@ -38,7 +43,7 @@ func TestEnsureTable_Error(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS migrations").
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"migrations\"").
 		WillReturnError(errors.New("db error"))
 
 	tracker := NewTracker(db, "migrations")
@@ -52,8 +57,12 @@ func TestEnsureTable_CustomTableName(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS custom_migrations").
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"custom_migrations\"").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM \"custom_migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"checksum"}))
+	mock.ExpectQuery("SELECT phase FROM \"custom_migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"phase"}))
 
 	tracker := NewTracker(db, "custom_migrations")
 	err = tracker.EnsureTable()
@@ -61,17 +70,73 @@ func TestEnsureTable_CustomTableName(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestEnsureTable_AddsMissingColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"migrations\"").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM \"migrations\" WHERE 1 = 0").
+		WillReturnError(errors.New(`column "checksum" does not exist`))
+	mock.ExpectQuery("SELECT \\* FROM \"migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "migration", "batch", "created_at"}))
+	mock.ExpectExec("ALTER TABLE \"migrations\" ADD COLUMN checksum VARCHAR\\(64\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT phase FROM \"migrations\" WHERE 1 = 0").
+		WillReturnError(errors.New(`column "phase" does not exist`))
+	mock.ExpectQuery("SELECT \\* FROM \"migrations\" WHERE 1 = 0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "migration", "batch", "created_at", "checksum"}))
+	mock.ExpectExec("ALTER TABLE \"migrations\" ADD COLUMN phase VARCHAR\\(16\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tracker := NewTracker(db, "migrations")
+	err = tracker.EnsureTable()
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnsureTable_ProbeErrorIsNotAMissingColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	probeErr := errors.New("permission denied for table migrations")
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS \"migrations\"").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT checksum FROM \"migrations\" WHERE 1 = 0").
+		WillReturnError(probeErr)
+	mock.ExpectQuery("SELECT \\* FROM \"migrations\" WHERE 1 = 0").
+		WillReturnError(probeErr)
+
+	err = NewTracker(db, "migrations").EnsureTable()
+	assert.ErrorIs(t, err, ErrTrackingTable)
+	assert.ErrorIs(t, err, probeErr)
+	assert.NoError(t, mock.ExpectationsWereMet(), "no ALTER is attempted")
+}
+
+func TestEnsureTable_SQLiteUpgradesLegacyTable(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE migrations (id INTEGER PRIMARY KEY AUTOINCREMENT, migration VARCHAR(255) NOT NULL, batch INTEGER NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+
+	tracker := NewTrackerWithDialect(db, "migrations", SQLiteTrackerDialect{})
+	require.NoError(t, tracker.EnsureTable())
+	require.NoError(t, tracker.EnsureTable(), "existing columns are not added again")
+	require.NoError(t, tracker.RecordPhaseContext(context.Background(), "20240101000000_create_users", 1, "abc", PhaseExpand))
+}
+
 func TestGetApplied_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
 	now := time.Now().Truncate(time.Second)
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-		AddRow("20240115000000_first", 1, now).
-		AddRow("20240215000000_second", 1, now)
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+		AddRow("20240115000000_first", 1, now, nil, nil).
+		AddRow("20240215000000_second", 1, now, nil, nil)
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
 		WillReturnRows(rows)
 
 	tracker := NewTracker(db, "migrations")
@@ -89,8 +154,8 @@ func TestGetApplied_Empty(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"})
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations ORDER BY migration ASC").
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"})
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" ORDER BY migration ASC").
 		WillReturnRows(rows)
 
 	tracker := NewTracker(db, "migrations")
@@ -104,7 +169,7 @@ func TestGetApplied_QueryError(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\"").
 		WillReturnError(errors.New("db error"))
 
 	tracker := NewTracker(db, "migrations")
@@ -119,11 +184,11 @@ func TestGetByBatch_Success(t *testing.T) {
 	defer db.Close()
 
 	now := time.Now().Truncate(time.Second)
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"}).
-		AddRow("20240115000000_first", 2, now).
-		AddRow("20240215000000_second", 2, now)
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"}).
+		AddRow("20240115000000_first", 2, now, nil, nil).
+		AddRow("20240215000000_second", 2, now, nil, nil)
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1 ORDER BY migration ASC").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1 ORDER BY migration ASC").
 		WithArgs(2).
 		WillReturnRows(rows)
 
@@ -141,8 +206,8 @@ func TestGetByBatch_Empty(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at"})
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1").
+	rows := sqlmock.NewRows([]string{"migration", "batch", "created_at", "checksum", "phase"})
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1").
 		WithArgs(99).
 		WillReturnRows(rows)
 
@@ -157,7 +222,7 @@ func TestGetByBatch_QueryError(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT migration, batch, created_at FROM migrations WHERE batch = \\$1").
+	mock.ExpectQuery("SELECT migration, batch, created_at, checksum, phase FROM \"migrations\" WHERE batch = \\$1").
 		WithArgs(1).
 		WillReturnError(errors.New("db error"))
 
@@ -173,7 +238,7 @@ func TestGetLastBatchNumber_WithRecords(t *testing.T) {
 	defer db.Close()
 
 	rows := sqlmock.NewRows([]string{"max"}).AddRow(3)
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(rows)
 
 	tracker := NewTracker(db, "migrations")
@@ -188,7 +253,7 @@ func TestGetLastBatchNumber_NoRecords(t *testing.T) {
 	defer db.Close()
 
 	rows := sqlmock.NewRows([]string{"max"}).AddRow(0)
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnRows(rows)
 
 	tracker := NewTracker(db, "migrations")
@@ -202,7 +267,7 @@ func TestGetLastBatchNumber_Error(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM migrations").
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(batch\\), 0\\) FROM \"migrations\"").
 		WillReturnError(errors.New("db error"))
 
 	tracker := NewTracker(db, "migrations")
@@ -216,8 +281,8 @@ func TestRecord_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("INSERT INTO migrations").
-		WithArgs("20240115000000_create_users", 1).
+	mock.ExpectExec("INSERT INTO \"migrations\"").
+		WithArgs("20240115000000_create_users", 1, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	tracker := NewTracker(db, "migrations")
@@ -226,13 +291,47 @@ func TestRecord_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestRecordContext_StoresChecksum(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO \"migrations\" \\(migration, batch, checksum\\)").
+		WithArgs("20240115000000_create_users", 1, "abc123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tracker := NewTracker(db, "migrations")
+	err = tracker.RecordContext(context.Background(), "20240115000000_create_users", 1, "abc123")
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordPhaseContext_StoresPhase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO \"migrations\" \\(migration, batch, checksum, phase\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\)").
+		WithArgs("20240115000000_split_name", 1, "abc123", "expand").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE \"migrations\" SET phase = \\$1 WHERE migration = \\$2").
+		WithArgs("backfill", "20240115000000_split_name").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tracker := NewTracker(db, "migrations")
+	ctx := context.Background()
+	require.NoError(t, tracker.RecordPhaseContext(ctx, "20240115000000_split_name", 1, "abc123", PhaseExpand))
+	require.NoError(t, tracker.SetPhaseContext(ctx, "20240115000000_split_name", PhaseBackfill))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRecord_Error(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("INSERT INTO migrations").
-		WithArgs("20240115000000_create_users", 1).
+	mock.ExpectExec("INSERT INTO \"migrations\"").
+		WithArgs("20240115000000_create_users", 1, nil).
 		WillReturnError(errors.New("duplicate key"))
 
 	tracker := NewTracker(db, "migrations")
@@ -247,7 +346,7 @@ func TestRemove_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("DELETE FROM migrations WHERE migration = \\$1").
+	mock.ExpectExec("DELETE FROM \"migrations\" WHERE migration = \\$1").
 		WithArgs("20240115000000_create_users").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -262,7 +361,7 @@ func TestRemove_Error(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	mock.ExpectExec("DELETE FROM migrations WHERE migration = \\$1").
+	mock.ExpectExec("DELETE FROM \"migrations\" WHERE migration = \\$1").
 		WithArgs("20240115000000_create_users").
 		WillReturnError(errors.New("db error"))
 
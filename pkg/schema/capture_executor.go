@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Compile-time check that CaptureExecutor implements Executor.
+var _ Executor = (*CaptureExecutor)(nil)
+
+// CaptureExecutor implements Executor by collecting statements instead of
+// executing them. Read queries such as HasTable and Inspector lookups are
+// forwarded to Reader so that a migration compiles the same SQL it would
+// produce when run.
+type CaptureExecutor struct {
+	Reader     Executor
+	Statements []string
+}
+
+func (c *CaptureExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	c.Statements = append(c.Statements, query)
+	return nil, nil
+}
+
+func (c *CaptureExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.Reader.QueryRowContext(ctx, query, args...)
+}
+
+// QueryContext forwards to Reader when it can run queries that return rows.
+func (c *CaptureExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	q, ok := c.Reader.(Queryer)
+	if !ok {
+		return nil, fmt.Errorf("executor %T: %w", c.Reader, ErrInspectionUnsupported)
+	}
+	return q.QueryContext(ctx, query, args...)
+}
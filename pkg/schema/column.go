@@ -82,6 +82,7 @@ type ColumnDefinition struct {
 	IsUnsigned      bool
 	IsAutoIncrement bool
 	AllowedValues   []string
+	IsChange        bool
 }
 
 // Nullable marks the column as nullable.
@@ -129,3 +130,12 @@ func (cd *ColumnDefinition) AutoIncrement() *ColumnDefinition {
 	cd.IsAutoIncrement = true
 	return cd
 }
+
+// Change marks the column as a modification of an existing column instead
+// of a new one. Inside Builder.Alter the definition replaces the column's
+// type, nullability and default as a whole, so modifiers that should be
+// kept must be repeated.
+func (cd *ColumnDefinition) Change() *ColumnDefinition {
+	cd.IsChange = true
+	return cd
+}
@@ -54,3 +54,11 @@ func TestColumnDefinition_AllModifiers(t *testing.T) {
 	assert.Equal(t, 0, col.DefaultValue)
 	assert.True(t, col.IsNullable)
 }
+
+func TestColumnDefinition_Change(t *testing.T) {
+	col := &ColumnDefinition{Name: "name", Type: TypeString}
+	result := col.Change()
+
+	assert.Same(t, col, result)
+	assert.True(t, col.IsChange)
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// declaredRegistry stores the desired table definitions registered via
+// Declare, keyed by table name.
+var declaredRegistry = struct {
+	sync.Mutex
+	tables map[string]func(*Blueprint)
+}{tables: make(map[string]func(*Blueprint))}
+
+// Declare registers the desired definition of a table. Declarations are
+// the input of Inspector.Diff and make:migration --auto, which generate a
+// migration converging the live database on them. Intended to be called
+// from init() functions. Panics if the table is declared twice.
+func Declare(table string, fn func(*Blueprint)) {
+	declaredRegistry.Lock()
+	defer declaredRegistry.Unlock()
+	if _, ok := declaredRegistry.tables[table]; ok {
+		panic(fmt.Sprintf("Declare: duplicate table %q", table))
+	}
+	declaredRegistry.tables[table] = fn
+}
+
+// DeclaredTables builds a Blueprint for every declared table, sorted by
+// table name.
+func DeclaredTables() []*Blueprint {
+	declaredRegistry.Lock()
+	defer declaredRegistry.Unlock()
+
+	names := make([]string, 0, len(declaredRegistry.tables))
+	for name := range declaredRegistry.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	blueprints := make([]*Blueprint, len(names))
+	for i, name := range names {
+		bp := NewBlueprint(name)
+		declaredRegistry.tables[name](bp)
+		blueprints[i] = bp
+	}
+	return blueprints
+}
+
+// ResetDeclared clears all declarations (for testing only).
+func ResetDeclared() {
+	declaredRegistry.Lock()
+	defer declaredRegistry.Unlock()
+	declaredRegistry.tables = make(map[string]func(*Blueprint))
+}
@@ -0,0 +1,460 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaDiff lists the changes that converge a live database on a set of
+// declared tables. Tables that exist in the database but are not declared
+// are left alone.
+type SchemaDiff struct {
+	// Tables holds one entry per declared table that needs a change. New
+	// tables come first, ordered so that referenced tables are created
+	// before the tables whose foreign keys point at them.
+	Tables []TableDiff
+
+	// Warnings describes live structures that could not be represented
+	// exactly, e.g. a dropped column whose type has no Blueprint method.
+	Warnings []string
+}
+
+// Empty reports whether the database already matches the declarations.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Tables) == 0
+}
+
+// TableDiff describes the changes to a single table.
+type TableDiff struct {
+	Table string
+
+	// Create holds the declared table when it does not exist yet; the
+	// other fields are empty in that case.
+	Create *Blueprint
+
+	AddColumns     []ColumnDefinition
+	AddIndexes     []IndexDefinition
+	AddForeignKeys []ForeignKeyDefinition
+
+	// ChangeColumns holds the declared columns whose type, nullability or
+	// default differ from the live column.
+	ChangeColumns []ColumnChange
+
+	// The Drop fields describe live structures that are not declared, as
+	// reconstructed from the database so that they can be restored.
+	DropColumns     []ColumnDefinition
+	DropIndexes     []IndexDefinition
+	DropForeignKeys []ForeignKeyDefinition
+}
+
+// ColumnChange describes a column whose declared definition differs from
+// its live one. Both definitions are marked as changes.
+type ColumnChange struct {
+	// From is the live definition, as reconstructed from the database so
+	// that it can be restored.
+	From ColumnDefinition
+	// To is the declared definition.
+	To ColumnDefinition
+}
+
+func (t *TableDiff) empty() bool {
+	return t.Create == nil &&
+		len(t.AddColumns) == 0 && len(t.AddIndexes) == 0 && len(t.AddForeignKeys) == 0 &&
+		len(t.ChangeColumns) == 0 &&
+		len(t.DropColumns) == 0 && len(t.DropIndexes) == 0 && len(t.DropForeignKeys) == 0
+}
+
+// Diff compares the declared tables against the live database and
+// returns the changes needed to make the database match them. Columns,
+// indexes and foreign keys are matched by name; the type, nullability and
+// default of columns that exist on both sides are compared too. Primary
+// key columns are never changed.
+func (i *Inspector) Diff(declared []*Blueprint) (*SchemaDiff, error) {
+	tables, err := i.Tables()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]struct{}, len(tables))
+	for _, t := range tables {
+		existing[t] = struct{}{}
+	}
+
+	diff := &SchemaDiff{}
+	var creates, alters []TableDiff
+	for _, bp := range declared {
+		if _, ok := existing[bp.Table()]; !ok {
+			creates = append(creates, TableDiff{Table: bp.Table(), Create: bp})
+			continue
+		}
+		live, err := i.Table(bp.Table())
+		if err != nil {
+			return nil, err
+		}
+		td := diffTable(i.types, bp, live, &diff.Warnings)
+		if !td.empty() {
+			alters = append(alters, td)
+		}
+	}
+
+	diff.Tables = append(orderCreates(creates), alters...)
+	return diff, nil
+}
+
+// diffTable compares one declared table against its live counterpart.
+// types compiles declared column types for comparison with live ones.
+func diffTable(types columnTyper, bp *Blueprint, live *TableInfo, warnings *[]string) TableDiff {
+	td := TableDiff{Table: bp.Table()}
+
+	declaredCols := make(map[string]ColumnDefinition)
+	for _, col := range bp.Columns() {
+		declaredCols[col.Name] = col
+		info, ok := live.Column(col.Name)
+		if !ok {
+			td.AddColumns = append(td.AddColumns, col)
+			continue
+		}
+		if !columnChanged(types, col, info) {
+			continue
+		}
+		if col.IsPrimary || info.PrimaryKey {
+			*warnings = append(*warnings, fmt.Sprintf("%s.%s: primary key column differs from its declaration; it is not changed", bp.Table(), col.Name))
+			continue
+		}
+		from, ok := ColumnFromInfo(info)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s.%s: type %q has no Blueprint equivalent; it is restored as text", bp.Table(), info.Name, info.Type))
+		}
+		from.IsChange = true
+		to := col
+		to.IsChange = true
+		td.ChangeColumns = append(td.ChangeColumns, ColumnChange{From: from, To: to})
+	}
+	for _, info := range live.Columns {
+		if _, ok := declaredCols[info.Name]; ok {
+			continue
+		}
+		col, ok := ColumnFromInfo(info)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s.%s: type %q has no Blueprint equivalent; it is restored as text", bp.Table(), info.Name, info.Type))
+		}
+		td.DropColumns = append(td.DropColumns, col)
+	}
+
+	liveKeys := make(map[string]struct{})
+	for _, fk := range live.ForeignKeys {
+		liveKeys[fk.Name] = struct{}{}
+	}
+	declaredKeys := make(map[string]struct{})
+	for _, fk := range bp.ForeignKeys() {
+		declaredKeys[fk.Name] = struct{}{}
+		if _, ok := liveKeys[fk.Name]; !ok {
+			td.AddForeignKeys = append(td.AddForeignKeys, fk)
+		}
+	}
+	for _, fk := range live.ForeignKeys {
+		if _, ok := declaredKeys[fk.Name]; ok {
+			continue
+		}
+		if len(fk.Columns) != 1 {
+			*warnings = append(*warnings, fmt.Sprintf("%s: composite foreign key %q is not declared but cannot be represented; it is kept", bp.Table(), fk.Name))
+			continue
+		}
+		td.DropForeignKeys = append(td.DropForeignKeys, ForeignKeyDefinition{
+			Name:      fk.Name,
+			Column:    fk.Columns[0],
+			RefTable:  fk.ReferencedTable,
+			RefColumn: fk.ReferencedColumns[0],
+			OnDelete:  referentialAction(fk.OnDelete),
+			OnUpdate:  referentialAction(fk.OnUpdate),
+		})
+	}
+
+	declaredIndexes := make(map[string]struct{})
+	for _, idx := range bp.Indexes() {
+		declaredIndexes[idx.Name] = struct{}{}
+		if _, ok := live.Index(idx.Name); !ok {
+			td.AddIndexes = append(td.AddIndexes, idx)
+		}
+	}
+	for _, idx := range live.Indexes {
+		if _, ok := declaredIndexes[idx.Name]; ok {
+			continue
+		}
+		if implicitIndex(idx, declaredCols, liveKeys) {
+			continue
+		}
+		def := IndexDefinition{Name: idx.Name, Columns: idx.Columns, Type: IndexRegular}
+		if idx.Unique {
+			def.Type = IndexUnique
+		}
+		td.DropIndexes = append(td.DropIndexes, def)
+	}
+
+	return td
+}
+
+// columnTyper compiles the database type of a column; every Grammar
+// implements it.
+type columnTyper interface {
+	CompileColumnType(col ColumnDefinition) (string, error)
+}
+
+// columnChanged reports whether a declared column differs from the live
+// column in type, nullability or default. The declared type is compiled
+// and read back like a live one, so that both sides use the database's
+// spelling of it; types is nil when the grammar cannot compile types, in
+// which case they are not compared.
+func columnChanged(types columnTyper, col ColumnDefinition, info ColumnInfo) bool {
+	if types != nil {
+		// The primary key clauses some grammars compile into the type
+		// are not part of it.
+		plain := col
+		plain.IsPrimary, plain.IsAutoIncrement = false, false
+		if compiled, err := types.CompileColumnType(plain); err == nil {
+			want, _ := ColumnFromInfo(ColumnInfo{Name: col.Name, Type: compiled})
+			have, _ := ColumnFromInfo(info)
+			if !sameType(want, have) {
+				return true
+			}
+		}
+	}
+	// SQLite reports INTEGER PRIMARY KEY columns as nullable.
+	if !col.IsPrimary && !info.PrimaryKey && col.IsNullable != info.Nullable {
+		return true
+	}
+	if col.IsAutoIncrement || info.AutoIncrement {
+		return false
+	}
+	want, declared := declaredDefault(col.DefaultValue)
+	have, live := "", info.Default != nil
+	if live {
+		have = comparableDefault(*info.Default)
+	}
+	return declared != live || want != have
+}
+
+// sameType reports whether two columns read back from database types have
+// the same type. MySQL 8 reports tinyint(1) as tinyint.
+func sameType(a, b ColumnDefinition) bool {
+	if a.Type == TypeBoolean && b.Type == TypeTinyInt || a.Type == TypeTinyInt && b.Type == TypeBoolean {
+		return true
+	}
+	if a.Type != b.Type || a.Length != b.Length || a.Precision != b.Precision || a.Scale != b.Scale {
+		return false
+	}
+	return len(a.AllowedValues) == 0 || len(b.AllowedValues) == 0 || strings.Join(a.AllowedValues, "\x00") == strings.Join(b.AllowedValues, "\x00")
+}
+
+// declaredDefault renders a declared default like comparableDefault
+// renders a live one, and reports whether the column has one.
+func declaredDefault(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case RawExpression:
+		return comparableDefault(v.Expression), true
+	case string:
+		return v, true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// comparableDefault normalizes a default expression as reported by the
+// database: casts and parentheses are removed, string literals unquoted
+// (MySQL reports them unquoted), other expressions lowercased and
+// booleans reported as 1 or 0.
+func comparableDefault(def string) string {
+	def = normalizeDefault(def)
+	for len(def) >= 2 && def[0] == '(' && def[len(def)-1] == ')' {
+		def = strings.TrimSpace(def[1 : len(def)-1])
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		return strings.ReplaceAll(def[1:len(def)-1], "''", "'")
+	}
+	switch def = strings.ToLower(def); def {
+	case "true":
+		return "1"
+	case "false":
+		return "0"
+	}
+	return def
+}
+
+// implicitIndex reports whether a live index is created by the database
+// for another structure: the primary key, a UNIQUE column or a foreign key.
+func implicitIndex(idx IndexInfo, declaredCols map[string]ColumnDefinition, liveKeys map[string]struct{}) bool {
+	if idx.Primary || strings.HasPrefix(idx.Name, "sqlite_autoindex_") {
+		return true
+	}
+	if _, ok := liveKeys[idx.Name]; ok {
+		// MySQL names the index backing a foreign key after the key.
+		return true
+	}
+	if idx.Unique && len(idx.Columns) == 1 {
+		if col, ok := declaredCols[idx.Columns[0]]; ok && col.IsUnique {
+			return true
+		}
+	}
+	return false
+}
+
+// referentialAction drops the default action so restored keys read like
+// declared ones.
+func referentialAction(action string) string {
+	if strings.EqualFold(action, "NO ACTION") {
+		return ""
+	}
+	return action
+}
+
+// orderCreates orders new tables so that a table is created after the
+// new tables its foreign keys reference. Ties keep declaration order.
+func orderCreates(creates []TableDiff) []TableDiff {
+	pending := make(map[string]bool, len(creates))
+	for _, td := range creates {
+		pending[td.Table] = true
+	}
+
+	var ordered []TableDiff
+	for len(ordered) < len(creates) {
+		progressed := false
+		for _, td := range creates {
+			if !pending[td.Table] {
+				continue
+			}
+			ready := true
+			for _, fk := range td.Create.ForeignKeys() {
+				if fk.RefTable != td.Table && pending[fk.RefTable] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, td)
+				pending[td.Table] = false
+				progressed = true
+			}
+		}
+		if !progressed {
+			// Circular references: keep the remaining tables in order.
+			for _, td := range creates {
+				if pending[td.Table] {
+					ordered = append(ordered, td)
+					pending[td.Table] = false
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+var (
+	typeArgs    = regexp.MustCompile(`\(([^)]*)\)`)
+	enumValue   = regexp.MustCompile(`'((?:[^']|'')*)'`)
+	defaultCast = regexp.MustCompile(`::[\w ]+(\[\])?$`)
+)
+
+// ColumnFromInfo converts a live column into the closest ColumnDefinition.
+// It returns false when the type has no Blueprint equivalent, in which case
+// the column is mapped to TypeText.
+func ColumnFromInfo(info ColumnInfo) (ColumnDefinition, bool) {
+	col := ColumnDefinition{
+		Name:            info.Name,
+		IsNullable:      info.Nullable,
+		IsPrimary:       info.PrimaryKey,
+		IsAutoIncrement: info.AutoIncrement,
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(info.Type))
+	if strings.Contains(lower, "unsigned") {
+		col.IsUnsigned = true
+		lower = strings.TrimSpace(strings.ReplaceAll(lower, "unsigned", ""))
+	}
+	base := lower
+	var args []int
+	if m := typeArgs.FindStringSubmatch(lower); m != nil {
+		base = strings.TrimSpace(lower[:strings.Index(lower, "(")])
+		for _, a := range strings.Split(m[1], ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(a)); err == nil {
+				args = append(args, n)
+			}
+		}
+	}
+	arg := func(i, def int) int {
+		if i < len(args) {
+			return args[i]
+		}
+		return def
+	}
+
+	ok := true
+	switch base {
+	case "varchar", "character varying", "nvarchar":
+		col.Type, col.Length = TypeString, arg(0, 255)
+	case "char", "character", "bpchar":
+		col.Type, col.Length = TypeChar, arg(0, 255)
+		if col.Length == 36 && strings.HasPrefix(base, "char") {
+			col.Type, col.Length = TypeUUID, 0
+		}
+	case "text", "clob":
+		col.Type = TypeText
+	case "mediumtext":
+		col.Type = TypeMediumText
+	case "longtext":
+		col.Type = TypeLongText
+	case "int", "integer", "int4", "mediumint", "serial":
+		col.Type = TypeInteger
+	case "bigint", "int8", "bigserial":
+		col.Type = TypeBigInteger
+	case "smallint", "int2", "smallserial":
+		col.Type = TypeSmallInt
+	case "tinyint":
+		col.Type = TypeTinyInt
+		if arg(0, 0) == 1 {
+			col.Type = TypeBoolean
+		}
+	case "boolean", "bool":
+		col.Type = TypeBoolean
+	case "timestamp", "timestamptz", "timestamp with time zone", "timestamp without time zone", "datetime":
+		col.Type = TypeTimestamp
+	case "date":
+		col.Type = TypeDate
+	case "decimal", "numeric":
+		col.Type, col.Precision, col.Scale = TypeDecimal, arg(0, 10), arg(1, 0)
+	case "float", "double", "double precision", "real", "float8", "float4":
+		col.Type = TypeFloat
+	case "uuid":
+		col.Type = TypeUUID
+	case "json", "jsonb":
+		col.Type = TypeJSON
+	case "blob", "bytea", "binary", "varbinary", "longblob", "mediumblob":
+		col.Type = TypeBinary
+	case "enum":
+		col.Type = TypeEnum
+		for _, m := range enumValue.FindAllStringSubmatch(info.Type, -1) {
+			col.AllowedValues = append(col.AllowedValues, strings.ReplaceAll(m[1], "''", "'"))
+		}
+	default:
+		col.Type = TypeText
+		ok = false
+	}
+
+	if info.Default != nil && !info.AutoIncrement {
+		col.DefaultValue = RawExpression{Expression: normalizeDefault(*info.Default)}
+	}
+	return col, ok
+}
+
+// normalizeDefault strips PostgreSQL type casts from a reported default,
+// e.g. 'draft'::character varying becomes 'draft'.
+func normalizeDefault(def string) string {
+	return defaultCast.ReplaceAllString(strings.TrimSpace(def), "")
+}
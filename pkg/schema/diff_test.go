@@ -0,0 +1,235 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func declared(table string, fn func(*schema.Blueprint)) *schema.Blueprint {
+	bp := schema.NewBlueprint(table)
+	fn(bp)
+	return bp
+}
+
+func TestInspector_Diff_CreatesMissingTablesInDependencyOrder(t *testing.T) {
+	db := openSQLite(t)
+	inspector, err := schema.NewInspector(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, err)
+
+	posts := declared("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.BigInteger("user_id")
+		bp.Foreign("user_id").References("id").On("users")
+	})
+	users := declared("users", func(bp *schema.Blueprint) {
+		bp.ID()
+	})
+
+	diff, err := inspector.Diff([]*schema.Blueprint{posts, users})
+	require.NoError(t, err)
+	require.Len(t, diff.Tables, 2)
+	assert.Equal(t, "users", diff.Tables[0].Table)
+	assert.Same(t, users, diff.Tables[0].Create)
+	assert.Equal(t, "posts", diff.Tables[1].Table)
+}
+
+func TestInspector_Diff_AltersExistingTable(t *testing.T) {
+	db := openSQLite(t)
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("email", 255).Unique()
+		bp.String("nickname", 50).Nullable().Default("anon")
+		bp.Index("nickname")
+	}))
+	require.NoError(t, b.Create("audit", func(bp *schema.Blueprint) {
+		bp.ID()
+	}))
+
+	inspector, err := schema.NewInspector(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, err)
+
+	users := declared("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("email", 255).Unique()
+		bp.Integer("age").Nullable()
+		bp.Index("email")
+	})
+
+	diff, err := inspector.Diff([]*schema.Blueprint{users})
+	require.NoError(t, err)
+	require.Len(t, diff.Tables, 1, "undeclared tables are ignored")
+
+	td := diff.Tables[0]
+	assert.Nil(t, td.Create)
+	require.Len(t, td.AddColumns, 1)
+	assert.Equal(t, "age", td.AddColumns[0].Name)
+	require.Len(t, td.AddIndexes, 1)
+	assert.Equal(t, "idx_users_email", td.AddIndexes[0].Name)
+
+	require.Len(t, td.DropColumns, 1)
+	nickname := td.DropColumns[0]
+	assert.Equal(t, "nickname", nickname.Name)
+	assert.True(t, nickname.IsNullable)
+	assert.Equal(t, schema.RawExpression{Expression: "'anon'"}, nickname.DefaultValue)
+	require.Len(t, td.DropIndexes, 1)
+	assert.Equal(t, "idx_users_nickname", td.DropIndexes[0].Name)
+	assert.Empty(t, diff.Warnings)
+}
+
+func TestInspector_Diff_NoChanges(t *testing.T) {
+	db := openSQLite(t)
+	define := func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("email", 255).Unique()
+		bp.Index("email")
+	}
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", define))
+
+	inspector, err := schema.NewInspector(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, err)
+
+	diff, err := inspector.Diff([]*schema.Blueprint{declared("users", define)})
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+}
+
+func TestInspector_Diff_ChangesColumns(t *testing.T) {
+	db := openSQLite(t)
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.Integer("age")
+		bp.String("nickname", 50).Nullable()
+		bp.String("status", 20).Default("draft")
+		bp.String("name", 100)
+	}))
+
+	inspector, err := schema.NewInspector(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, err)
+
+	users := declared("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("age", 10)
+		bp.String("nickname", 50)
+		bp.String("status", 20).Default("published")
+		bp.String("name", 100)
+	})
+
+	diff, err := inspector.Diff([]*schema.Blueprint{users})
+	require.NoError(t, err)
+	require.Len(t, diff.Tables, 1)
+	changes := diff.Tables[0].ChangeColumns
+	require.Len(t, changes, 3)
+
+	age := changes[0]
+	assert.Equal(t, "age", age.To.Name)
+	assert.Equal(t, schema.TypeString, age.To.Type)
+	assert.True(t, age.To.IsChange)
+	assert.Equal(t, schema.TypeInteger, age.From.Type, "the live definition is kept for Down")
+	assert.True(t, age.From.IsChange)
+
+	nickname := changes[1]
+	assert.False(t, nickname.To.IsNullable)
+	assert.True(t, nickname.From.IsNullable)
+
+	status := changes[2]
+	assert.Equal(t, "published", status.To.DefaultValue)
+	assert.Equal(t, schema.RawExpression{Expression: "'draft'"}, status.From.DefaultValue)
+	assert.Empty(t, diff.Warnings)
+
+	// Applying the changes converges the database on the declaration.
+	require.NoError(t, b.Alter("users", func(bp *schema.Blueprint) {
+		for _, c := range changes {
+			*bp.String(c.To.Name, c.To.Length) = c.To
+		}
+	}))
+	diff, err = inspector.Diff([]*schema.Blueprint{users})
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+}
+
+func TestInspector_Diff_ComparesColumnsInTheDatabaseSpelling(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("posts"))
+	mock.ExpectQuery("FROM information_schema.columns c").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "len", "precision", "scale", "is_nullable", "column_default", "is_identity", "pk"}).
+			AddRow("id", "bigint", nil, 64, 0, "NO", "nextval('posts_id_seq'::regclass)", "NO", true).
+			AddRow("title", "character varying", 255, nil, nil, "NO", "'untitled'::character varying", "NO", false).
+			AddRow("published", "boolean", nil, nil, nil, "NO", "false", "NO", false).
+			AddRow("price", "numeric", nil, 8, 2, "YES", "0", "NO", false).
+			AddRow("created_at", "timestamp without time zone", nil, nil, nil, "YES", "CURRENT_TIMESTAMP", "NO", false))
+	mock.ExpectQuery("FROM pg_index").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "indisunique", "indisprimary", "attname"}).
+			AddRow("posts_pkey", true, true, "id"))
+	mock.ExpectQuery("FROM pg_constraint con").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "attname", "relname", "ref", "upd", "del"}))
+
+	inspector, err := schema.NewInspector(db, grammars.NewPostgresGrammar())
+	require.NoError(t, err)
+
+	posts := declared("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("title", 255).Default("untitled")
+		bp.Boolean("published").Default(false)
+		bp.Decimal("price", 8, 2).Nullable().Default(0)
+		bp.Timestamp("created_at").Nullable().DefaultRaw("CURRENT_TIMESTAMP")
+	})
+
+	diff, err := inspector.Diff([]*schema.Blueprint{posts})
+	require.NoError(t, err)
+	assert.True(t, diff.Empty(), "%+v", diff.Tables)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestColumnFromInfo(t *testing.T) {
+	def := "'draft'::character varying"
+	col, ok := schema.ColumnFromInfo(schema.ColumnInfo{Name: "status", Type: "character varying(20)", Default: &def})
+	assert.True(t, ok)
+	assert.Equal(t, schema.TypeString, col.Type)
+	assert.Equal(t, 20, col.Length)
+	assert.Equal(t, schema.RawExpression{Expression: "'draft'"}, col.DefaultValue)
+
+	col, ok = schema.ColumnFromInfo(schema.ColumnInfo{Name: "price", Type: "decimal(8,2) unsigned"})
+	assert.True(t, ok)
+	assert.Equal(t, schema.TypeDecimal, col.Type)
+	assert.Equal(t, 8, col.Precision)
+	assert.Equal(t, 2, col.Scale)
+	assert.True(t, col.IsUnsigned)
+
+	col, ok = schema.ColumnFromInfo(schema.ColumnInfo{Name: "state", Type: "enum('a','b')"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, col.AllowedValues)
+
+	col, ok = schema.ColumnFromInfo(schema.ColumnInfo{Name: "area", Type: "geometry"})
+	assert.False(t, ok)
+	assert.Equal(t, schema.TypeText, col.Type)
+}
+
+func TestDeclare(t *testing.T) {
+	schema.ResetDeclared()
+	t.Cleanup(schema.ResetDeclared)
+
+	schema.Declare("users", func(bp *schema.Blueprint) { bp.ID() })
+	schema.Declare("accounts", func(bp *schema.Blueprint) { bp.ID() })
+
+	tables := schema.DeclaredTables()
+	require.Len(t, tables, 2)
+	assert.Equal(t, "accounts", tables[0].Table())
+	assert.Equal(t, "users", tables[1].Table())
+	assert.Len(t, tables[1].Columns(), 1)
+
+	assert.Panics(t, func() {
+		schema.Declare("users", func(bp *schema.Blueprint) {})
+	})
+}
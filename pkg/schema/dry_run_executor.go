@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
@@ -10,17 +11,23 @@ import (
 var _ Executor = (*DryRunExecutor)(nil)
 
 // DryRunExecutor implements Executor but writes SQL to a Writer
-// instead of executing it against a database.
+// instead of executing it against a database. Read queries such as
+// HasTable and HasColumn are answered by Reader; without one they return
+// a nil *sql.Row.
 type DryRunExecutor struct {
 	Writer io.Writer
+	Reader Executor
 }
 
-func (d *DryRunExecutor) Exec(query string, args ...any) (sql.Result, error) {
+func (d *DryRunExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	fmt.Fprintf(d.Writer, "%s;\n", query)
 	return nil, nil
 }
 
-func (d *DryRunExecutor) QueryRow(query string, args ...any) *sql.Row {
+func (d *DryRunExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if d.Reader != nil {
+		return d.Reader.QueryRowContext(ctx, query, args...)
+	}
 	fmt.Fprintf(d.Writer, "%s;\n", query)
 	return nil
 }
@@ -1,5 +1,15 @@
 package schema
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrRebuildRequired is returned by CompileAlter for alterations the
+// database can only apply by rebuilding the table; Builder.Alter performs
+// the rebuild through TableRebuilder.
+var ErrRebuildRequired = errors.New("alteration requires a table rebuild")
+
 // Grammar defines the contract for compiling Blueprint definitions into
 // database-specific SQL statements. Each supported database engine (PostgreSQL,
 // MySQL, SQLite) provides its own Grammar implementation.
@@ -26,9 +36,51 @@ type Grammar interface {
 	// CompileHasColumn generates a query to check if a column exists in a table.
 	CompileHasColumn(table, column string) string
 
-	// CompileDropAllTables generates a statement to drop all tables in the database.
-	CompileDropAllTables() string
+	// CompileDropAll returns the statements that drop every table of the
+	// current database or schema, read through q: foreign key checks are
+	// disabled, each table is dropped and the checks are restored. The
+	// statements change session settings and must run on one connection.
+	CompileDropAll(ctx context.Context, q Queryer, opts DropAllOptions) ([]string, error)
 
 	// CompileColumnType returns the database-specific SQL type string for a column.
 	CompileColumnType(col ColumnDefinition) (string, error)
 }
+
+// DropAllOptions selects what Grammar.CompileDropAll drops besides the
+// tables.
+type DropAllOptions struct {
+	// Views, Triggers and Sequences also drop the objects of that kind.
+	// Grammars ignore kinds their database drops together with the tables
+	// or does not have. Leaving Views off does not keep a view that
+	// depends on a dropped table on PostgreSQL, where the table is dropped
+	// with CASCADE.
+	Views     bool
+	Triggers  bool
+	Sequences bool
+
+	// Except lists tables to keep.
+	Except []string
+}
+
+// Keeps reports whether table is listed in Except.
+func (o DropAllOptions) Keeps(table string) bool {
+	for _, name := range o.Except {
+		if name == table {
+			return true
+		}
+	}
+	return false
+}
+
+// TableRebuilder is implemented by grammars that apply some alterations by
+// rebuilding the table instead of altering it in place (SQLite).
+type TableRebuilder interface {
+	// NeedsRebuild reports whether the Blueprint contains alterations that
+	// CompileAlter cannot express.
+	NeedsRebuild(blueprint *Blueprint) bool
+
+	// CompileRebuild reads the current definition of the table through q
+	// and returns the statements that recreate it with all of the
+	// Blueprint's alterations applied, preserving its rows.
+	CompileRebuild(ctx context.Context, q Queryer, blueprint *Blueprint) ([]string, error)
+}
@@ -0,0 +1,185 @@
+package grammars
+
+import (
+	"context"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// CompileDropAll drops the tables of the current schema in one statement.
+// CASCADE removes the foreign keys between them, and also any views that
+// depend on them, even without opts.Views; triggers go with their tables.
+// Sequences owned by serial columns are dropped with their tables, others
+// only with opts.Sequences. Unless opts.Except keeps tables, whose columns
+// may use them, the schema's functions, procedures, domains and types
+// (enums, ranges and composites) are dropped as well; objects that belong
+// to an extension are left to it.
+func (g *PostgresGrammar) CompileDropAll(ctx context.Context, q schema.Queryer, opts schema.DropAllOptions) ([]string, error) {
+	var stmts []string
+	if opts.Views {
+		views, err := queryNames(ctx, q, `SELECT table_name FROM information_schema.views
+			WHERE table_schema = current_schema() ORDER BY table_name`)
+		if err != nil {
+			return nil, err
+		}
+		if len(views) > 0 {
+			stmts = append(stmts, "DROP VIEW IF EXISTS "+strings.Join(quoteSlice(views), ", ")+" CASCADE")
+		}
+	}
+
+	tables, err := g.InspectTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if tables = droppedTables(tables, opts); len(tables) > 0 {
+		stmts = append(stmts, "DROP TABLE IF EXISTS "+strings.Join(quoteSlice(tables), ", ")+" CASCADE")
+	}
+
+	if opts.Sequences {
+		sequences, err := queryNames(ctx, q, `SELECT sequence_name FROM information_schema.sequences
+			WHERE sequence_schema = current_schema() ORDER BY sequence_name`)
+		if err != nil {
+			return nil, err
+		}
+		if len(sequences) > 0 {
+			stmts = append(stmts, "DROP SEQUENCE IF EXISTS "+strings.Join(quoteSlice(sequences), ", ")+" CASCADE")
+		}
+	}
+
+	if len(opts.Except) > 0 {
+		return stmts, nil
+	}
+	routines, err := queryNames(ctx, q, `SELECT p.oid::regprocedure::text FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = current_schema() AND p.prokind IN ('f', 'p')
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')
+		ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	if len(routines) > 0 {
+		// regprocedure already quotes the names and lists the argument types.
+		stmts = append(stmts, "DROP ROUTINE IF EXISTS "+strings.Join(routines, ", ")+" CASCADE")
+	}
+	for _, kind := range []struct{ keyword, typtype string }{{"DOMAIN", "d"}, {"TYPE", "ecr"}} {
+		types, err := queryNames(ctx, q, `SELECT t.typname FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			WHERE n.nspname = current_schema() AND strpos($1, t.typtype::text) > 0
+				AND (t.typrelid = 0 OR EXISTS (SELECT 1 FROM pg_class c WHERE c.oid = t.typrelid AND c.relkind = 'c'))
+				AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = t.oid AND d.deptype = 'e')
+			ORDER BY t.typname`, kind.typtype)
+		if err != nil {
+			return nil, err
+		}
+		if len(types) > 0 {
+			stmts = append(stmts, "DROP "+kind.keyword+" IF EXISTS "+strings.Join(quoteSlice(types), ", ")+" CASCADE")
+		}
+	}
+	return stmts, nil
+}
+
+// CompileDropAll disables FOREIGN_KEY_CHECKS, drops the tables of the
+// current database in one statement and restores the previous setting.
+// Triggers go with their tables; opts.Sequences drops MariaDB sequences.
+func (g *MySQLGrammar) CompileDropAll(ctx context.Context, q schema.Queryer, opts schema.DropAllOptions) ([]string, error) {
+	checks, err := queryNames(ctx, q, `SELECT @@FOREIGN_KEY_CHECKS`)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts := []string{"SET FOREIGN_KEY_CHECKS = 0"}
+	if opts.Views {
+		views, err := queryNames(ctx, q, `SELECT table_name FROM information_schema.views
+			WHERE table_schema = DATABASE() ORDER BY table_name`)
+		if err != nil {
+			return nil, err
+		}
+		if len(views) > 0 {
+			stmts = append(stmts, "DROP VIEW IF EXISTS "+strings.Join(mysqlQuoteSlice(views), ", "))
+		}
+	}
+
+	tables, err := g.InspectTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if tables = droppedTables(tables, opts); len(tables) > 0 {
+		stmts = append(stmts, "DROP TABLE IF EXISTS "+strings.Join(mysqlQuoteSlice(tables), ", "))
+	}
+
+	if opts.Sequences {
+		sequences, err := queryNames(ctx, q, `SELECT table_name FROM information_schema.tables
+			WHERE table_schema = DATABASE() AND table_type = 'SEQUENCE' ORDER BY table_name`)
+		if err != nil {
+			return nil, err
+		}
+		if len(sequences) > 0 {
+			stmts = append(stmts, "DROP SEQUENCE IF EXISTS "+strings.Join(mysqlQuoteSlice(sequences), ", "))
+		}
+	}
+
+	if len(checks) == 1 && checks[0] != "0" {
+		stmts = append(stmts, "SET FOREIGN_KEY_CHECKS = 1")
+	}
+	return stmts, nil
+}
+
+// CompileDropAll drops each table from sqlite_master, switching foreign
+// key enforcement off around the drops when it is on. SQLite ignores that
+// switch inside a transaction. Triggers go with their tables and
+// sqlite_sequence rows with their AUTOINCREMENT tables.
+func (g *SQLiteGrammar) CompileDropAll(ctx context.Context, q schema.Queryer, opts schema.DropAllOptions) ([]string, error) {
+	foreignKeys, err := queryNames(ctx, q, `PRAGMA foreign_keys`)
+	if err != nil {
+		return nil, err
+	}
+	enforced := len(foreignKeys) == 1 && foreignKeys[0] == "1"
+
+	var stmts []string
+	if enforced {
+		stmts = append(stmts, "PRAGMA foreign_keys = OFF")
+	}
+	if opts.Triggers {
+		triggers, err := queryNames(ctx, q, `SELECT name FROM sqlite_master WHERE type = 'trigger' ORDER BY name`)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range triggers {
+			stmts = append(stmts, "DROP TRIGGER IF EXISTS "+quote(name))
+		}
+	}
+	if opts.Views {
+		views, err := queryNames(ctx, q, `SELECT name FROM sqlite_master WHERE type = 'view' ORDER BY name`)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range views {
+			stmts = append(stmts, "DROP VIEW IF EXISTS "+quote(name))
+		}
+	}
+
+	tables, err := g.InspectTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range droppedTables(tables, opts) {
+		stmts = append(stmts, "DROP TABLE IF EXISTS "+quote(name))
+	}
+
+	if enforced {
+		stmts = append(stmts, "PRAGMA foreign_keys = ON")
+	}
+	return stmts, nil
+}
+
+// droppedTables returns the tables opts does not keep.
+func droppedTables(tables []string, opts schema.DropAllOptions) []string {
+	var dropped []string
+	for _, name := range tables {
+		if !opts.Keeps(name) {
+			dropped = append(dropped, name)
+		}
+	}
+	return dropped
+}
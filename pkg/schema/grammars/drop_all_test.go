@@ -0,0 +1,170 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgres_CompileDropAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.views").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("active_users"))
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("migrations").AddRow("posts").AddRow("users"))
+	mock.ExpectQuery("FROM information_schema.sequences").
+		WillReturnRows(sqlmock.NewRows([]string{"sequence_name"}).AddRow("invoice_numbers"))
+
+	stmts, err := NewPostgresGrammar().CompileDropAll(context.Background(), db, schema.DropAllOptions{
+		Views: true, Sequences: true, Except: []string{"migrations"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`DROP VIEW IF EXISTS "active_users" CASCADE`,
+		`DROP TABLE IF EXISTS "posts", "users" CASCADE`,
+		`DROP SEQUENCE IF EXISTS "invoice_numbers" CASCADE`,
+	}, stmts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgres_CompileDropAll_EmptySchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+	mock.ExpectQuery("FROM pg_proc").
+		WillReturnRows(sqlmock.NewRows([]string{"oid"}))
+	mock.ExpectQuery("FROM pg_type").WithArgs("d").
+		WillReturnRows(sqlmock.NewRows([]string{"typname"}))
+	mock.ExpectQuery("FROM pg_type").WithArgs("ecr").
+		WillReturnRows(sqlmock.NewRows([]string{"typname"}))
+
+	stmts, err := NewPostgresGrammar().CompileDropAll(context.Background(), db, schema.DropAllOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, stmts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgres_CompileDropAll_DropsRoutinesAndTypes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users"))
+	mock.ExpectQuery("FROM pg_proc").
+		WillReturnRows(sqlmock.NewRows([]string{"oid"}).AddRow("touch_updated_at()").AddRow(`"Slug"(text, integer)`))
+	mock.ExpectQuery("FROM pg_type").WithArgs("d").
+		WillReturnRows(sqlmock.NewRows([]string{"typname"}).AddRow("email"))
+	mock.ExpectQuery("FROM pg_type").WithArgs("ecr").
+		WillReturnRows(sqlmock.NewRows([]string{"typname"}).AddRow("mood").AddRow("price_range"))
+
+	stmts, err := NewPostgresGrammar().CompileDropAll(context.Background(), db, schema.DropAllOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`DROP TABLE IF EXISTS "users" CASCADE`,
+		`DROP ROUTINE IF EXISTS touch_updated_at(), "Slug"(text, integer) CASCADE`,
+		`DROP DOMAIN IF EXISTS "email" CASCADE`,
+		`DROP TYPE IF EXISTS "mood", "price_range" CASCADE`,
+	}, stmts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQL_CompileDropAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT @@FOREIGN_KEY_CHECKS").
+		WillReturnRows(sqlmock.NewRows([]string{"@@FOREIGN_KEY_CHECKS"}).AddRow(1))
+	mock.ExpectQuery("FROM information_schema.views").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("active_users"))
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("posts").AddRow("users"))
+
+	stmts, err := NewMySQLGrammar().CompileDropAll(context.Background(), db, schema.DropAllOptions{Views: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"SET FOREIGN_KEY_CHECKS = 0",
+		"DROP VIEW IF EXISTS `active_users`",
+		"DROP TABLE IF EXISTS `posts`, `users`",
+		"SET FOREIGN_KEY_CHECKS = 1",
+	}, stmts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQL_CompileDropAll_KeepsChecksDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT @@FOREIGN_KEY_CHECKS").
+		WillReturnRows(sqlmock.NewRows([]string{"@@FOREIGN_KEY_CHECKS"}).AddRow(0))
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("users"))
+
+	stmts, err := NewMySQLGrammar().CompileDropAll(context.Background(), db, schema.DropAllOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SET FOREIGN_KEY_CHECKS = 0", "DROP TABLE IF EXISTS `users`"}, stmts)
+}
+
+// TestSQLite_CompileDropAll drops related tables on a real database with
+// foreign keys enforced, keeping one table and optionally the views.
+func TestSQLite_CompileDropAll(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "drop.db")+"?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	g := NewSQLiteGrammar()
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id));
+		CREATE TABLE migrations_lock (id INTEGER PRIMARY KEY);
+		CREATE VIEW names AS SELECT name FROM users;
+		CREATE TRIGGER users_touch AFTER INSERT ON users BEGIN SELECT 1; END;
+		INSERT INTO users (name) VALUES ('ann'); INSERT INTO posts (user_id) VALUES (1)`)
+	require.NoError(t, err)
+
+	stmts, err := g.CompileDropAll(ctx, db, schema.DropAllOptions{Except: []string{"migrations_lock"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"PRAGMA foreign_keys = OFF",
+		`DROP TABLE IF EXISTS "posts"`,
+		`DROP TABLE IF EXISTS "users"`,
+		"PRAGMA foreign_keys = ON",
+	}, stmts)
+
+	stmts, err = g.CompileDropAll(ctx, db, schema.DropAllOptions{Views: true, Triggers: true, Except: []string{"migrations_lock"}})
+	require.NoError(t, err)
+	for _, stmt := range stmts {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err, stmt)
+	}
+
+	var names []string
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE name NOT LIKE 'sqlite_%' ORDER BY name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"migrations_lock"}, names)
+
+	var enforced int
+	require.NoError(t, db.QueryRow(`PRAGMA foreign_keys`).Scan(&enforced))
+	assert.Equal(t, 1, enforced)
+}
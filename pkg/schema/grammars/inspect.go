@@ -0,0 +1,103 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// Compile-time checks that the built-in grammars support inspection.
+var (
+	_ schema.InspectorGrammar = (*PostgresGrammar)(nil)
+	_ schema.InspectorGrammar = (*MySQLGrammar)(nil)
+	_ schema.InspectorGrammar = (*SQLiteGrammar)(nil)
+)
+
+// queryNames runs a query returning a single string column.
+func queryNames(ctx context.Context, q schema.Queryer, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// indexRow is one column of an index, as returned by the per-column index
+// queries. Rows of the same index must be adjacent and in column order.
+type indexRow struct {
+	name    string
+	column  string
+	unique  bool
+	primary bool
+}
+
+// groupIndexRows merges adjacent rows of the same index into IndexInfo values.
+func groupIndexRows(rows []indexRow) []schema.IndexInfo {
+	var indexes []schema.IndexInfo
+	for _, r := range rows {
+		if n := len(indexes); n > 0 && indexes[n-1].Name == r.name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, r.column)
+			continue
+		}
+		indexes = append(indexes, schema.IndexInfo{
+			Name:    r.name,
+			Columns: []string{r.column},
+			Unique:  r.unique,
+			Primary: r.primary,
+		})
+	}
+	return indexes
+}
+
+// foreignKeyRow is one column pair of a foreign key. Rows of the same key
+// must be adjacent and in column order.
+type foreignKeyRow struct {
+	name      string
+	column    string
+	refTable  string
+	refColumn string
+	onUpdate  string
+	onDelete  string
+}
+
+// groupForeignKeyRows merges adjacent rows of the same foreign key into
+// ForeignKeyInfo values.
+func groupForeignKeyRows(rows []foreignKeyRow) []schema.ForeignKeyInfo {
+	var keys []schema.ForeignKeyInfo
+	for _, r := range rows {
+		if n := len(keys); n > 0 && keys[n-1].Name == r.name {
+			keys[n-1].Columns = append(keys[n-1].Columns, r.column)
+			keys[n-1].ReferencedColumns = append(keys[n-1].ReferencedColumns, r.refColumn)
+			continue
+		}
+		keys = append(keys, schema.ForeignKeyInfo{
+			Name:              r.name,
+			Columns:           []string{r.column},
+			ReferencedTable:   r.refTable,
+			ReferencedColumns: []string{r.refColumn},
+			OnDelete:          r.onDelete,
+			OnUpdate:          r.onUpdate,
+		})
+	}
+	return keys
+}
+
+// nullStringPtr returns a pointer to the string held by s, or nil.
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	v := s.String
+	return &v
+}
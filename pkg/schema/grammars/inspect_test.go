@@ -0,0 +1,166 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestPostgresGrammar_Inspect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	g := NewPostgresGrammar()
+
+	mock.ExpectQuery("FROM information_schema.tables").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("posts").AddRow("users"))
+	tables, err := g.InspectTables(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts", "users"}, tables)
+
+	mock.ExpectQuery("FROM information_schema.columns c").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "len", "precision", "scale", "is_nullable", "column_default", "is_identity", "pk"}).
+			AddRow("id", "bigint", nil, 64, 0, "NO", "nextval('posts_id_seq'::regclass)", "NO", true).
+			AddRow("title", "character varying", 255, nil, nil, "YES", nil, "NO", false).
+			AddRow("price", "numeric", nil, 8, 2, "NO", "0", "NO", false))
+	columns, err := g.InspectColumns(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ColumnInfo{
+		{Name: "id", Type: "bigint", Default: strPtr("nextval('posts_id_seq'::regclass)"), PrimaryKey: true, AutoIncrement: true},
+		{Name: "title", Type: "character varying(255)", Nullable: true},
+		{Name: "price", Type: "numeric(8,2)", Default: strPtr("0")},
+	}, columns)
+
+	mock.ExpectQuery("FROM pg_index").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"relname", "indisunique", "indisprimary", "attname"}).
+			AddRow("posts_pkey", true, true, "id").
+			AddRow("uniq_posts_user_id_title", true, false, "user_id").
+			AddRow("uniq_posts_user_id_title", true, false, "title"))
+	indexes, err := g.InspectIndexes(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.IndexInfo{
+		{Name: "posts_pkey", Columns: []string{"id"}, Unique: true, Primary: true},
+		{Name: "uniq_posts_user_id_title", Columns: []string{"user_id", "title"}, Unique: true},
+	}, indexes)
+
+	mock.ExpectQuery("FROM pg_constraint con").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"conname", "attname", "relname", "ref", "upd", "del"}).
+			AddRow("fk_posts_user_id", "user_id", "users", "id", "a", "c"))
+	keys, err := g.InspectForeignKeys(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ForeignKeyInfo{
+		{Name: "fk_posts_user_id", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}, keys)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLGrammar_Inspect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	g := NewMySQLGrammar()
+
+	mock.ExpectQuery("FROM information_schema.columns").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "column_type", "is_nullable", "column_default", "column_key", "extra"}).
+			AddRow("id", "bigint unsigned", "NO", nil, "PRI", "auto_increment").
+			AddRow("status", "varchar(20)", "NO", "draft", "", ""))
+	columns, err := g.InspectColumns(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ColumnInfo{
+		{Name: "id", Type: "bigint unsigned", PrimaryKey: true, AutoIncrement: true},
+		{Name: "status", Type: "varchar(20)", Default: strPtr("draft")},
+	}, columns)
+
+	mock.ExpectQuery("FROM information_schema.statistics").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"index_name", "non_unique", "column_name"}).
+			AddRow("PRIMARY", 0, "id").
+			AddRow("idx_posts_status", 1, "status"))
+	indexes, err := g.InspectIndexes(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}, Unique: true, Primary: true},
+		{Name: "idx_posts_status", Columns: []string{"status"}},
+	}, indexes)
+
+	mock.ExpectQuery("FROM information_schema.key_column_usage").WithArgs("posts").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "column_name", "referenced_table_name", "referenced_column_name", "update_rule", "delete_rule"}).
+			AddRow("fk_posts_user_id", "user_id", "users", "id", "RESTRICT", "SET NULL"))
+	keys, err := g.InspectForeignKeys(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ForeignKeyInfo{
+		{Name: "fk_posts_user_id", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnDelete: "SET NULL", OnUpdate: "RESTRICT"},
+	}, keys)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSQLiteGrammar_Inspect creates tables with the SQLite grammar on a
+// real database and reads them back.
+func TestSQLiteGrammar_Inspect(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "inspect.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+	g := NewSQLiteGrammar()
+
+	users := schema.NewBlueprint("users")
+	users.ID()
+	users.String("email", 255).Unique()
+	posts := schema.NewBlueprint("posts")
+	posts.ID()
+	posts.BigInteger("user_id")
+	posts.String("title", 255).Nullable().Default("untitled")
+	posts.UniqueIndex("user_id", "title")
+	posts.Index("title")
+	posts.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
+
+	for _, bp := range []*schema.Blueprint{users, posts} {
+		stmt, err := g.CompileCreate(bp)
+		require.NoError(t, err)
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	tables, err := g.InspectTables(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts", "users"}, tables)
+
+	columns, err := g.InspectColumns(ctx, db, "posts")
+	require.NoError(t, err)
+	require.Len(t, columns, 3)
+	assert.Equal(t, "id", columns[0].Name)
+	assert.True(t, columns[0].PrimaryKey)
+	assert.True(t, columns[0].AutoIncrement)
+	assert.False(t, columns[1].Nullable)
+	assert.True(t, columns[2].Nullable)
+	assert.Equal(t, strPtr("'untitled'"), columns[2].Default)
+
+	indexes, err := g.InspectIndexes(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.IndexInfo{
+		{Name: "idx_posts_title", Columns: []string{"title"}},
+		{Name: "uniq_posts_user_id_title", Columns: []string{"user_id", "title"}, Unique: true},
+	}, indexes)
+
+	keys, err := g.InspectForeignKeys(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ForeignKeyInfo{
+		{Name: "fk_posts_user_id", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}, keys)
+
+	columns, err = g.InspectColumns(ctx, db, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, columns)
+}
@@ -74,8 +74,19 @@ func (g *MySQLGrammar) CompileAlter(bp *schema.Blueprint) ([]string, error) {
 	var stmts []string
 	table := mysqlQuote(bp.Table())
 
-	// Add new columns
+	// Add new columns and change existing ones
 	for _, col := range bp.Columns() {
+		if col.IsChange {
+			// MODIFY COLUMN restates the whole column. UNIQUE is left out
+			// so that repeating it does not add another index.
+			col.IsUnique = false
+			colSQL, err := g.compileColumnDef(col)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, colSQL))
+			continue
+		}
 		colSQL, err := g.compileColumnDef(col)
 		if err != nil {
 			return nil, err
@@ -146,11 +157,6 @@ func (g *MySQLGrammar) CompileHasColumn(table, column string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = '%s' AND column_name = '%s'", table, column)
 }
 
-// CompileDropAllTables generates a statement to disable foreign key checks and signal table drop.
-func (g *MySQLGrammar) CompileDropAllTables() string {
-	return "SET FOREIGN_KEY_CHECKS = 0"
-}
-
 // CompileColumnType returns the MySQL-specific SQL type string for a column.
 func (g *MySQLGrammar) CompileColumnType(col schema.ColumnDefinition) (string, error) {
 	switch col.Type {
@@ -0,0 +1,106 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// InspectTables returns the base tables of the current database.
+func (g *MySQLGrammar) InspectTables(ctx context.Context, q schema.Queryer) ([]string, error) {
+	return queryNames(ctx, q, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`)
+}
+
+// InspectColumns returns the columns of table from information_schema.
+// Type is the full column type, e.g. "varchar(255)" or "int unsigned".
+func (g *MySQLGrammar) InspectColumns(ctx context.Context, q schema.Queryer, table string) ([]schema.ColumnInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT column_name, column_type, is_nullable, column_default, column_key, extra
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ?
+		ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []schema.ColumnInfo
+	for rows.Next() {
+		var (
+			name, colType, nullable, key, extra string
+			def                                 sql.NullString
+		)
+		if err := rows.Scan(&name, &colType, &nullable, &def, &key, &extra); err != nil {
+			return nil, err
+		}
+		columns = append(columns, schema.ColumnInfo{
+			Name:          name,
+			Type:          colType,
+			Nullable:      nullable == "YES",
+			Default:       nullStringPtr(def),
+			PrimaryKey:    key == "PRI",
+			AutoIncrement: strings.Contains(strings.ToLower(extra), "auto_increment"),
+		})
+	}
+	return columns, rows.Err()
+}
+
+// InspectIndexes returns the indexes of table from information_schema.statistics.
+// The primary key is reported as the index named "PRIMARY".
+func (g *MySQLGrammar) InspectIndexes(ctx context.Context, q schema.Queryer, table string) ([]schema.IndexInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT index_name, non_unique, column_name
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ?
+		ORDER BY index_name, seq_in_index`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexRows []indexRow
+	for rows.Next() {
+		var r indexRow
+		var nonUnique int
+		if err := rows.Scan(&r.name, &nonUnique, &r.column); err != nil {
+			return nil, err
+		}
+		r.unique = nonUnique == 0
+		r.primary = r.name == "PRIMARY"
+		indexRows = append(indexRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupIndexRows(indexRows), nil
+}
+
+// InspectForeignKeys returns the foreign keys of table from information_schema.
+func (g *MySQLGrammar) InspectForeignKeys(ctx context.Context, q schema.Queryer, table string) ([]schema.ForeignKeyInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT k.constraint_name, k.column_name, k.referenced_table_name,
+		k.referenced_column_name, r.update_rule, r.delete_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints r
+			ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name
+		WHERE k.table_schema = DATABASE() AND k.table_name = ? AND k.referenced_table_name IS NOT NULL
+		ORDER BY k.constraint_name, k.ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keyRows []foreignKeyRow
+	for rows.Next() {
+		var r foreignKeyRow
+		if err := rows.Scan(&r.name, &r.column, &r.refTable, &r.refColumn, &r.onUpdate, &r.onDelete); err != nil {
+			return nil, err
+		}
+		keyRows = append(keyRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupForeignKeyRows(keyRows), nil
+}
@@ -439,6 +439,20 @@ func TestMySQL_CompileAlter_MultipleOperations(t *testing.T) {
 	assert.Contains(t, stmts[2], "RENAME COLUMN")
 }
 
+func TestMySQL_CompileAlter_ChangeColumn(t *testing.T) {
+	g := newMySQLGrammar()
+	bp := schema.NewBlueprint("users")
+	bp.String("email", 320).Unique().Change()
+	bp.BigInteger("visits").Unsigned().Nullable().Default(0).Change()
+
+	stmts, err := g.CompileAlter(bp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `users` MODIFY COLUMN `email` VARCHAR(320) NOT NULL",
+		"ALTER TABLE `users` MODIFY COLUMN `visits` BIGINT UNSIGNED DEFAULT 0",
+	}, stmts)
+}
+
 // --- CompileDrop tests ---
 
 func TestMySQL_CompileDrop(t *testing.T) {
@@ -479,14 +493,6 @@ func TestMySQL_CompileHasColumn(t *testing.T) {
 	assert.Contains(t, sql, "table_schema = DATABASE()")
 }
 
-// --- CompileDropAllTables test ---
-
-func TestMySQL_CompileDropAllTables(t *testing.T) {
-	g := newMySQLGrammar()
-	sql := g.CompileDropAllTables()
-	assert.Contains(t, sql, "SET FOREIGN_KEY_CHECKS = 0")
-}
-
 // --- Grammar interface compliance ---
 
 func TestMySQLGrammar_ImplementsGrammar(t *testing.T) {
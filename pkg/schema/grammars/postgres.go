@@ -87,8 +87,16 @@ func (g *PostgresGrammar) CompileAlter(bp *schema.Blueprint) ([]string, error) {
 	var stmts []string
 	table := quote(bp.Table())
 
-	// Add new columns
+	// Add new columns and change existing ones
 	for _, col := range bp.Columns() {
+		if col.IsChange {
+			changeStmts, err := g.compileChange(bp.Table(), col)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, changeStmts...)
+			continue
+		}
 		colSQL, err := g.compileColumnDef(col)
 		if err != nil {
 			return nil, err
@@ -151,19 +159,16 @@ func (g *PostgresGrammar) CompileRename(from, to string) string {
 	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quote(from), quote(to))
 }
 
-// CompileHasTable generates a query to check if a table exists.
+// CompileHasTable generates a query to check if a table exists in the
+// current schema, the first existing schema of the search_path.
 func (g *PostgresGrammar) CompileHasTable(table string) string {
-	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name = '%s'", table)
+	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = '%s'", table)
 }
 
-// CompileHasColumn generates a query to check if a column exists in a table.
+// CompileHasColumn generates a query to check if a column exists in a table
+// of the current schema.
 func (g *PostgresGrammar) CompileHasColumn(table, column string) string {
-	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = 'public' AND table_name = '%s' AND column_name = '%s'", table, column)
-}
-
-// CompileDropAllTables generates statements to drop all tables by dropping and recreating the public schema.
-func (g *PostgresGrammar) CompileDropAllTables() string {
-	return "DROP SCHEMA public CASCADE; CREATE SCHEMA public"
+	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = '%s' AND column_name = '%s'", table, column)
 }
 
 // CompileColumnType returns the PostgreSQL-specific SQL type string for a column.
@@ -269,6 +274,51 @@ func (g *PostgresGrammar) compileColumnDef(col schema.ColumnDefinition) (string,
 	return sb.String(), nil
 }
 
+// compileChange compiles a changed column into ALTER COLUMN statements
+// setting its type, nullability and default. Auto-increment columns keep
+// their sequence default. Enum columns are changed to VARCHAR(255) and
+// their CHECK constraint, named as PostgreSQL names inline checks, is
+// replaced.
+func (g *PostgresGrammar) compileChange(tableName string, col schema.ColumnDefinition) ([]string, error) {
+	table := quote(tableName)
+	column := quote(col.Name)
+
+	typeCol := col
+	typeCol.IsAutoIncrement = false // SERIAL is not a type ALTER COLUMN accepts
+	colType, err := g.CompileColumnType(typeCol)
+	if err != nil {
+		return nil, err
+	}
+	var check string
+	if col.Type == schema.TypeEnum {
+		colType, check, _ = strings.Cut(colType, " CHECK ")
+	}
+
+	stmts := []string{fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", table, column, colType, column, colType)}
+	if col.Type == schema.TypeEnum {
+		constraint := quote(tableName + "_" + col.Name + "_check")
+		stmts = append(stmts,
+			fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", table, constraint),
+			fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK %s", table, constraint, check))
+	}
+
+	if col.IsNullable {
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL", table, column))
+	} else {
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, column))
+	}
+
+	if !col.IsAutoIncrement {
+		if col.DefaultValue != nil {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", table, column, formatDefault(col.DefaultValue)))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", table, column))
+		}
+	}
+
+	return stmts, nil
+}
+
 // compileForeignKey compiles a foreign key constraint into SQL.
 func (g *PostgresGrammar) compileForeignKey(fk schema.ForeignKeyDefinition) string {
 	var sb strings.Builder
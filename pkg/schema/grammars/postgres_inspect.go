@@ -0,0 +1,143 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// pgReferentialActions maps pg_constraint action codes to SQL keywords.
+var pgReferentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// InspectTables returns the base tables of the current schema.
+func (g *PostgresGrammar) InspectTables(ctx context.Context, q schema.Queryer) ([]string, error) {
+	return queryNames(ctx, q, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`)
+}
+
+// InspectColumns returns the columns of table from information_schema.
+// Serial and identity columns are reported as AutoIncrement.
+func (g *PostgresGrammar) InspectColumns(ctx context.Context, q schema.Queryer, table string) ([]schema.ColumnInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT c.column_name, c.data_type, c.character_maximum_length,
+		c.numeric_precision, c.numeric_scale, c.is_nullable, c.column_default, c.is_identity,
+		EXISTS (
+			SELECT 1 FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage k
+				ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name
+			WHERE tc.table_schema = c.table_schema AND tc.table_name = c.table_name
+				AND tc.constraint_type = 'PRIMARY KEY' AND k.column_name = c.column_name
+		)
+		FROM information_schema.columns c
+		WHERE c.table_schema = current_schema() AND c.table_name = $1
+		ORDER BY c.ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []schema.ColumnInfo
+	for rows.Next() {
+		var (
+			name, dataType, nullable, identity string
+			length, precision, scale           sql.NullInt64
+			def                                sql.NullString
+			primary                            bool
+		)
+		if err := rows.Scan(&name, &dataType, &length, &precision, &scale, &nullable, &def, &identity, &primary); err != nil {
+			return nil, err
+		}
+
+		colType := dataType
+		switch {
+		case length.Valid:
+			colType = fmt.Sprintf("%s(%d)", dataType, length.Int64)
+		case dataType == "numeric" && precision.Valid:
+			colType = fmt.Sprintf("numeric(%d,%d)", precision.Int64, scale.Int64)
+		}
+
+		columns = append(columns, schema.ColumnInfo{
+			Name:          name,
+			Type:          colType,
+			Nullable:      nullable == "YES",
+			Default:       nullStringPtr(def),
+			PrimaryKey:    primary,
+			AutoIncrement: identity == "YES" || strings.HasPrefix(def.String, "nextval("),
+		})
+	}
+	return columns, rows.Err()
+}
+
+// InspectIndexes returns the indexes of table from pg_index, including the
+// indexes backing primary key and unique constraints.
+func (g *PostgresGrammar) InspectIndexes(ctx context.Context, q schema.Queryer, table string) ([]schema.IndexInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT i.relname, ix.indisunique, ix.indisprimary, a.attname
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = current_schema() AND t.relname = $1
+		ORDER BY i.relname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexRows []indexRow
+	for rows.Next() {
+		var r indexRow
+		if err := rows.Scan(&r.name, &r.unique, &r.primary, &r.column); err != nil {
+			return nil, err
+		}
+		indexRows = append(indexRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupIndexRows(indexRows), nil
+}
+
+// InspectForeignKeys returns the foreign keys of table from pg_constraint.
+func (g *PostgresGrammar) InspectForeignKeys(ctx context.Context, q schema.Queryer, table string) ([]schema.ForeignKeyInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT con.conname, a.attname, rt.relname, ra.attname,
+		con.confupdtype::text, con.confdeltype::text
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class rt ON rt.oid = con.confrelid
+		JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refnum
+		WHERE con.contype = 'f' AND n.nspname = current_schema() AND t.relname = $1
+		ORDER BY con.conname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keyRows []foreignKeyRow
+	for rows.Next() {
+		var r foreignKeyRow
+		if err := rows.Scan(&r.name, &r.column, &r.refTable, &r.refColumn, &r.onUpdate, &r.onDelete); err != nil {
+			return nil, err
+		}
+		r.onUpdate = pgReferentialActions[r.onUpdate]
+		r.onDelete = pgReferentialActions[r.onDelete]
+		keyRows = append(keyRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupForeignKeyRows(keyRows), nil
+}
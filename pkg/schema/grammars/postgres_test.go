@@ -410,6 +410,51 @@ func TestCompileAlter_MultipleOperations(t *testing.T) {
 	assert.Contains(t, stmts[2], "RENAME COLUMN")
 }
 
+func TestCompileAlter_ChangeColumn(t *testing.T) {
+	g := newGrammar()
+	bp := schema.NewBlueprint("users")
+	bp.String("name", 100).Change()
+	bp.Integer("age").Nullable().Default(18).Change()
+
+	stmts, err := g.CompileAlter(bp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "users" ALTER COLUMN "name" TYPE VARCHAR(100) USING "name"::VARCHAR(100)`,
+		`ALTER TABLE "users" ALTER COLUMN "name" SET NOT NULL`,
+		`ALTER TABLE "users" ALTER COLUMN "name" DROP DEFAULT`,
+		`ALTER TABLE "users" ALTER COLUMN "age" TYPE INTEGER USING "age"::INTEGER`,
+		`ALTER TABLE "users" ALTER COLUMN "age" DROP NOT NULL`,
+		`ALTER TABLE "users" ALTER COLUMN "age" SET DEFAULT 18`,
+	}, stmts)
+}
+
+func TestCompileAlter_ChangeAutoIncrementColumn(t *testing.T) {
+	g := newGrammar()
+	bp := schema.NewBlueprint("users")
+	bp.BigInteger("id").AutoIncrement().Change()
+
+	stmts, err := g.CompileAlter(bp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "users" ALTER COLUMN "id" TYPE BIGINT USING "id"::BIGINT`,
+		`ALTER TABLE "users" ALTER COLUMN "id" SET NOT NULL`,
+	}, stmts, "the sequence default is kept")
+}
+
+func TestCompileAlter_ChangeEnumColumn(t *testing.T) {
+	g := newGrammar()
+	bp := schema.NewBlueprint("posts")
+	bp.Enum("status", []string{"draft", "published"}).Default("draft").Change()
+
+	stmts, err := g.CompileAlter(bp)
+	require.NoError(t, err)
+	require.Len(t, stmts, 5)
+	assert.Equal(t, `ALTER TABLE "posts" ALTER COLUMN "status" TYPE VARCHAR(255) USING "status"::VARCHAR(255)`, stmts[0])
+	assert.Equal(t, `ALTER TABLE "posts" DROP CONSTRAINT IF EXISTS "posts_status_check"`, stmts[1])
+	assert.Equal(t, `ALTER TABLE "posts" ADD CONSTRAINT "posts_status_check" CHECK ("status" IN ('draft','published'))`, stmts[2])
+	assert.Equal(t, `ALTER TABLE "posts" ALTER COLUMN "status" SET DEFAULT 'draft'`, stmts[4])
+}
+
 // --- CompileDrop tests ---
 
 func TestCompileDrop(t *testing.T) {
@@ -436,7 +481,7 @@ func TestCompileHasTable(t *testing.T) {
 	sql := g.CompileHasTable("users")
 	assert.Contains(t, sql, "information_schema.tables")
 	assert.Contains(t, sql, "table_name = 'users'")
-	assert.Contains(t, sql, "table_schema = 'public'")
+	assert.Contains(t, sql, "table_schema = current_schema()")
 }
 
 // --- CompileHasColumn test ---
@@ -447,16 +492,7 @@ func TestCompileHasColumn(t *testing.T) {
 	assert.Contains(t, sql, "information_schema.columns")
 	assert.Contains(t, sql, "table_name = 'users'")
 	assert.Contains(t, sql, "column_name = 'email'")
-	assert.Contains(t, sql, "table_schema = 'public'")
-}
-
-// --- CompileDropAllTables test ---
-
-func TestCompileDropAllTables(t *testing.T) {
-	g := newGrammar()
-	sql := g.CompileDropAllTables()
-	assert.Contains(t, sql, "DROP SCHEMA public CASCADE")
-	assert.Contains(t, sql, "CREATE SCHEMA public")
+	assert.Contains(t, sql, "table_schema = current_schema()")
 }
 
 // --- Grammar interface compliance ---
@@ -83,8 +83,14 @@ func (g *SQLiteGrammar) CompileCreate(bp *schema.Blueprint) (string, error) {
 }
 
 // CompileAlter generates ALTER TABLE statements from the given Blueprint.
-// SQLite has limited ALTER TABLE support: only ADD COLUMN is fully supported.
+// SQLite's ALTER TABLE can add, drop and rename columns but cannot change
+// columns, add UNIQUE or PRIMARY KEY columns, or add and drop foreign keys;
+// those return schema.ErrRebuildRequired and are applied by CompileRebuild.
 func (g *SQLiteGrammar) CompileAlter(bp *schema.Blueprint) ([]string, error) {
+	if reason := rebuildReason(bp); reason != "" {
+		return nil, fmt.Errorf("table %q: %s: %w", bp.Table(), reason, schema.ErrRebuildRequired)
+	}
+
 	var stmts []string
 	table := quote(bp.Table())
 
@@ -106,33 +112,16 @@ func (g *SQLiteGrammar) CompileAlter(bp *schema.Blueprint) ([]string, error) {
 			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, quote(cmd.Name), quote(cmd.To)))
 		case schema.CommandDropIndex:
 			stmts = append(stmts, fmt.Sprintf("DROP INDEX %s", quote(cmd.Name)))
-		case schema.CommandDropForeign:
-			// SQLite does not support dropping foreign keys directly;
-			// emit the statement for compatibility but it may not work on all versions.
-			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, quote(cmd.Name)))
 		}
 	}
 
 	// Add new indexes
 	for _, idx := range bp.Indexes() {
-		switch idx.Type {
-		case schema.IndexFulltext:
-			return nil, fmt.Errorf("fulltext indexes are not supported by SQLite")
-		case schema.IndexSpatial:
-			return nil, fmt.Errorf("spatial indexes are not supported by SQLite")
-		case schema.IndexUnique:
-			quotedCols := quoteSlice(idx.Columns)
-			stmts = append(stmts, fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", quote(idx.Name), table, strings.Join(quotedCols, ", ")))
-		default:
-			quotedCols := quoteSlice(idx.Columns)
-			stmts = append(stmts, fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quote(idx.Name), table, strings.Join(quotedCols, ", ")))
+		stmt, err := g.compileIndex(bp.Table(), idx)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	// Add new foreign keys
-	for _, fk := range bp.ForeignKeys() {
-		fkSQL := g.compileForeignKey(fk)
-		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD %s", table, fkSQL))
+		stmts = append(stmts, stmt)
 	}
 
 	return stmts, nil
@@ -163,13 +152,6 @@ func (g *SQLiteGrammar) CompileHasColumn(table, column string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name='%s'", table, column)
 }
 
-// CompileDropAllTables generates a query to retrieve all table names for dropping.
-// SQLite doesn't support dropping all tables in a single statement, so this returns
-// a query to select all table names from sqlite_master.
-func (g *SQLiteGrammar) CompileDropAllTables() string {
-	return "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
-}
-
 // CompileColumnType returns the SQLite-specific SQL type string for a column.
 func (g *SQLiteGrammar) CompileColumnType(col schema.ColumnDefinition) (string, error) {
 	switch col.Type {
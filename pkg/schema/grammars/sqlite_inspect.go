@@ -0,0 +1,194 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// sqliteNamedConstraint matches a named table constraint in CREATE TABLE
+// SQL, capturing its name, kind and column list.
+var sqliteNamedConstraint = regexp.MustCompile("(?i)CONSTRAINT\\s+[\"`\\[]?(\\w+)[\"`\\]]?\\s+(UNIQUE|FOREIGN\\s+KEY)\\s*\\(([^)]*)\\)")
+
+// InspectTables returns the tables recorded in sqlite_master.
+func (g *SQLiteGrammar) InspectTables(ctx context.Context, q schema.Queryer) ([]string, error) {
+	return queryNames(ctx, q, `SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name`)
+}
+
+// InspectColumns returns the columns of table from pragma_table_info. An
+// INTEGER PRIMARY KEY column aliases the rowid and is reported as
+// AutoIncrement.
+func (g *SQLiteGrammar) InspectColumns(ctx context.Context, q schema.Queryer, table string) ([]schema.ColumnInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk
+		FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []schema.ColumnInfo
+	pkCount := 0
+	for rows.Next() {
+		var (
+			name, colType string
+			notNull, pk   int
+			def           sql.NullString
+		)
+		if err := rows.Scan(&name, &colType, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		if pk > 0 {
+			pkCount++
+		}
+		columns = append(columns, schema.ColumnInfo{
+			Name:       name,
+			Type:       colType,
+			Nullable:   notNull == 0 && pk == 0,
+			Default:    nullStringPtr(def),
+			PrimaryKey: pk > 0,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if pkCount == 1 {
+		for i := range columns {
+			if columns[i].PrimaryKey && strings.EqualFold(columns[i].Type, "INTEGER") {
+				columns[i].AutoIncrement = true
+			}
+		}
+	}
+	return columns, nil
+}
+
+// InspectIndexes returns the indexes of table from pragma_index_list and
+// pragma_index_info. Indexes SQLite creates for named UNIQUE constraints
+// are reported under the constraint name.
+func (g *SQLiteGrammar) InspectIndexes(ctx context.Context, q schema.Queryer, table string) ([]schema.IndexInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []schema.IndexInfo
+	for rows.Next() {
+		var name, origin string
+		var unique int
+		if err := rows.Scan(&name, &unique, &origin); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		indexes = append(indexes, schema.IndexInfo{Name: name, Unique: unique == 1, Primary: origin == "pk"})
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	// Column lookups run after the index list is closed, since the
+	// queryer may be a transaction bound to a single connection.
+	for i := range indexes {
+		columns, err := queryNames(ctx, q, `SELECT COALESCE(name, '') FROM pragma_index_info(?) ORDER BY seqno`, indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i].Columns = columns
+	}
+
+	if len(indexes) > 0 {
+		named, err := g.constraintNames(ctx, q, table, "UNIQUE")
+		if err != nil {
+			return nil, err
+		}
+		for i := range indexes {
+			if !strings.HasPrefix(indexes[i].Name, "sqlite_autoindex_") {
+				continue
+			}
+			if name, ok := named[strings.Join(indexes[i].Columns, ",")]; ok {
+				indexes[i].Name = name
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// InspectForeignKeys returns the foreign keys of table from
+// pragma_foreign_key_list. SQLite does not report constraint names, so
+// they are recovered from the table's CREATE TABLE SQL; unnamed keys get
+// the Blueprint naming convention fk_<table>_<column>.
+func (g *SQLiteGrammar) InspectForeignKeys(ctx context.Context, q schema.Queryer, table string) ([]schema.ForeignKeyInfo, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, "table", "from", "to", on_update, on_delete
+		FROM pragma_foreign_key_list(?) ORDER BY id, seq`, table)
+	if err != nil {
+		return nil, err
+	}
+	var keyRows []foreignKeyRow
+	for rows.Next() {
+		var r foreignKeyRow
+		var id int
+		var to sql.NullString
+		if err := rows.Scan(&id, &r.refTable, &r.column, &to, &r.onUpdate, &r.onDelete); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// The id groups the columns of one key until names are resolved.
+		r.name = strconv.Itoa(id)
+		r.refColumn = to.String
+		keyRows = append(keyRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	keys := groupForeignKeyRows(keyRows)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	named, err := g.constraintNames(ctx, q, table, "FOREIGN KEY")
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if name, ok := named[strings.Join(keys[i].Columns, ",")]; ok {
+			keys[i].Name = name
+		} else {
+			keys[i].Name = "fk_" + table + "_" + strings.Join(keys[i].Columns, "_")
+		}
+	}
+	return keys, nil
+}
+
+// constraintNames maps the column list (comma-joined, unquoted) of each
+// named constraint of the given kind in table's CREATE TABLE SQL to the
+// constraint's name.
+func (g *SQLiteGrammar) constraintNames(ctx context.Context, q schema.Queryer, table, kind string) (map[string]string, error) {
+	ddl, err := queryNames(ctx, q, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, stmt := range ddl {
+		for _, m := range sqliteNamedConstraint.FindAllStringSubmatch(stmt, -1) {
+			if !strings.EqualFold(strings.Join(strings.Fields(m[2]), " "), kind) {
+				continue
+			}
+			var columns []string
+			for _, c := range strings.Split(m[3], ",") {
+				columns = append(columns, strings.Trim(strings.TrimSpace(c), "\"`[]"))
+			}
+			names[strings.Join(columns, ",")] = m[1]
+		}
+	}
+	return names, nil
+}
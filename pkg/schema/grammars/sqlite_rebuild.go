@@ -0,0 +1,365 @@
+package grammars
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+)
+
+// sqliteConstraintName matches the name of a named table constraint.
+var sqliteConstraintName = regexp.MustCompile("(?i)^CONSTRAINT\\s+[\"`\\[]?(\\w+)[\"`\\]]?")
+
+// sqliteTablePart is one entry of the column and constraint list of a
+// CREATE TABLE statement.
+type sqliteTablePart struct {
+	column string // empty for table constraints
+	sql    string
+}
+
+// NeedsRebuild reports whether bp contains alterations SQLite's ALTER
+// TABLE cannot apply.
+func (g *SQLiteGrammar) NeedsRebuild(bp *schema.Blueprint) bool {
+	return rebuildReason(bp) != ""
+}
+
+// rebuildReason describes the first alteration in bp that SQLite's ALTER
+// TABLE cannot apply, or returns "" if there is none.
+func rebuildReason(bp *schema.Blueprint) string {
+	for _, col := range bp.Columns() {
+		switch {
+		case col.IsChange:
+			return fmt.Sprintf("change column %q", col.Name)
+		case col.IsUnique:
+			return fmt.Sprintf("add UNIQUE column %q", col.Name)
+		case col.IsPrimary:
+			return fmt.Sprintf("add PRIMARY KEY column %q", col.Name)
+		}
+	}
+	for _, cmd := range bp.Commands() {
+		if cmd.Type == schema.CommandDropForeign {
+			return fmt.Sprintf("drop foreign key %q", cmd.Name)
+		}
+	}
+	if fks := bp.ForeignKeys(); len(fks) > 0 {
+		return fmt.Sprintf("add foreign key %q", fks[0].Name)
+	}
+	return ""
+}
+
+// CompileRebuild applies bp by rebuilding the table as the SQLite
+// documentation prescribes: the table is created under a temporary name
+// with the altered definition, the rows are copied, the old table is
+// dropped, the new one renamed, and its indexes and triggers recreated.
+// Views are kept; they are not rewritten for renamed columns.
+//
+// The definition is derived from the table's CREATE TABLE statement, so
+// columns and constraints that are not altered are kept verbatim. When
+// foreign key enforcement is on it is switched off around the rebuild;
+// SQLite ignores that inside a transaction, so the migration Runner
+// switches it off before the transaction of a migration that rebuilds.
+func (g *SQLiteGrammar) CompileRebuild(ctx context.Context, q schema.Queryer, bp *schema.Blueprint) ([]string, error) {
+	table := bp.Table()
+	ddl, err := queryNames(ctx, q, `SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(ddl) == 0 {
+		return nil, fmt.Errorf("rebuild table %q: %w", table, schema.ErrTableNotFound)
+	}
+	parts, suffix, err := splitSQLiteTable(ddl[0])
+	if err != nil {
+		return nil, fmt.Errorf("rebuild table %q: %w", table, err)
+	}
+
+	// sources maps each column of the rebuilt table to the column of the
+	// old table its values are copied from.
+	sources := make(map[string]string)
+	for _, p := range parts {
+		if p.column != "" {
+			sources[p.column] = p.column
+		}
+	}
+	renamed := make(map[string]string)
+	dropped := make(map[string]bool)
+	droppedIndexes := make(map[string]bool)
+
+	for _, col := range bp.Columns() {
+		def, err := g.compileColumnDef(col)
+		if err != nil {
+			return nil, err
+		}
+		if !col.IsChange {
+			parts = append(parts, sqliteTablePart{column: col.Name, sql: def})
+			continue
+		}
+		i := findSQLiteColumn(parts, col.Name)
+		if i < 0 {
+			return nil, fmt.Errorf("rebuild table %q: change column %q: column does not exist", table, col.Name)
+		}
+		parts[i].sql = def
+	}
+
+	for _, cmd := range bp.Commands() {
+		switch cmd.Type {
+		case schema.CommandDropColumn:
+			i := findSQLiteColumn(parts, cmd.Name)
+			if i < 0 {
+				return nil, fmt.Errorf("rebuild table %q: drop column %q: column does not exist", table, cmd.Name)
+			}
+			parts = append(parts[:i], parts[i+1:]...)
+			dropped[sources[cmd.Name]] = true
+			delete(sources, cmd.Name)
+		case schema.CommandRenameColumn:
+			i := findSQLiteColumn(parts, cmd.Name)
+			if i < 0 {
+				return nil, fmt.Errorf("rebuild table %q: rename column %q: column does not exist", table, cmd.Name)
+			}
+			_, rest := splitSQLiteIdentifier(parts[i].sql)
+			parts[i] = sqliteTablePart{column: cmd.To, sql: quote(cmd.To) + rest}
+			for j := range parts {
+				if parts[j].column == "" {
+					parts[j].sql = strings.ReplaceAll(parts[j].sql, quote(cmd.Name), quote(cmd.To))
+				}
+			}
+			if src, ok := sources[cmd.Name]; ok {
+				sources[cmd.To] = src
+				renamed[src] = cmd.To
+				delete(sources, cmd.Name)
+			}
+		case schema.CommandDropIndex:
+			if i := findSQLiteConstraint(parts, cmd.Name); i >= 0 {
+				parts = append(parts[:i], parts[i+1:]...)
+			}
+			droppedIndexes[cmd.Name] = true
+		case schema.CommandDropForeign:
+			i := findSQLiteConstraint(parts, cmd.Name)
+			if i < 0 {
+				return nil, fmt.Errorf("rebuild table %q: drop foreign key %q: constraint does not exist", table, cmd.Name)
+			}
+			parts = append(parts[:i], parts[i+1:]...)
+		}
+	}
+
+	for _, fk := range bp.ForeignKeys() {
+		parts = append(parts, sqliteTablePart{sql: g.compileForeignKey(fk)})
+	}
+
+	indexes, err := g.rebuildIndexes(ctx, q, table, dropped, renamed, droppedIndexes)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := queryNames(ctx, q, `SELECT sql FROM sqlite_master
+		WHERE type = 'trigger' AND tbl_name = ? AND sql IS NOT NULL ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := queryNames(ctx, q, `PRAGMA foreign_keys`)
+	if err != nil {
+		return nil, err
+	}
+	enforced := len(foreignKeys) == 1 && foreignKeys[0] == "1"
+	legacyAlter, err := queryNames(ctx, q, `PRAGMA legacy_alter_table`)
+	if err != nil {
+		return nil, err
+	}
+	legacy := len(legacyAlter) == 1 && legacyAlter[0] == "1"
+
+	temp := "__rebuild_" + table
+	defs := make([]string, len(parts))
+	var newCols, oldCols []string
+	for i, p := range parts {
+		defs[i] = p.sql
+		if src, ok := sources[p.column]; ok && p.column != "" {
+			newCols = append(newCols, quote(p.column))
+			oldCols = append(oldCols, quote(src))
+		}
+	}
+
+	var stmts []string
+	if enforced {
+		stmts = append(stmts, "PRAGMA foreign_keys = OFF")
+	}
+	stmts = append(stmts, fmt.Sprintf("CREATE TABLE %s (%s)%s", quote(temp), strings.Join(defs, ", "), suffix))
+	if len(newCols) > 0 {
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+			quote(temp), strings.Join(newCols, ", "), strings.Join(oldCols, ", "), quote(table)))
+	}
+	// Views that select from the table would make the rename fail while
+	// the table is gone; the legacy rename does not check them.
+	stmts = append(stmts,
+		fmt.Sprintf("DROP TABLE %s", quote(table)),
+		"PRAGMA legacy_alter_table = ON",
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quote(temp), quote(table)))
+	if !legacy {
+		stmts = append(stmts, "PRAGMA legacy_alter_table = OFF")
+	}
+	stmts = append(stmts, indexes...)
+	for _, idx := range bp.Indexes() {
+		stmt, err := g.compileIndex(table, idx)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+	stmts = append(stmts, triggers...)
+	if enforced {
+		stmts = append(stmts, "PRAGMA foreign_keys = ON")
+	}
+	return stmts, nil
+}
+
+// rebuildIndexes returns the CREATE INDEX statements of the table's
+// explicit indexes, with renamed columns substituted. Indexes that were
+// dropped or cover a dropped column are left out.
+func (g *SQLiteGrammar) rebuildIndexes(ctx context.Context, q schema.Queryer, table string, dropped map[string]bool, renamed map[string]string, droppedIndexes map[string]bool) ([]string, error) {
+	names, err := queryNames(ctx, q, `SELECT name FROM sqlite_master
+		WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []string
+	for _, name := range names {
+		if droppedIndexes[name] {
+			continue
+		}
+		columns, err := queryNames(ctx, q, `SELECT COALESCE(name, '') FROM pragma_index_info(?)`, name)
+		if err != nil {
+			return nil, err
+		}
+		keep := true
+		for _, c := range columns {
+			if dropped[c] {
+				keep = false
+			}
+		}
+		if !keep {
+			continue
+		}
+		sqlText, err := queryNames(ctx, q, `SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?`, name)
+		if err != nil {
+			return nil, err
+		}
+		stmt := sqlText[0]
+		for from, to := range renamed {
+			stmt = strings.ReplaceAll(stmt, quote(from), quote(to))
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+// compileIndex compiles a CREATE INDEX statement for idx on table.
+func (g *SQLiteGrammar) compileIndex(table string, idx schema.IndexDefinition) (string, error) {
+	quotedCols := strings.Join(quoteSlice(idx.Columns), ", ")
+	switch idx.Type {
+	case schema.IndexFulltext:
+		return "", fmt.Errorf("fulltext indexes are not supported by SQLite")
+	case schema.IndexSpatial:
+		return "", fmt.Errorf("spatial indexes are not supported by SQLite")
+	case schema.IndexUnique:
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", quote(idx.Name), quote(table), quotedCols), nil
+	default:
+		return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quote(idx.Name), quote(table), quotedCols), nil
+	}
+}
+
+// splitSQLiteTable splits a CREATE TABLE statement into its columns and
+// table constraints, and returns what follows the closing parenthesis
+// (e.g. " WITHOUT ROWID").
+func splitSQLiteTable(ddl string) ([]sqliteTablePart, string, error) {
+	open := strings.Index(ddl, "(")
+	if open < 0 {
+		return nil, "", fmt.Errorf("unexpected table definition %q", ddl)
+	}
+
+	var parts []sqliteTablePart
+	depth, start := 0, open+1
+	for i := open; i < len(ddl); i++ {
+		switch c := ddl[i]; c {
+		case '\'', '"', '`':
+			end := strings.IndexByte(ddl[i+1:], c)
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated quote in %q", ddl)
+			}
+			i += end + 1
+		case '[':
+			end := strings.IndexByte(ddl[i+1:], ']')
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated identifier in %q", ddl)
+			}
+			i += end + 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				parts = append(parts, newSQLiteTablePart(ddl[start:i]))
+				return parts, ddl[i+1:], nil
+			}
+		case ',':
+			if depth == 1 {
+				parts = append(parts, newSQLiteTablePart(ddl[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("unterminated table definition %q", ddl)
+}
+
+// newSQLiteTablePart classifies one entry of a CREATE TABLE list.
+func newSQLiteTablePart(sqlText string) sqliteTablePart {
+	sqlText = strings.TrimSpace(sqlText)
+	keyword := strings.ToUpper(strings.SplitN(sqlText, " ", 2)[0])
+	switch keyword {
+	case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+		return sqliteTablePart{sql: sqlText}
+	}
+	name, _ := splitSQLiteIdentifier(sqlText)
+	return sqliteTablePart{column: name, sql: sqlText}
+}
+
+// splitSQLiteIdentifier splits the leading, possibly quoted, identifier
+// off s and returns it unquoted together with the remainder.
+func splitSQLiteIdentifier(s string) (string, string) {
+	if s == "" {
+		return "", ""
+	}
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}[s[0]]
+	if closing != 0 {
+		if end := strings.IndexByte(s[1:], closing); end >= 0 {
+			return s[1 : end+1], s[end+2:]
+		}
+	}
+	if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+		return s[:i], s[i:]
+	}
+	return s, ""
+}
+
+// findSQLiteColumn returns the index of the named column, or -1.
+func findSQLiteColumn(parts []sqliteTablePart, name string) int {
+	for i, p := range parts {
+		if p.column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// findSQLiteConstraint returns the index of the named table constraint,
+// or -1.
+func findSQLiteConstraint(parts []sqliteTablePart, name string) int {
+	for i, p := range parts {
+		if p.column != "" {
+			continue
+		}
+		if m := sqliteConstraintName.FindStringSubmatch(p.sql); m != nil && m[1] == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,248 @@
+package grammars
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openRebuildDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "rebuild.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLite_CompileAlter_ChangeRequiresRebuild(t *testing.T) {
+	g := newSQLiteGrammar()
+	bp := schema.NewBlueprint("users")
+	bp.String("name", 100).Change()
+
+	_, err := g.CompileAlter(bp)
+	assert.True(t, errors.Is(err, schema.ErrRebuildRequired))
+	assert.True(t, g.NeedsRebuild(bp))
+}
+
+// TestSQLite_ChangeColumn_RebuildsTable changes columns through the
+// Builder on a real database and checks that rows, indexes, triggers and
+// views survive the rebuild.
+func TestSQLite_ChangeColumn_RebuildsTable(t *testing.T) {
+	db := openRebuildDB(t)
+	g := NewSQLiteGrammar()
+	b := schema.NewBuilder(db, g)
+
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("name", 50)
+		bp.Integer("age").Nullable()
+		bp.String("email", 255).Unique()
+		bp.Index("name")
+	}))
+	_, err := db.Exec(`CREATE TABLE audit (msg TEXT);
+		CREATE TRIGGER users_audit AFTER INSERT ON users BEGIN INSERT INTO audit VALUES (new.name); END;
+		CREATE VIEW adults AS SELECT name FROM users WHERE age >= 18;
+		INSERT INTO users (name, age, email) VALUES ('ann', 30, 'ann@example.com'), ('bob', 12, 'bob@example.com')`)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Alter("users", func(bp *schema.Blueprint) {
+		bp.String("name", 50).Nullable().Change()
+		bp.Integer("age").Default(0).Change()
+		bp.RenameColumn("email", "mail")
+	}))
+
+	var name, mail string
+	var age int
+	require.NoError(t, db.QueryRow(`SELECT name, age, mail FROM users WHERE id = 1`).Scan(&name, &age, &mail))
+	assert.Equal(t, "ann", name)
+	assert.Equal(t, 30, age)
+	assert.Equal(t, "ann@example.com", mail)
+
+	columns, err := g.InspectColumns(context.Background(), db, "users")
+	require.NoError(t, err)
+	require.Len(t, columns, 4)
+	assert.True(t, columns[0].AutoIncrement)
+	assert.True(t, columns[1].Nullable, "name is now nullable")
+	assert.False(t, columns[2].Nullable, "age is now NOT NULL")
+	assert.Equal(t, strPtr("0"), columns[2].Default)
+	assert.Equal(t, "mail", columns[3].Name)
+
+	indexes, err := g.InspectIndexes(context.Background(), db, "users")
+	require.NoError(t, err)
+	var names []string
+	for _, idx := range indexes {
+		names = append(names, idx.Name)
+	}
+	assert.Contains(t, names, "idx_users_name")
+
+	_, err = db.Exec(`INSERT INTO users (name, mail) VALUES ('cid', 'cid@example.com')`)
+	require.NoError(t, err, "age falls back to its new default")
+	var audited int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM audit`).Scan(&audited))
+	assert.Equal(t, 3, audited, "the trigger was recreated")
+
+	var adults int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM adults`).Scan(&adults))
+	assert.Equal(t, 1, adults)
+
+	_, err = db.Exec(`INSERT INTO users (name, age, mail) VALUES ('dup', 1, 'ann@example.com')`)
+	assert.Error(t, err, "the UNIQUE constraint is kept")
+}
+
+func TestSQLite_ChangeColumn_InsideTransaction(t *testing.T) {
+	db := openRebuildDB(t)
+	_, err := db.Exec(`CREATE TABLE "items" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "qty" TEXT NOT NULL); INSERT INTO items (qty) VALUES ('3')`)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	b := schema.NewBuilder(schema.NewRecordingExecutor(tx), NewSQLiteGrammar())
+	require.NoError(t, b.Alter("items", func(bp *schema.Blueprint) {
+		bp.Integer("qty").Change()
+	}))
+	require.NoError(t, tx.Commit())
+
+	var qtyType string
+	require.NoError(t, db.QueryRow(`SELECT typeof(qty) FROM items`).Scan(&qtyType))
+	assert.Equal(t, "integer", qtyType)
+}
+
+func TestSQLite_ChangeColumn_MissingColumn(t *testing.T) {
+	db := openRebuildDB(t)
+	_, err := db.Exec(`CREATE TABLE "items" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`)
+	require.NoError(t, err)
+
+	b := schema.NewBuilder(db, NewSQLiteGrammar())
+	err = b.Alter("items", func(bp *schema.Blueprint) {
+		bp.Integer("qty").Change()
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `change column "qty"`)
+
+	err = b.Alter("missing", func(bp *schema.Blueprint) {
+		bp.Integer("qty").Change()
+	})
+	assert.True(t, errors.Is(err, schema.ErrTableNotFound))
+}
+
+func TestSplitSQLiteTable(t *testing.T) {
+	parts, suffix, err := splitSQLiteTable(`CREATE TABLE "t" ("id" INTEGER, "price" DECIMAL(8, 2) DEFAULT 'a,b', ` +
+		`CONSTRAINT "fk_t_id" FOREIGN KEY ("id") REFERENCES "u" ("id")) WITHOUT ROWID`)
+	require.NoError(t, err)
+	assert.Equal(t, []sqliteTablePart{
+		{column: "id", sql: `"id" INTEGER`},
+		{column: "price", sql: `"price" DECIMAL(8, 2) DEFAULT 'a,b'`},
+		{sql: `CONSTRAINT "fk_t_id" FOREIGN KEY ("id") REFERENCES "u" ("id")`},
+	}, parts)
+	assert.Equal(t, " WITHOUT ROWID", suffix)
+	assert.Equal(t, 2, findSQLiteConstraint(parts, "fk_t_id"))
+}
+
+// TestSQLite_ChangeColumn_ForeignKeysEnforced rebuilds a referenced table
+// with enforcement on; rows referencing it must not be cascaded away.
+func TestSQLite_ChangeColumn_ForeignKeysEnforced(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "fk.db")+"?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	b := schema.NewBuilder(db, NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("name", 50)
+	}))
+	require.NoError(t, b.Create("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.BigInteger("user_id")
+		bp.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
+	}))
+	_, err = db.Exec(`INSERT INTO users (name) VALUES ('ann'); INSERT INTO posts (user_id) VALUES (1)`)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Alter("users", func(bp *schema.Blueprint) {
+		bp.String("name", 50).Nullable().Change()
+	}))
+
+	var posts int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&posts))
+	assert.Equal(t, 1, posts)
+	var enforced int
+	require.NoError(t, db.QueryRow(`PRAGMA foreign_keys`).Scan(&enforced))
+	assert.Equal(t, 1, enforced, "enforcement is switched back on")
+}
+
+// TestSQLite_ForeignKeys_RebuildTable adds and drops a foreign key on an
+// existing table, which SQLite's ALTER TABLE cannot do, inside a
+// transaction as the migration runner does.
+func TestSQLite_ForeignKeys_RebuildTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "fk.db")+"?_foreign_keys=1")
+	require.NoError(t, err)
+	defer db.Close()
+	g := NewSQLiteGrammar()
+	ctx := context.Background()
+
+	b := schema.NewBuilder(db, g)
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+	}))
+	require.NoError(t, b.Create("posts", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.BigInteger("user_id")
+		bp.Index("user_id")
+	}))
+	_, err = db.Exec(`INSERT INTO users (id) VALUES (1); INSERT INTO posts (user_id) VALUES (1)`)
+	require.NoError(t, err)
+
+	alter := func(fn func(*schema.Blueprint)) {
+		t.Helper()
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		require.NoError(t, schema.NewBuilder(schema.NewRecordingExecutor(tx), g).Alter("posts", fn))
+		require.NoError(t, tx.Commit())
+	}
+
+	alter(func(bp *schema.Blueprint) {
+		bp.String("slug", 100).Nullable().Unique()
+		bp.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
+	})
+
+	keys, err := g.InspectForeignKeys(ctx, db, "posts")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "fk_posts_user_id", keys[0].Name)
+	indexes, err := g.InspectIndexes(ctx, db, "posts")
+	require.NoError(t, err)
+	var names []string
+	for _, idx := range indexes {
+		names = append(names, idx.Name)
+	}
+	assert.Contains(t, names, "idx_posts_user_id")
+
+	_, err = db.Exec(`INSERT INTO posts (user_id) VALUES (42)`)
+	assert.Error(t, err, "the new foreign key is enforced")
+	var posts int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&posts))
+	assert.Equal(t, 1, posts)
+
+	alter(func(bp *schema.Blueprint) {
+		bp.DropForeign("fk_posts_user_id")
+	})
+
+	keys, err = g.InspectForeignKeys(ctx, db, "posts")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	_, err = db.Exec(`INSERT INTO posts (user_id) VALUES (42)`)
+	assert.NoError(t, err)
+
+	err = schema.NewBuilder(db, g).Alter("posts", func(bp *schema.Blueprint) {
+		bp.DropForeign("fk_posts_missing")
+	})
+	assert.ErrorContains(t, err, `drop foreign key "fk_posts_missing"`)
+}
@@ -432,11 +432,28 @@ func TestSQLite_CompileAlter_AddForeignKey(t *testing.T) {
 	bp := schema.NewBlueprint("posts")
 	bp.Foreign("user_id").References("id").On("users").OnDeleteAction("CASCADE")
 
-	stmts, err := g.CompileAlter(bp)
-	require.NoError(t, err)
-	require.Len(t, stmts, 1)
-	assert.Contains(t, stmts[0], `ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_user_id"`)
-	assert.Contains(t, stmts[0], `FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE`)
+	_, err := g.CompileAlter(bp)
+	assert.True(t, errors.Is(err, schema.ErrRebuildRequired), "SQLite cannot add foreign keys in place")
+	assert.True(t, g.NeedsRebuild(bp))
+}
+
+func TestSQLite_CompileAlter_DropForeign(t *testing.T) {
+	g := newSQLiteGrammar()
+	bp := schema.NewBlueprint("posts")
+	bp.DropForeign("fk_posts_user_id")
+
+	_, err := g.CompileAlter(bp)
+	assert.True(t, errors.Is(err, schema.ErrRebuildRequired), "SQLite cannot drop foreign keys in place")
+	assert.True(t, g.NeedsRebuild(bp))
+}
+
+func TestSQLite_CompileAlter_AddUniqueColumn(t *testing.T) {
+	g := newSQLiteGrammar()
+	bp := schema.NewBlueprint("users")
+	bp.String("email", 255).Nullable().Unique()
+
+	_, err := g.CompileAlter(bp)
+	assert.True(t, errors.Is(err, schema.ErrRebuildRequired))
 }
 
 func TestSQLite_CompileAlter_MultipleOperations(t *testing.T) {
@@ -492,16 +509,6 @@ func TestSQLite_CompileHasColumn(t *testing.T) {
 	assert.Contains(t, sql, "name='email'")
 }
 
-// --- CompileDropAllTables test ---
-
-func TestSQLite_CompileDropAllTables(t *testing.T) {
-	g := newSQLiteGrammar()
-	sql := g.CompileDropAllTables()
-	assert.Contains(t, sql, "sqlite_master")
-	assert.Contains(t, sql, "type='table'")
-	assert.Contains(t, sql, "NOT LIKE 'sqlite_%'")
-}
-
 // --- Default string escaping ---
 
 func TestSQLite_CompileCreate_DefaultStringEscapesSingleQuotes(t *testing.T) {
@@ -0,0 +1,194 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrTableNotFound is returned by Inspector.Table for a table that does
+// not exist.
+var ErrTableNotFound = errors.New("table not found")
+
+// ErrInspectionUnsupported is returned when an Inspector cannot be built
+// because the grammar or executor does not support schema inspection.
+var ErrInspectionUnsupported = errors.New("schema inspection not supported")
+
+// Queryer runs queries that return rows. *sql.DB, *sql.Tx and *sql.Conn
+// implement it.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// TableInfo describes an existing table.
+type TableInfo struct {
+	Name        string
+	Columns     []ColumnInfo
+	Indexes     []IndexInfo
+	ForeignKeys []ForeignKeyInfo
+}
+
+// Column returns the named column and whether it exists.
+func (t *TableInfo) Column(name string) (ColumnInfo, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// Index returns the named index and whether it exists.
+func (t *TableInfo) Index(name string) (IndexInfo, bool) {
+	for _, idx := range t.Indexes {
+		if idx.Name == name {
+			return idx, true
+		}
+	}
+	return IndexInfo{}, false
+}
+
+// ColumnInfo describes a column of an existing table.
+type ColumnInfo struct {
+	Name string
+	// Type is the column type as reported by the database, e.g.
+	// "character varying(255)" (PostgreSQL), "varchar(255)" (MySQL) or
+	// "VARCHAR(255)" (SQLite).
+	Type     string
+	Nullable bool
+	// Default is the default expression as reported by the database, or
+	// nil when the column has no default.
+	Default       *string
+	PrimaryKey    bool
+	AutoIncrement bool
+}
+
+// IndexInfo describes an index of an existing table, in column order.
+type IndexInfo struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// ForeignKeyInfo describes a foreign key of an existing table. OnDelete
+// and OnUpdate hold the referential action, e.g. "CASCADE" or "NO ACTION".
+type ForeignKeyInfo struct {
+	Name              string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+	OnDelete          string
+	OnUpdate          string
+}
+
+// InspectorGrammar is implemented by grammars that can read the structure
+// of a live database. All built-in grammars implement it.
+type InspectorGrammar interface {
+	// InspectTables returns the names of the base tables, sorted.
+	InspectTables(ctx context.Context, q Queryer) ([]string, error)
+
+	// InspectColumns returns the columns of table in ordinal order.
+	InspectColumns(ctx context.Context, q Queryer, table string) ([]ColumnInfo, error)
+
+	// InspectIndexes returns the indexes of table, sorted by name.
+	InspectIndexes(ctx context.Context, q Queryer, table string) ([]IndexInfo, error)
+
+	// InspectForeignKeys returns the foreign keys of table.
+	InspectForeignKeys(ctx context.Context, q Queryer, table string) ([]ForeignKeyInfo, error)
+}
+
+// Inspector reads the structure of an existing database through the
+// introspection queries of a Grammar.
+type Inspector struct {
+	ctx     context.Context
+	queryer Queryer
+	grammar InspectorGrammar
+	types   columnTyper
+}
+
+// NewInspector creates an Inspector for the database behind q. The
+// grammar must implement InspectorGrammar.
+func NewInspector(q Queryer, grammar Grammar) (*Inspector, error) {
+	return NewInspectorContext(context.Background(), q, grammar)
+}
+
+// NewInspectorContext creates an Inspector whose queries are executed
+// with ctx.
+func NewInspectorContext(ctx context.Context, q Queryer, grammar Grammar) (*Inspector, error) {
+	ig, ok := grammar.(InspectorGrammar)
+	if !ok {
+		return nil, fmt.Errorf("grammar %T: %w", grammar, ErrInspectionUnsupported)
+	}
+	return &Inspector{ctx: ctx, queryer: q, grammar: ig, types: grammar}, nil
+}
+
+// Inspector returns an Inspector that queries through the Builder's
+// executor and context, so that a migration sees its own uncommitted
+// changes.
+func (b *Builder) Inspector() (*Inspector, error) {
+	q, ok := b.executor.(Queryer)
+	if !ok {
+		return nil, fmt.Errorf("executor %T: %w", b.executor, ErrInspectionUnsupported)
+	}
+	return NewInspectorContext(b.ctx, q, b.grammar)
+}
+
+// Tables returns the names of all base tables, sorted.
+func (i *Inspector) Tables() ([]string, error) {
+	return i.grammar.InspectTables(i.ctx, i.queryer)
+}
+
+// Table returns the columns, indexes and foreign keys of table. It
+// returns an error wrapping ErrTableNotFound if the table does not exist.
+func (i *Inspector) Table(table string) (*TableInfo, error) {
+	columns, err := i.Columns(table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %q: %w", table, ErrTableNotFound)
+	}
+	indexes, err := i.Indexes(table)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := i.ForeignKeys(table)
+	if err != nil {
+		return nil, err
+	}
+	return &TableInfo{
+		Name:        table,
+		Columns:     columns,
+		Indexes:     indexes,
+		ForeignKeys: foreignKeys,
+	}, nil
+}
+
+// Columns returns the columns of table in ordinal order.
+func (i *Inspector) Columns(table string) ([]ColumnInfo, error) {
+	columns, err := i.grammar.InspectColumns(i.ctx, i.queryer, table)
+	if err != nil {
+		return nil, fmt.Errorf("inspect columns of %q: %w", table, err)
+	}
+	return columns, nil
+}
+
+// Indexes returns the indexes of table, sorted by name.
+func (i *Inspector) Indexes(table string) ([]IndexInfo, error) {
+	indexes, err := i.grammar.InspectIndexes(i.ctx, i.queryer, table)
+	if err != nil {
+		return nil, fmt.Errorf("inspect indexes of %q: %w", table, err)
+	}
+	return indexes, nil
+}
+
+// ForeignKeys returns the foreign keys of table.
+func (i *Inspector) ForeignKeys(table string) ([]ForeignKeyInfo, error) {
+	foreignKeys, err := i.grammar.InspectForeignKeys(i.ctx, i.queryer, table)
+	if err != nil {
+		return nil, fmt.Errorf("inspect foreign keys of %q: %w", table, err)
+	}
+	return foreignKeys, nil
+}
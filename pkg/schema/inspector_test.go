@@ -0,0 +1,91 @@
+package schema_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/schema"
+	"github.com/andrianprasetya/go-migration/pkg/schema/grammars"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainGrammar is a Grammar without inspection support.
+type plainGrammar struct{ schema.Grammar }
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "inspector.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestInspector_Table(t *testing.T) {
+	db := openSQLite(t)
+	b := schema.NewBuilder(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("users", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.String("email", 255)
+		bp.Index("email")
+	}))
+
+	inspector, err := schema.NewInspector(db, grammars.NewSQLiteGrammar())
+	require.NoError(t, err)
+
+	tables, err := inspector.Tables()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users"}, tables)
+
+	info, err := inspector.Table("users")
+	require.NoError(t, err)
+	assert.Equal(t, "users", info.Name)
+	email, ok := info.Column("email")
+	require.True(t, ok)
+	assert.False(t, email.Nullable)
+	_, ok = info.Column("missing")
+	assert.False(t, ok)
+	_, ok = info.Index("idx_users_email")
+	assert.True(t, ok)
+
+	_, err = inspector.Table("missing")
+	assert.True(t, errors.Is(err, schema.ErrTableNotFound))
+}
+
+func TestInspector_RequiresInspectorGrammar(t *testing.T) {
+	db := openSQLite(t)
+	_, err := schema.NewInspector(db, plainGrammar{})
+	assert.True(t, errors.Is(err, schema.ErrInspectionUnsupported))
+}
+
+// TestBuilder_Inspector_SeesUncommittedChanges inspects through a
+// transaction wrapped in a RecordingExecutor, as the migration runner does.
+func TestBuilder_Inspector_SeesUncommittedChanges(t *testing.T) {
+	db := openSQLite(t)
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	b := schema.NewBuilder(schema.NewRecordingExecutor(tx), grammars.NewSQLiteGrammar())
+	require.NoError(t, b.Create("orders", func(bp *schema.Blueprint) {
+		bp.ID()
+		bp.Integer("total")
+	}))
+
+	inspector, err := b.Inspector()
+	require.NoError(t, err)
+	columns, err := inspector.Columns("orders")
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.Equal(t, "total", columns[1].Name)
+}
+
+func TestBuilder_Inspector_DryRunUnsupported(t *testing.T) {
+	b := schema.NewBuilder(&schema.DryRunExecutor{Writer: &bytes.Buffer{}}, grammars.NewSQLiteGrammar())
+	_, err := b.Inspector()
+	assert.True(t, errors.Is(err, schema.ErrInspectionUnsupported))
+}
@@ -1,16 +1,24 @@
 package schema
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
 
 // Compile-time check that RecordingExecutor implements Executor.
 var _ Executor = (*RecordingExecutor)(nil)
 
 // RecordingExecutor wraps an inner Executor, recording the last SQL query
 // executed. This is used by the Runner to capture the failing SQL statement
-// when wrapping errors in MigrationError.
+// when wrapping errors in MigrationError. Statements additionally collects
+// every executed statement so the Runner can checksum a migration's SQL,
+// and Reads counts the read queries its SQL may depend on.
 type RecordingExecutor struct {
-	inner   Executor
-	LastSQL string
+	inner      Executor
+	LastSQL    string
+	Statements []string
+	Reads      int
 }
 
 // NewRecordingExecutor creates a RecordingExecutor wrapping the given executor.
@@ -18,12 +26,26 @@ func NewRecordingExecutor(inner Executor) *RecordingExecutor {
 	return &RecordingExecutor{inner: inner}
 }
 
-func (r *RecordingExecutor) Exec(query string, args ...any) (sql.Result, error) {
+func (r *RecordingExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	r.LastSQL = query
-	return r.inner.Exec(query, args...)
+	r.Statements = append(r.Statements, query)
+	return r.inner.ExecContext(ctx, query, args...)
 }
 
-func (r *RecordingExecutor) QueryRow(query string, args ...any) *sql.Row {
+func (r *RecordingExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
 	r.LastSQL = query
-	return r.inner.QueryRow(query, args...)
+	r.Reads++
+	return r.inner.QueryRowContext(ctx, query, args...)
+}
+
+// QueryContext forwards to the inner executor when it can run queries
+// that return rows, so an Inspector works inside a recorded migration.
+func (r *RecordingExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	q, ok := r.inner.(Queryer)
+	if !ok {
+		return nil, fmt.Errorf("executor %T: %w", r.inner, ErrInspectionUnsupported)
+	}
+	r.LastSQL = query
+	r.Reads++
+	return q.QueryContext(ctx, query, args...)
 }
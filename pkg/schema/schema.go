@@ -1,31 +1,49 @@
 package schema
 
-import "database/sql"
-
-// Executor abstracts database execution so that both *sql.DB and *sql.Tx
-// can be used interchangeably with the Builder.
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Executor abstracts database execution so that *sql.DB, *sql.Tx and
+// *sql.Conn can be used interchangeably with the Builder.
 type Executor interface {
-	Exec(query string, args ...any) (sql.Result, error)
-	QueryRow(query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 // Builder provides a fluent API for defining database schema changes.
 // It compiles Blueprint definitions through a Grammar and executes the
 // resulting SQL against the database.
 type Builder struct {
+	ctx      context.Context
 	executor Executor
 	grammar  Grammar
 }
 
 // NewBuilder creates a new Builder with the given executor and grammar.
-// The executor can be a *sql.DB or *sql.Tx.
+// The executor can be a *sql.DB, *sql.Tx or *sql.Conn.
 func NewBuilder(executor Executor, grammar Grammar) *Builder {
+	return NewBuilderContext(context.Background(), executor, grammar)
+}
+
+// NewBuilderContext creates a new Builder whose statements are executed
+// with ctx, so that they are cancelled when ctx is done.
+func NewBuilderContext(ctx context.Context, executor Executor, grammar Grammar) *Builder {
 	return &Builder{
+		ctx:      ctx,
 		executor: executor,
 		grammar:  grammar,
 	}
 }
 
+// Context returns the context the Builder executes statements with.
+// Migrations can use it for their own queries.
+func (b *Builder) Context() context.Context {
+	return b.ctx
+}
+
 // Create creates a new table by building a Blueprint via the callback,
 // compiling it through the Grammar, and executing the resulting SQL.
 func (b *Builder) Create(table string, fn func(*Blueprint)) error {
@@ -37,23 +55,35 @@ func (b *Builder) Create(table string, fn func(*Blueprint)) error {
 		return err
 	}
 
-	_, err = b.executor.Exec(sqlStr)
+	_, err = b.executor.ExecContext(b.ctx, sqlStr)
 	return err
 }
 
 // Alter modifies an existing table by building a Blueprint via the callback,
 // compiling it through the Grammar, and executing each resulting SQL statement.
+// When the Grammar is a TableRebuilder that cannot alter the table in place,
+// the table is rebuilt instead, which requires an executor that can query.
 func (b *Builder) Alter(table string, fn func(*Blueprint)) error {
 	bp := NewBlueprint(table)
 	fn(bp)
 
-	stmts, err := b.grammar.CompileAlter(bp)
+	var stmts []string
+	var err error
+	if r, ok := b.grammar.(TableRebuilder); ok && r.NeedsRebuild(bp) {
+		q, ok := b.executor.(Queryer)
+		if !ok {
+			return fmt.Errorf("rebuild table %q: executor %T: %w", table, b.executor, ErrInspectionUnsupported)
+		}
+		stmts, err = r.CompileRebuild(b.ctx, q, bp)
+	} else {
+		stmts, err = b.grammar.CompileAlter(bp)
+	}
 	if err != nil {
 		return err
 	}
 
 	for _, stmt := range stmts {
-		if _, err := b.executor.Exec(stmt); err != nil {
+		if _, err := b.executor.ExecContext(b.ctx, stmt); err != nil {
 			return err
 		}
 	}
@@ -64,29 +94,40 @@ func (b *Builder) Alter(table string, fn func(*Blueprint)) error {
 // Drop drops the given table.
 func (b *Builder) Drop(table string) error {
 	sqlStr := b.grammar.CompileDrop(table)
-	_, err := b.executor.Exec(sqlStr)
+	_, err := b.executor.ExecContext(b.ctx, sqlStr)
 	return err
 }
 
 // DropIfExists drops the given table if it exists.
 func (b *Builder) DropIfExists(table string) error {
 	sqlStr := b.grammar.CompileDropIfExists(table)
-	_, err := b.executor.Exec(sqlStr)
+	_, err := b.executor.ExecContext(b.ctx, sqlStr)
 	return err
 }
 
 // Rename renames a table from one name to another.
 func (b *Builder) Rename(from, to string) error {
 	sqlStr := b.grammar.CompileRename(from, to)
-	_, err := b.executor.Exec(sqlStr)
+	_, err := b.executor.ExecContext(b.ctx, sqlStr)
 	return err
 }
 
+// Exec executes a raw SQL statement, such as a data update, and returns
+// the number of rows it affected. Executors that do not run statements,
+// like those of dry runs, report 0 rows.
+func (b *Builder) Exec(query string, args ...any) (int64, error) {
+	res, err := b.executor.ExecContext(b.ctx, query, args...)
+	if err != nil || res == nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // HasTable checks whether the given table exists in the database.
 func (b *Builder) HasTable(table string) (bool, error) {
 	sqlStr := b.grammar.CompileHasTable(table)
 	var count int
-	err := b.executor.QueryRow(sqlStr).Scan(&count)
+	err := b.executor.QueryRowContext(b.ctx, sqlStr).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -97,7 +138,7 @@ func (b *Builder) HasTable(table string) (bool, error) {
 func (b *Builder) HasColumn(table, column string) (bool, error) {
 	sqlStr := b.grammar.CompileHasColumn(table, column)
 	var count int
-	err := b.executor.QueryRow(sqlStr).Scan(&count)
+	err := b.executor.QueryRowContext(b.ctx, sqlStr).Scan(&count)
 	if err != nil {
 		return false, err
 	}
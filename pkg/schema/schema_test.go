@@ -1,6 +1,7 @@
 package schema_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -21,6 +22,21 @@ func TestNewBuilder(t *testing.T) {
 	assert.NotNil(t, builder)
 }
 
+func TestNewBuilderContext_CancelledContextAbortsStatements(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	builder := schema.NewBuilderContext(ctx, db, grammars.NewPostgresGrammar())
+	assert.Equal(t, ctx, builder.Context())
+
+	err = builder.Drop("users")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestBuilder_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
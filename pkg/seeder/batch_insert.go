@@ -1,8 +1,10 @@
 package seeder
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 )
@@ -19,6 +21,56 @@ const (
 	DialectSQLite
 )
 
+// dialectMap maps database driver names to Dialects.
+var dialectMap = map[string]Dialect{
+	"postgres": DialectPostgres,
+	"mysql":    DialectMySQL,
+	"sqlite":   DialectSQLite,
+	"sqlite3":  DialectSQLite,
+}
+
+// ResolveDialect returns the Dialect for the given database driver name.
+// It returns an error if the driver is not recognized.
+func ResolveDialect(driver string) (Dialect, error) {
+	d, ok := dialectMap[driver]
+	if !ok {
+		return 0, fmt.Errorf("unsupported driver %q", driver)
+	}
+	return d, nil
+}
+
+// dialectExecutor is an Executor that carries the Dialect of its
+// database.
+type dialectExecutor struct {
+	Executor
+	dialect Dialect
+}
+
+// Dialect returns the dialect the executor was wrapped with.
+func (e dialectExecutor) Dialect() Dialect {
+	return e.dialect
+}
+
+// ExecutorWithDialect returns exec wrapped with dialect, so that
+// CreateManyContext and CreateManyReturningContext generate that
+// dialect's SQL through it. A Runner passes such an Executor, with the
+// dialect set by WithDialect, to TxSeeder.RunTx.
+func ExecutorWithDialect(exec Executor, dialect Dialect) Executor {
+	if e, ok := exec.(dialectExecutor); ok {
+		exec = e.Executor
+	}
+	return dialectExecutor{Executor: exec, dialect: dialect}
+}
+
+// dialectOfExecutor returns the dialect exec was wrapped with by
+// ExecutorWithDialect, or DialectPostgres.
+func dialectOfExecutor(exec Executor) Dialect {
+	if e, ok := exec.(interface{ Dialect() Dialect }); ok {
+		return e.Dialect()
+	}
+	return DialectPostgres
+}
+
 // placeholder returns the appropriate placeholder string for the given dialect
 // and 1-based parameter index.
 func (d Dialect) placeholder(index int) string {
@@ -48,7 +100,7 @@ func (d Dialect) quoteIdent(name string) string {
 // SQL generation. All subsequent records must have the same set of keys.
 //
 // If chunkSize is zero or negative, a default of 500 is used.
-// Uses DialectPostgres by default. Use CreateManyWithDialect for other databases.
+// Uses DialectPostgres. Use CreateManyWithDialect for other databases.
 func CreateMany(db *sql.DB, table string, records []map[string]any, chunkSize int) error {
 	return CreateManyWithDialect(db, table, records, chunkSize, DialectPostgres)
 }
@@ -61,6 +113,38 @@ func CreateMany(db *sql.DB, table string, records []map[string]any, chunkSize in
 //   - DialectMySQL: ? placeholders, `backtick-quoted` identifiers
 //   - DialectSQLite: ? placeholders, "double-quoted" identifiers
 func CreateManyWithDialect(db *sql.DB, table string, records []map[string]any, chunkSize int, dialect Dialect) error {
+	return createMany(context.Background(), db, table, records, chunkSize, dialect, "", nil)
+}
+
+// CreateManyContext is like CreateMany but inserts through exec, such as
+// the transaction a TxSeeder receives, and executes with ctx. The dialect
+// is the one exec carries from ExecutorWithDialect, DialectPostgres
+// otherwise.
+func CreateManyContext(ctx context.Context, exec Executor, table string, records []map[string]any, chunkSize int) error {
+	return createMany(ctx, exec, table, records, chunkSize, dialectOfExecutor(exec), "", nil)
+}
+
+// CreateManyReturningContext is like CreateManyContext but also reads back
+// the primary key column pk that the database generated for each record,
+// storing the key of records[i] in keys[i], which must be a pointer.
+//
+// PostgreSQL returns the keys with RETURNING, so any column type works.
+// MySQL and SQLite report them with LastInsertId, which requires an
+// auto-increment integer key: the keys of a multi-row INSERT are
+// consecutive, starting at the first generated key on MySQL and ending at
+// the last one on SQLite. On MySQL they are @@auto_increment_increment
+// apart, which is read from the session first.
+func CreateManyReturningContext(ctx context.Context, exec Executor, table string, records []map[string]any, chunkSize int, pk string, keys []any) error {
+	if len(keys) != len(records) {
+		return fmt.Errorf("got %d key destinations for %d records", len(keys), len(records))
+	}
+	return createMany(ctx, exec, table, records, chunkSize, dialectOfExecutor(exec), pk, keys)
+}
+
+// createMany implements CreateManyWithDialect, CreateManyContext and
+// CreateManyReturningContext. With a pk, the generated keys are stored in
+// keys.
+func createMany(ctx context.Context, exec Executor, table string, records []map[string]any, chunkSize int, dialect Dialect, pk string, keys []any) error {
 	if len(records) == 0 {
 		return fmt.Errorf("no records to insert")
 	}
@@ -81,6 +165,15 @@ func CreateManyWithDialect(db *sql.DB, table string, records []map[string]any, c
 		return err
 	}
 
+	// MySQL spaces the keys of a multi-row INSERT by
+	// auto_increment_increment, which replication setups raise above 1.
+	step := int64(1)
+	if pk != "" && dialect == DialectMySQL {
+		if err := exec.QueryRowContext(ctx, `SELECT @@auto_increment_increment`).Scan(&step); err != nil {
+			return fmt.Errorf("read auto_increment_increment: %w", err)
+		}
+	}
+
 	// Process records in chunks.
 	for start := 0; start < len(records); start += chunkSize {
 		end := start + chunkSize
@@ -89,7 +182,11 @@ func CreateManyWithDialect(db *sql.DB, table string, records []map[string]any, c
 		}
 
 		chunk := records[start:end]
-		if err := insertChunk(db, table, columns, chunk, dialect); err != nil {
+		var chunkKeys []any
+		if pk != "" {
+			chunkKeys = keys[start:end]
+		}
+		if err := insertChunk(ctx, exec, table, columns, chunk, dialect, pk, chunkKeys, step); err != nil {
 			return fmt.Errorf("batch [%d:%d] failed: %w", start, end, err)
 		}
 	}
@@ -158,7 +255,9 @@ func keyMismatchError(index int, expected []string, record map[string]any) error
 }
 
 // insertChunk builds and executes a single multi-row INSERT statement.
-func insertChunk(db *sql.DB, table string, columns []string, records []map[string]any, dialect Dialect) error {
+// With a pk, the generated keys of the records are stored in keys; keys
+// reported by LastInsertId are step apart.
+func insertChunk(ctx context.Context, exec Executor, table string, columns []string, records []map[string]any, dialect Dialect, pk string, keys []any, step int64) error {
 	if len(records) == 0 {
 		return nil
 	}
@@ -192,6 +291,91 @@ func insertChunk(db *sql.DB, table string, columns []string, records []map[strin
 		strings.Join(rows, ", "),
 	)
 
-	_, err := db.Exec(query, values...)
-	return err
+	if pk == "" {
+		_, err := exec.ExecContext(ctx, query, values...)
+		return err
+	}
+	if dialect == DialectPostgres {
+		return insertReturning(ctx, exec, query+" RETURNING "+dialect.quoteIdent(pk), values, keys)
+	}
+
+	res, err := exec.ExecContext(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read generated key %q: %w", pk, err)
+	}
+	// MySQL reports the first key of a multi-row INSERT, SQLite the last.
+	if dialect == DialectSQLite {
+		id -= int64(len(keys)-1) * step
+	}
+	for i, dest := range keys {
+		if err := assignKey(dest, id+int64(i)*step); err != nil {
+			return fmt.Errorf("store generated key %q: %w", pk, err)
+		}
+	}
+	return nil
+}
+
+// insertReturning executes an INSERT ... RETURNING query and scans the
+// returned keys into keys, in order.
+func insertReturning(ctx context.Context, exec Executor, query string, values []any, keys []any) error {
+	rows, err := exec.QueryContext(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		if n == len(keys) {
+			return fmt.Errorf("INSERT returned more than %d keys", len(keys))
+		}
+		if err := rows.Scan(keys[n]); err != nil {
+			return fmt.Errorf("scan generated key: %w", err)
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if n != len(keys) {
+		return fmt.Errorf("INSERT returned %d keys for %d records", n, len(keys))
+	}
+	return nil
+}
+
+// assignKey stores an integer key reported by LastInsertId in dest, a
+// pointer to an integer, an interface or a sql.Scanner.
+func assignKey(dest any, id int64) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(id)
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dest)
+	}
+	v = v.Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(id) {
+			return fmt.Errorf("key %d overflows %s", id, v.Type())
+		}
+		v.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if id < 0 || v.OverflowUint(uint64(id)) {
+			return fmt.Errorf("key %d overflows %s", id, v.Type())
+		}
+		v.SetUint(uint64(id))
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return fmt.Errorf("cannot store integer key in %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(id))
+	default:
+		return fmt.Errorf("cannot store integer key in %s", v.Type())
+	}
+	return nil
 }
@@ -0,0 +1,135 @@
+package seeder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDialect(t *testing.T) {
+	for driver, want := range map[string]Dialect{
+		"postgres": DialectPostgres,
+		"mysql":    DialectMySQL,
+		"sqlite":   DialectSQLite,
+		"sqlite3":  DialectSQLite,
+	} {
+		d, err := ResolveDialect(driver)
+		require.NoError(t, err, driver)
+		assert.Equal(t, want, d, driver)
+	}
+
+	_, err := ResolveDialect("oracle")
+	assert.Error(t, err)
+}
+
+func TestCreateManyContext_UsesExecutorDialect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, DialectPostgres, dialectOfExecutor(db))
+	exec := ExecutorWithDialect(db, DialectMySQL)
+	assert.Equal(t, DialectMySQL, dialectOfExecutor(exec))
+	assert.Equal(t, DialectSQLite, dialectOfExecutor(ExecutorWithDialect(exec, DialectSQLite)))
+
+	mock.ExpectExec("INSERT INTO `users` \\(`name`\\) VALUES \\(\\?\\), \\(\\?\\)").
+		WithArgs("ann", "bob").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = CreateManyContext(context.Background(), exec, "users", []map[string]any{{"name": "ann"}, {"name": "bob"}}, 10)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateManyReturning_Postgres(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO "users" \("name"\) VALUES \(\$1\), \(\$2\) RETURNING "id"`).
+		WithArgs("ann", "bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+
+	var ids [2]int
+	err = CreateManyReturningContext(context.Background(), db, "users",
+		[]map[string]any{{"name": "ann"}, {"name": "bob"}}, 10, "id", []any{&ids[0], &ids[1]})
+	require.NoError(t, err)
+	assert.Equal(t, [2]int{7, 8}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateManyReturning_MySQLReportsFirstKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT @@auto_increment_increment").
+		WillReturnRows(sqlmock.NewRows([]string{"@@auto_increment_increment"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO `users` \\(`name`\\) VALUES \\(\\?\\), \\(\\?\\)").
+		WithArgs("ann", "bob").
+		WillReturnResult(sqlmock.NewResult(10, 2))
+	mock.ExpectExec("INSERT INTO `users` \\(`name`\\) VALUES \\(\\?\\)").
+		WithArgs("cid").
+		WillReturnResult(sqlmock.NewResult(12, 1))
+
+	var ids [3]uint64
+	err = CreateManyReturningContext(context.Background(), ExecutorWithDialect(db, DialectMySQL), "users",
+		[]map[string]any{{"name": "ann"}, {"name": "bob"}, {"name": "cid"}}, 2, "id", []any{&ids[0], &ids[1], &ids[2]})
+	require.NoError(t, err)
+	assert.Equal(t, [3]uint64{10, 11, 12}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateManyReturning_MySQLAutoIncrementIncrement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT @@auto_increment_increment").
+		WillReturnRows(sqlmock.NewRows([]string{"@@auto_increment_increment"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO `users`").
+		WithArgs("ann", "bob", "cid").
+		WillReturnResult(sqlmock.NewResult(4, 3))
+
+	var ids [3]int64
+	err = CreateManyReturningContext(context.Background(), ExecutorWithDialect(db, DialectMySQL), "users",
+		[]map[string]any{{"name": "ann"}, {"name": "bob"}, {"name": "cid"}}, 0, "id", []any{&ids[0], &ids[1], &ids[2]})
+	require.NoError(t, err)
+	assert.Equal(t, [3]int64{4, 7, 10}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateManyReturning_SQLiteReportsLastKey(t *testing.T) {
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO users (name) VALUES ('root')`)
+	require.NoError(t, err)
+
+	var ids [3]int64
+	err = CreateManyReturningContext(context.Background(), ExecutorWithDialect(db, DialectSQLite), "users",
+		[]map[string]any{{"name": "ann"}, {"name": "bob"}, {"name": "cid"}}, 2, "id", []any{&ids[0], &ids[1], &ids[2]})
+	require.NoError(t, err)
+	assert.Equal(t, [3]int64{2, 3, 4}, ids)
+
+	var name string
+	require.NoError(t, db.QueryRow(`SELECT name FROM users WHERE id = ?`, ids[2]).Scan(&name))
+	assert.Equal(t, "cid", name)
+}
+
+func TestCreateManyReturning_Errors(t *testing.T) {
+	records := []map[string]any{{"name": "ann"}}
+	err := CreateManyReturningContext(context.Background(), nil, "users", records, 0, "id", nil)
+	assert.ErrorContains(t, err, "got 0 key destinations for 1 records")
+
+	var s string
+	assert.ErrorContains(t, assignKey(&s, 1), "cannot store integer key in string")
+	var small int8
+	assert.ErrorContains(t, assignKey(&small, 300), "overflows int8")
+	var key any
+	require.NoError(t, assignKey(&key, 5))
+	assert.Equal(t, int64(5), key)
+}
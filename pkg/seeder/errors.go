@@ -1,6 +1,9 @@
 package seeder
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Sentinel errors for the seeder system.
 // Defined locally to avoid circular dependencies with pkg/migrator.
@@ -9,4 +12,24 @@ var (
 	ErrInvalidSeederName  = errors.New("invalid seeder name")
 	ErrSeederNotFound     = errors.New("seeder not found")
 	ErrCircularDependency = errors.New("circular seeder dependency")
+	ErrTrackingTable      = errors.New("seeder tracking table error")
+	ErrTrackingDisabled   = errors.New("seeder tracking is not enabled")
 )
+
+// SeederError records the failure of a single seeder.
+type SeederError struct {
+	Seeder   string // name of the seeder that failed
+	Rollback bool   // true when the seeder's Rollback failed
+	Cause    error  // error returned by the seeder
+}
+
+func (e *SeederError) Error() string {
+	if e.Rollback {
+		return fmt.Sprintf("seeder %q rollback: %v", e.Seeder, e.Cause)
+	}
+	return fmt.Sprintf("seeder %q: %v", e.Seeder, e.Cause)
+}
+
+func (e *SeederError) Unwrap() error {
+	return e.Cause
+}
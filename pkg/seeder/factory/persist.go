@@ -0,0 +1,155 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
+)
+
+// Create makes a single instance and inserts it into table. See
+// CreateMany for how fields map to columns.
+func (f *Factory[T]) Create(exec seeder.Executor, table string) (T, error) {
+	return f.CreateContext(context.Background(), exec, table)
+}
+
+// CreateContext is like Create but executes with ctx.
+func (f *Factory[T]) CreateContext(ctx context.Context, exec seeder.Executor, table string) (T, error) {
+	instances, err := f.CreateManyContext(ctx, exec, table, 1)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return instances[0], nil
+}
+
+// CreateMany makes count instances and inserts them into table with
+// seeder.CreateManyReturningContext, in the dialect exec carries (see
+// seeder.ExecutorWithDialect). It returns the instances with their generated primary
+// keys filled in.
+//
+// T must be a struct. Fields tagged `db:"column"` are inserted into that
+// column; untagged fields and fields tagged `db:"-"` are not. The primary
+// key is the field tagged `db:"column,pk"`, or the one mapped to "id".
+// When the primary key is zero in every instance it is left out of the
+// INSERT and read back from the database; when it is set in every
+// instance it is inserted as is. A mix of both is an error.
+func (f *Factory[T]) CreateMany(exec seeder.Executor, table string, count int) ([]T, error) {
+	return f.CreateManyContext(context.Background(), exec, table, count)
+}
+
+// CreateManyContext is like CreateMany but executes with ctx.
+func (f *Factory[T]) CreateManyContext(ctx context.Context, exec seeder.Executor, table string, count int) ([]T, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	var zero T
+	columns, pk, err := columnsOf(reflect.TypeOf(zero))
+	if err != nil {
+		return nil, err
+	}
+
+	instances := f.MakeMany(count)
+	values := make([]reflect.Value, count)
+	given := 0
+	for i := range instances {
+		values[i] = reflect.ValueOf(&instances[i]).Elem()
+		if pk >= 0 && !values[i].FieldByIndex(columns[pk].index).IsZero() {
+			given++
+		}
+	}
+	if given > 0 && given < count {
+		return nil, fmt.Errorf("factory: primary key %q is set in %d of %d %T instances; set it in all or none", columns[pk].name, given, count, zero)
+	}
+	generate := pk >= 0 && given == 0
+
+	if generate && len(columns) == 1 {
+		return nil, fmt.Errorf("factory: %T has no columns besides its primary key", zero)
+	}
+
+	records := make([]map[string]any, count)
+	for i, v := range values {
+		records[i] = make(map[string]any, len(columns))
+		for j, c := range columns {
+			if generate && j == pk {
+				continue
+			}
+			records[i][c.name] = v.FieldByIndex(c.index).Interface()
+		}
+	}
+
+	if !generate {
+		if err := seeder.CreateManyContext(ctx, exec, table, records, 0); err != nil {
+			return nil, fmt.Errorf("factory: insert into %q: %w", table, err)
+		}
+		return instances, nil
+	}
+	keys := make([]any, count)
+	for i, v := range values {
+		keys[i] = v.FieldByIndex(columns[pk].index).Addr().Interface()
+	}
+	if err := seeder.CreateManyReturningContext(ctx, exec, table, records, 0, columns[pk].name, keys); err != nil {
+		return nil, fmt.Errorf("factory: insert into %q: %w", table, err)
+	}
+	return instances, nil
+}
+
+// column is a struct field mapped to a column with a db tag.
+type column struct {
+	name  string
+	index []int
+}
+
+// columnsOf returns the columns of the fields of struct type t tagged with
+// db, including promoted fields of embedded structs that are not
+// pointers, and the position of
+// the primary key among them, or -1 if it has none.
+func columnsOf(t reflect.Type) ([]column, int, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, -1, fmt.Errorf("factory: %v is not a struct", t)
+	}
+
+	var columns []column
+	pk, tagged := -1, false
+	for _, field := range reflect.VisibleFields(t) {
+		tag, ok := field.Tag.Lookup("db")
+		if !ok || tag == "-" || !field.IsExported() || throughPointer(t, field.Index) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			return nil, -1, fmt.Errorf("factory: field %s of %v has an empty db tag", field.Name, t)
+		}
+		columns = append(columns, column{name: name, index: field.Index})
+
+		switch {
+		case opts == "pk":
+			if tagged {
+				return nil, -1, fmt.Errorf("factory: %v has more than one primary key", t)
+			}
+			pk, tagged = len(columns)-1, true
+		case opts != "":
+			return nil, -1, fmt.Errorf("factory: field %s of %v has unknown db tag option %q", field.Name, t, opts)
+		case name == "id" && !tagged:
+			pk = len(columns) - 1
+		}
+	}
+	if len(columns) == 0 {
+		return nil, -1, fmt.Errorf("factory: %v has no fields tagged with db", t)
+	}
+	return columns, pk, nil
+}
+
+// throughPointer reports whether the field at index of t is promoted
+// through an embedded pointer, which may be nil.
+func throughPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		t = t.Field(i).Type
+		if t.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package factory
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andrianprasetya/go-migration/pkg/seeder"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Timestamps is embedded in Account to test promoted fields.
+type Timestamps struct {
+	CreatedAt string `db:"created_at"`
+}
+
+// Account is a test struct mapped to the accounts table.
+type Account struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	Email string `db:"email"`
+	Notes string
+	Timestamps
+}
+
+func openAccounts(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "factory.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`CREATE TABLE accounts (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT NOT NULL,
+		email      TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	)`)
+	require.NoError(t, err)
+	return db
+}
+
+// sqliteExecutor returns db as an Executor that inserts with SQLite SQL.
+func sqliteExecutor(db *sql.DB) seeder.Executor {
+	return seeder.ExecutorWithDialect(db, seeder.DialectSQLite)
+}
+
+func accountFactory() *Factory[Account] {
+	return NewFactory(func(f Faker) Account {
+		return Account{
+			Name:       f.Name(),
+			Email:      f.Email(),
+			Notes:      "not persisted",
+			Timestamps: Timestamps{CreatedAt: "2024-01-01 00:00:00"},
+		}
+	}).WithFaker(NewFaker(42))
+}
+
+func TestCreateMany_InsertsAndReadsBackKeys(t *testing.T) {
+	db := openAccounts(t)
+
+	accounts, err := accountFactory().CreateMany(sqliteExecutor(db), "accounts", 3)
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	for i, a := range accounts {
+		assert.Equal(t, int64(i+1), a.ID)
+
+		var name, email, createdAt string
+		require.NoError(t, db.QueryRow(`SELECT name, email, created_at FROM accounts WHERE id = ?`, a.ID).
+			Scan(&name, &email, &createdAt))
+		assert.Equal(t, a.Name, name)
+		assert.Equal(t, a.Email, email)
+		assert.Equal(t, a.CreatedAt, createdAt)
+	}
+
+	account, err := accountFactory().Create(sqliteExecutor(db), "accounts")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), account.ID)
+}
+
+func TestCreateMany_InsertsGivenKeys(t *testing.T) {
+	db := openAccounts(t)
+	f := accountFactory().State("fixed", func(_ Faker, a Account) Account {
+		a.ID = 100
+		return a
+	})
+
+	account, err := f.WithState("fixed").Create(sqliteExecutor(db), "accounts")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), account.ID)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE id = 100`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestCreateMany_MixedKeysAreAnError(t *testing.T) {
+	db := openAccounts(t)
+	n := 0
+	f := NewFactory(func(f Faker) Account {
+		n++
+		a := Account{Name: f.Name(), Email: f.Email(), Timestamps: Timestamps{CreatedAt: "2024-01-01 00:00:00"}}
+		if n == 2 {
+			a.ID = 100
+		}
+		return a
+	})
+
+	_, err := f.CreateMany(sqliteExecutor(db), "accounts", 3)
+	assert.ErrorContains(t, err, `primary key "id" is set in 1 of 3`)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+// Tagged uses an explicit primary key tag on a column not named id.
+type Tagged struct {
+	Code  int64  `db:"code,pk"`
+	Label string `db:"label"`
+	Skip  string `db:"-"`
+}
+
+func reflectTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func TestColumnsOf(t *testing.T) {
+	columns, pk, err := columnsOf(reflectTypeOf[Tagged]())
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.Equal(t, "code", columns[pk].name)
+	assert.Equal(t, "label", columns[1].name)
+
+	_, _, err = columnsOf(reflectTypeOf[User]())
+	assert.ErrorContains(t, err, "has no fields tagged with db")
+
+	_, _, err = columnsOf(reflectTypeOf[string]())
+	assert.ErrorContains(t, err, "string is not a struct")
+
+	_, _, err = columnsOf(reflectTypeOf[struct {
+		ID int `db:"id,primary"`
+	}]())
+	assert.ErrorContains(t, err, `unknown db tag option "primary"`)
+}
+
+func TestCreateMany_ZeroCount(t *testing.T) {
+	accounts, err := accountFactory().CreateMany(nil, "accounts", 0)
+	require.NoError(t, err)
+	assert.Nil(t, accounts)
+}
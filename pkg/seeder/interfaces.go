@@ -1,6 +1,9 @@
 package seeder
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 // Seeder defines the contract for a database seeder.
 // Implementations populate database tables with data.
@@ -30,3 +33,36 @@ type RollbackableSeeder interface {
 	Seeder
 	Rollback(db *sql.DB) error
 }
+
+// Executor runs SQL statements. It is satisfied by both *sql.DB and
+// *sql.Tx, so a TxSeeder runs the same way inside or outside a transaction.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxSeeder extends Seeder with a Run method that receives an Executor.
+// When a seeder implements this interface, the Runner calls RunTx instead
+// of Run, passing the transaction the seeder runs in when transactions are
+// enabled with WithTransactions, and the database otherwise.
+type TxSeeder interface {
+	Seeder
+	RunTx(ctx context.Context, exec Executor) error
+}
+
+// VersionedSeeder extends Seeder with a version recorded by the tracker.
+// A seeder that already ran is run again when its Version changes, e.g.
+// after its data set was edited.
+type VersionedSeeder interface {
+	Seeder
+	Version() string
+}
+
+// ContextSeeder extends Seeder with a context-aware Run method.
+// When a seeder implements this interface, the Runner calls RunContext
+// instead of Run so that long-running seeders can observe cancellation.
+type ContextSeeder interface {
+	Seeder
+	RunContext(ctx context.Context, db *sql.DB) error
+}
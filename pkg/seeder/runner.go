@@ -1,10 +1,12 @@
 package seeder
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Logger defines a minimal logging interface for the seeder runner.
@@ -18,21 +20,98 @@ type Runner struct {
 	registry *Registry
 	db       *sql.DB
 	logger   Logger
+	tracker  *Tracker
+	force    bool
+	txMode   TransactionMode
+	dialect  Dialect
+}
+
+// TransactionMode selects how a Runner wraps seeders in transactions.
+type TransactionMode int
+
+const (
+	// TxNone runs seeders without a transaction. This is the default.
+	TxNone TransactionMode = iota
+	// TxPerSeeder runs each TxSeeder in its own transaction, together with
+	// its tracker record. Other seeders run without a transaction.
+	TxPerSeeder
+	// TxAll runs every seeder of a run in a single transaction, so that a
+	// failure leaves no data behind. Every seeder must be a TxSeeder.
+	TxAll
+)
+
+// ParseTransactionMode parses "none", "seeder" or "all".
+func ParseTransactionMode(s string) (TransactionMode, error) {
+	switch s {
+	case "none", "":
+		return TxNone, nil
+	case "seeder":
+		return TxPerSeeder, nil
+	case "all":
+		return TxAll, nil
+	default:
+		return TxNone, fmt.Errorf("invalid transaction mode %q: must be none, seeder or all", s)
+	}
+}
+
+// RunnerOption configures a Runner.
+type RunnerOption func(*Runner)
+
+// WithTracker records each seeder the Runner runs in t. Seeders t has
+// already recorded are skipped unless their Version changed or WithForce
+// is set, and the last batch can be rolled back with RollbackLastBatch.
+func WithTracker(t *Tracker) RunnerOption {
+	return func(r *Runner) {
+		r.tracker = t
+	}
+}
+
+// WithForce runs seeders again even when the tracker has recorded them.
+func WithForce(force bool) RunnerOption {
+	return func(r *Runner) {
+		r.force = force
+	}
+}
+
+// WithTransactions wraps seeders in transactions according to mode.
+func WithTransactions(mode TransactionMode) RunnerOption {
+	return func(r *Runner) {
+		r.txMode = mode
+	}
+}
+
+// WithDialect sets the dialect of the Runner's database. The Executor a
+// TxSeeder receives carries it, so CreateManyContext generates its SQL.
+// The default is DialectPostgres.
+func WithDialect(d Dialect) RunnerOption {
+	return func(r *Runner) {
+		r.dialect = d
+	}
 }
 
 // NewRunner creates a new seeder Runner.
 // The logger parameter may be nil, in which case logging is silently skipped.
-func NewRunner(registry *Registry, db *sql.DB, logger Logger) *Runner {
-	return &Runner{
+func NewRunner(registry *Registry, db *sql.DB, logger Logger, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		registry: registry,
 		db:       db,
 		logger:   logger,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // RunAll executes all registered seeders in dependency-resolved order.
 // If any seeder fails, execution stops and the error is returned.
 func (r *Runner) RunAll() error {
+	return r.RunAllContext(context.Background())
+}
+
+// RunAllContext is like RunAll but stops before the next seeder once ctx
+// is cancelled. Seeders implementing ContextSeeder receive ctx directly.
+func (r *Runner) RunAllContext(ctx context.Context) error {
 	all := r.registry.GetAll()
 	if len(all) == 0 {
 		return nil
@@ -43,22 +122,17 @@ func (r *Runner) RunAll() error {
 		return err
 	}
 
-	for _, name := range order {
-		s := all[name]
-		r.logInfo("Running seeder: %s", name)
-		if err := s.Run(r.db); err != nil {
-			r.logError("Seeder %s failed: %v", name, err)
-			return fmt.Errorf("seeder %q: %w", name, err)
-		}
-		r.logInfo("Seeder %s completed", name)
-	}
-
-	return nil
+	return r.runOrdered(ctx, all, order)
 }
 
 // Run executes a specific seeder and its transitive dependencies in order.
 // Returns ErrSeederNotFound if the named seeder is not registered.
 func (r *Runner) Run(name string) error {
+	return r.RunContext(context.Background(), name)
+}
+
+// RunContext is like Run but stops before the next seeder once ctx is cancelled.
+func (r *Runner) RunContext(ctx context.Context, name string) error {
 	if _, err := r.registry.Get(name); err != nil {
 		return err
 	}
@@ -68,24 +142,19 @@ func (r *Runner) Run(name string) error {
 		return err
 	}
 
-	all := r.registry.GetAll()
-	for _, sName := range order {
-		s := all[sName]
-		r.logInfo("Running seeder: %s", sName)
-		if err := s.Run(r.db); err != nil {
-			r.logError("Seeder %s failed: %v", sName, err)
-			return fmt.Errorf("seeder %q: %w", sName, err)
-		}
-		r.logInfo("Seeder %s completed", sName)
-	}
-
-	return nil
+	return r.runOrdered(ctx, r.registry.GetAll(), order)
 }
 
 // RunByTag executes only seeders whose tags include the specified tag,
 // in dependency-resolved order. Seeders that do not implement TaggedSeeder
 // are skipped. Returns without error if no seeders match the tag.
 func (r *Runner) RunByTag(tag string) error {
+	return r.RunByTagContext(context.Background(), tag)
+}
+
+// RunByTagContext is like RunByTag but stops before the next seeder once
+// ctx is cancelled.
+func (r *Runner) RunByTagContext(ctx context.Context, tag string) error {
 	all := r.registry.GetAll()
 	if len(all) == 0 {
 		return nil
@@ -113,22 +182,280 @@ func (r *Runner) RunByTag(tag string) error {
 		return err
 	}
 
+	return r.runOrdered(ctx, tagged, order)
+}
+
+// runOrdered executes the named seeders in the given order, stopping at the
+// first failure or when ctx is cancelled between seeders. With a tracker,
+// seeders that already ran are skipped and the others are recorded in a
+// new batch. The transaction mode decides which seeders run in a
+// transaction.
+func (r *Runner) runOrdered(ctx context.Context, seeders map[string]Seeder, order []string) error {
+	ran, batch, err := r.nextBatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
 	for _, name := range order {
-		s := tagged[name]
-		r.logInfo("Running seeder: %s", name)
-		if err := s.Run(r.db); err != nil {
-			r.logError("Seeder %s failed: %v", name, err)
-			return fmt.Errorf("seeder %q: %w", name, err)
+		if rec, ok := ran[name]; ok && !r.force && rec.Version == versionOf(seeders[name]) {
+			r.logInfo("Seeder %s already ran, skipping", name)
+			continue
+		}
+		pending = append(pending, name)
+	}
+
+	if r.txMode == TxAll {
+		for _, name := range pending {
+			if _, ok := seeders[name].(TxSeeder); !ok {
+				return fmt.Errorf("seeder %q does not implement TxSeeder and cannot run in a single transaction", name)
+			}
+		}
+		return r.inTransaction(ctx, func(tx *sql.Tx) error {
+			tracker := r.tracker.on(tx)
+			for _, name := range pending {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := r.seed(ctx, tx, tracker, name, seeders[name], batch); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, name := range pending {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.runOne(ctx, name, seeders[name], batch); err != nil {
+			return err
 		}
-		r.logInfo("Seeder %s completed", name)
 	}
 
 	return nil
 }
 
+// runOne runs one seeder outside of a TxAll transaction: in its own
+// transaction if it is a TxSeeder and the mode is TxPerSeeder, directly
+// otherwise.
+func (r *Runner) runOne(ctx context.Context, name string, s Seeder, batch int) error {
+	if _, ok := s.(TxSeeder); !ok || r.txMode == TxNone {
+		return r.seed(ctx, r.db, r.tracker, name, s, batch)
+	}
+	return r.inTransaction(ctx, func(tx *sql.Tx) error {
+		return r.seed(ctx, tx, r.tracker.on(tx), name, s, batch)
+	})
+}
+
+// seed runs one seeder through exec and records it in tracker.
+func (r *Runner) seed(ctx context.Context, exec Executor, tracker *Tracker, name string, s Seeder, batch int) error {
+	r.logInfo("Running seeder: %s", name)
+	if err := runSeeder(ctx, s, r.db, ExecutorWithDialect(exec, r.dialect)); err != nil {
+		r.logError("Seeder %s failed: %v", name, err)
+		return &SeederError{Seeder: name, Cause: err}
+	}
+	if tracker != nil {
+		if err := tracker.RecordContext(ctx, name, batch, versionOf(s)); err != nil {
+			return err
+		}
+	}
+	r.logInfo("Seeder %s completed", name)
+	return nil
+}
+
+// inTransaction runs fn in a transaction that is committed when fn
+// succeeds and rolled back otherwise.
+func (r *Runner) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin seed transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit seed transaction: %w", err)
+	}
+	return nil
+}
+
+// SeederTiming is how long a seeder took to run.
+type SeederTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// RunAllParallel executes all registered seeders like RunAll, but runs up
+// to n seeders at a time: each seeder starts as soon as every seeder it
+// depends on has completed. The first failure cancels the context of the
+// seeders still running and no further seeders start. It returns the
+// timings of the seeders that completed, in completion order. An n below 1
+// runs one seeder at a time. Seeders cannot run in parallel with TxAll.
+func (r *Runner) RunAllParallel(n int) ([]SeederTiming, error) {
+	return r.RunAllParallelContext(context.Background(), n)
+}
+
+// RunAllParallelContext is like RunAllParallel but starts no further
+// seeders once ctx is cancelled.
+func (r *Runner) RunAllParallelContext(ctx context.Context, n int) ([]SeederTiming, error) {
+	if r.txMode == TxAll {
+		return nil, fmt.Errorf("seeders cannot run in parallel in a single transaction")
+	}
+	if n < 1 {
+		n = 1
+	}
+	all := r.registry.GetAll()
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	order, err := r.resolveAllOrder(all)
+	if err != nil {
+		return nil, err
+	}
+	ran, batch, err := r.nextBatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// waiting counts the dependencies of each seeder that have not
+	// completed yet; a seeder is ready when its count drops to zero.
+	waiting := make(map[string]int, len(order))
+	dependents := make(map[string][]string)
+	for _, name := range order {
+		if ds, ok := all[name].(DependentSeeder); ok {
+			for _, dep := range ds.DependsOn() {
+				waiting[name]++
+				dependents[dep] = append(dependents[dep], name)
+			}
+		}
+	}
+	var ready []string
+	for _, name := range order {
+		if waiting[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	complete := func(name string) {
+		for _, d := range dependents[name] {
+			if waiting[d]--; waiting[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		name     string
+		duration time.Duration
+		err      error
+	}
+	results := make(chan result)
+
+	var timings []SeederTiming
+	var firstErr error
+	running := 0
+	for {
+		for firstErr == nil && running < n && len(ready) > 0 {
+			if err := ctx.Err(); err != nil {
+				firstErr = err
+				break
+			}
+			name := ready[0]
+			ready = ready[1:]
+			s := all[name]
+			if rec, ok := ran[name]; ok && !r.force && rec.Version == versionOf(s) {
+				r.logInfo("Seeder %s already ran, skipping", name)
+				complete(name)
+				continue
+			}
+			running++
+			go func() {
+				start := time.Now()
+				err := r.runOne(ctx, name, s, batch)
+				results <- result{name: name, duration: time.Since(start), err: err}
+			}()
+		}
+		if running == 0 {
+			return timings, firstErr
+		}
+
+		res := <-results
+		running--
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = res.err
+				cancel()
+			}
+			continue
+		}
+		timings = append(timings, SeederTiming{Name: res.name, Duration: res.duration})
+		complete(res.name)
+	}
+}
+
+// nextBatch returns the seeders recorded by the tracker, keyed by name, and
+// the number of the batch to record new runs in. Without a tracker it
+// returns nothing.
+func (r *Runner) nextBatch(ctx context.Context) (map[string]SeederRecord, int, error) {
+	if r.tracker == nil {
+		return nil, 0, nil
+	}
+	if err := r.tracker.EnsureTableContext(ctx); err != nil {
+		return nil, 0, err
+	}
+	records, err := r.tracker.GetRanContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	last, err := r.tracker.GetLastBatchNumberContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ran := make(map[string]SeederRecord, len(records))
+	for _, rec := range records {
+		ran[rec.Name] = rec
+	}
+	return ran, last + 1, nil
+}
+
+// versionOf returns the Version of a VersionedSeeder, or the empty string.
+func versionOf(s Seeder) string {
+	if vs, ok := s.(VersionedSeeder); ok {
+		return vs.Version()
+	}
+	return ""
+}
+
+// runSeeder calls RunTx with exec for seeders implementing TxSeeder,
+// RunContext for seeders implementing ContextSeeder and falls back to Run
+// otherwise.
+func runSeeder(ctx context.Context, s Seeder, db *sql.DB, exec Executor) error {
+	if ts, ok := s.(TxSeeder); ok {
+		return ts.RunTx(ctx, exec)
+	}
+	if cs, ok := s.(ContextSeeder); ok {
+		return cs.RunContext(ctx, db)
+	}
+	return s.Run(db)
+}
+
 // Rollback executes the named seeder's Rollback method.
 // Returns an error if the seeder is not found or does not implement RollbackableSeeder.
+// With a tracker, the seeder's record is removed so that it runs again.
 func (r *Runner) Rollback(name string) error {
+	return r.RollbackContext(context.Background(), name)
+}
+
+// RollbackContext is like Rollback but updates the tracker with ctx.
+func (r *Runner) RollbackContext(ctx context.Context, name string) error {
 	s, err := r.registry.Get(name)
 	if err != nil {
 		return err
@@ -138,21 +465,145 @@ func (r *Runner) Rollback(name string) error {
 	if !ok {
 		return fmt.Errorf("seeder %q does not support rollback", name)
 	}
+	return r.rollback(ctx, name, rs)
+}
+
+// RollbackLastBatch rolls back the seeders of the last batch recorded by
+// the tracker, in reverse dependency order. Every seeder of the batch must
+// implement RollbackableSeeder. Returns ErrTrackingDisabled without a
+// tracker.
+func (r *Runner) RollbackLastBatch() error {
+	return r.RollbackLastBatchContext(context.Background())
+}
 
+// RollbackLastBatchContext is like RollbackLastBatch but stops before the
+// next seeder once ctx is cancelled.
+func (r *Runner) RollbackLastBatchContext(ctx context.Context) error {
+	if r.tracker == nil {
+		return ErrTrackingDisabled
+	}
+	if err := r.tracker.EnsureTableContext(ctx); err != nil {
+		return err
+	}
+	last, err := r.tracker.GetLastBatchNumberContext(ctx)
+	if err != nil || last == 0 {
+		return err
+	}
+	records, err := r.tracker.GetByBatchContext(ctx, last)
+	if err != nil {
+		return err
+	}
+
+	// Check the whole batch before rolling anything back.
+	all := r.registry.GetAll()
+	batch := make(map[string]RollbackableSeeder, len(records))
+	for _, rec := range records {
+		s, ok := all[rec.Name]
+		if !ok {
+			return fmt.Errorf("seeder name %q: %w", rec.Name, ErrSeederNotFound)
+		}
+		rs, ok := s.(RollbackableSeeder)
+		if !ok {
+			return fmt.Errorf("seeder %q does not support rollback", rec.Name)
+		}
+		batch[rec.Name] = rs
+	}
+
+	order, err := r.resolveAllOrder(all)
+	if err != nil {
+		return err
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		rs, ok := batch[order[i]]
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.rollback(ctx, order[i], rs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollback rolls back one seeder and removes its tracker record.
+func (r *Runner) rollback(ctx context.Context, name string, rs RollbackableSeeder) error {
 	r.logInfo("Rolling back seeder: %s", name)
 	if err := rs.Rollback(r.db); err != nil {
 		r.logError("Seeder %s rollback failed: %v", name, err)
-		return fmt.Errorf("seeder %q rollback: %w", name, err)
+		return &SeederError{Seeder: name, Rollback: true, Cause: err}
+	}
+	if r.tracker != nil {
+		if err := r.tracker.RemoveContext(ctx, name); err != nil {
+			return err
+		}
 	}
 	r.logInfo("Seeder %s rolled back", name)
 	return nil
 }
 
+// SeederStatus is the state of a registered seeder in the tracker.
+type SeederStatus struct {
+	Name  string
+	Ran   bool
+	Batch int
+	RanAt *time.Time
+	// Version is the version recorded when the seeder ran.
+	Version string
+	// Changed is true when the seeder's current Version differs from the
+	// recorded one; db:seed runs it again.
+	Changed bool
+}
+
+// Status returns the state of every registered seeder in dependency
+// order. Returns ErrTrackingDisabled without a tracker.
+func (r *Runner) Status() ([]SeederStatus, error) {
+	return r.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but executes with ctx.
+func (r *Runner) StatusContext(ctx context.Context) ([]SeederStatus, error) {
+	if r.tracker == nil {
+		return nil, ErrTrackingDisabled
+	}
+	ran, _, err := r.nextBatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	all := r.registry.GetAll()
+	order, err := r.resolveAllOrder(all)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]SeederStatus, len(order))
+	for i, name := range order {
+		statuses[i] = SeederStatus{Name: name}
+		if rec, ok := ran[name]; ok {
+			ranAt := rec.RanAt
+			statuses[i].Ran = true
+			statuses[i].Batch = rec.Batch
+			statuses[i].RanAt = &ranAt
+			statuses[i].Version = rec.Version
+			statuses[i].Changed = rec.Version != versionOf(all[name])
+		}
+	}
+	return statuses, nil
+}
+
 // Truncate deletes all rows from the specified table.
 // Uses DELETE FROM for broad database compatibility (including SQLite).
 func (r *Runner) Truncate(table string) error {
+	return r.TruncateContext(context.Background(), table)
+}
+
+// TruncateContext is like Truncate but executes the statement with ctx.
+func (r *Runner) TruncateContext(ctx context.Context, table string) error {
 	query := fmt.Sprintf(`DELETE FROM "%s"`, table)
-	_, err := r.db.Exec(query)
+	_, err := r.db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("truncate table %q: %w", table, err)
 	}
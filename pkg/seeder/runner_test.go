@@ -1,10 +1,13 @@
 package seeder
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -48,6 +51,25 @@ func (s *failingSeeder) Run(db *sql.DB) error {
 	return s.err
 }
 
+// cancellingSeeder implements ContextSeeder and cancels the run once invoked.
+type cancellingSeeder struct {
+	name   string
+	order  *[]string
+	cancel context.CancelFunc
+	gotCtx context.Context
+}
+
+func (s *cancellingSeeder) Run(db *sql.DB) error {
+	return errors.New("Run called instead of RunContext")
+}
+
+func (s *cancellingSeeder) RunContext(ctx context.Context, db *sql.DB) error {
+	s.gotCtx = ctx
+	*s.order = append(*s.order, s.name)
+	s.cancel()
+	return nil
+}
+
 // testLogger captures log messages for verification.
 type testLogger struct {
 	infos  []string
@@ -99,6 +121,26 @@ func TestRunAllNoDependencies(t *testing.T) {
 	assert.Contains(t, order, "comments")
 }
 
+func TestRunAllContext_StopsAfterCancellation(t *testing.T) {
+	reg := NewRegistry()
+	db, _ := newTestDB(t)
+	var order []string
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &cancellingSeeder{name: "a_first", order: &order, cancel: cancel}
+	require.NoError(t, reg.Register("a_first", first))
+	require.NoError(t, reg.Register("b_second", &trackingSeeder{name: "b_second", order: &order}))
+
+	runner := NewRunner(reg, db, nil)
+	err := runner.RunAllContext(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"a_first"}, order)
+	assert.Equal(t, ctx, first.gotCtx)
+}
+
 func TestRunAllWithDependencies(t *testing.T) {
 	reg := NewRegistry()
 	db, _ := newTestDB(t)
@@ -352,3 +394,230 @@ func indexOf(slice []string, s string) int {
 	}
 	return -1
 }
+
+// insertSeeder inserts a row into items and then fails with err, if set.
+type insertSeeder struct {
+	name string
+	deps []string
+	err  error
+}
+
+func (s *insertSeeder) Run(db *sql.DB) error { return s.RunTx(context.Background(), db) }
+
+func (s *insertSeeder) RunTx(ctx context.Context, exec Executor) error {
+	if err := CreateManyContext(ctx, exec, "items", []map[string]any{{"name": s.name}}, 0); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func (s *insertSeeder) DependsOn() []string { return s.deps }
+
+func openItemsDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db := openSQLite(t)
+	_, err := db.Exec(`CREATE TABLE items (name TEXT NOT NULL)`)
+	require.NoError(t, err)
+	return db
+}
+
+func itemNames(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM items ORDER BY name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	return names
+}
+
+func TestRunner_Transactions(t *testing.T) {
+	seedErr := errors.New("seed failed")
+	tests := []struct {
+		mode  TransactionMode
+		items []string
+		ran   []string
+	}{
+		{TxNone, []string{"posts", "users"}, []string{"users"}},
+		{TxPerSeeder, []string{"users"}, []string{"users"}},
+		{TxAll, nil, nil},
+	}
+
+	for _, tt := range tests {
+		db := openItemsDB(t)
+		reg := NewRegistry()
+		require.NoError(t, reg.Register("users", &insertSeeder{name: "users"}))
+		require.NoError(t, reg.Register("posts", &insertSeeder{name: "posts", deps: []string{"users"}, err: seedErr}))
+		tracker := NewTrackerWithDialect(db, "seeders", DialectSQLite)
+
+		err := NewRunner(reg, db, nil, WithDialect(DialectSQLite), WithTracker(tracker), WithTransactions(tt.mode)).RunAll()
+		assert.ErrorIs(t, err, seedErr)
+		assert.Equal(t, tt.items, itemNames(t, db), "mode %d", tt.mode)
+
+		records, err := tracker.GetRan()
+		require.NoError(t, err)
+		var ran []string
+		for _, rec := range records {
+			ran = append(ran, rec.Name)
+		}
+		assert.Equal(t, tt.ran, ran, "mode %d", tt.mode)
+	}
+}
+
+func TestRunner_TxAllRequiresTxSeeders(t *testing.T) {
+	db := openItemsDB(t)
+	reg := NewRegistry()
+	require.NoError(t, reg.Register("users", &insertSeeder{name: "users"}))
+	require.NoError(t, reg.Register("legacy", &failingSeeder{name: "legacy", err: errors.New("must not run")}))
+
+	err := NewRunner(reg, db, nil, WithDialect(DialectSQLite), WithTransactions(TxAll)).RunAll()
+	assert.ErrorContains(t, err, `seeder "legacy" does not implement TxSeeder`)
+	assert.Empty(t, itemNames(t, db), "nothing runs when a seeder cannot join the transaction")
+}
+
+func TestRunner_TxPerSeederRunsLegacySeeders(t *testing.T) {
+	db := openItemsDB(t)
+	var order []string
+	reg := NewRegistry()
+	require.NoError(t, reg.Register("users", &insertSeeder{name: "users"}))
+	require.NoError(t, reg.Register("legacy", &dependentTrackingSeeder{name: "legacy", deps: []string{"users"}, order: &order}))
+
+	require.NoError(t, NewRunner(reg, db, nil, WithDialect(DialectSQLite), WithTransactions(TxPerSeeder)).RunAll())
+	assert.Equal(t, []string{"users"}, itemNames(t, db))
+	assert.Equal(t, []string{"legacy"}, order)
+}
+
+func TestParseTransactionMode(t *testing.T) {
+	for in, want := range map[string]TransactionMode{"": TxNone, "none": TxNone, "seeder": TxPerSeeder, "all": TxAll} {
+		mode, err := ParseTransactionMode(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, mode)
+	}
+	_, err := ParseTransactionMode("always")
+	assert.ErrorContains(t, err, `invalid transaction mode "always"`)
+}
+
+// parallelSeeder tracks how many seeders run at once and records when it
+// completes. It fails with err, if set, and waits for ctx otherwise when
+// block is set.
+type parallelSeeder struct {
+	name    string
+	deps    []string
+	err     error
+	block   bool
+	mu      *sync.Mutex
+	active  *int
+	maxSeen *int
+	order   *[]string
+}
+
+func (s *parallelSeeder) Run(*sql.DB) error { return errors.New("Run must not be called") }
+
+func (s *parallelSeeder) RunContext(ctx context.Context, _ *sql.DB) error {
+	s.mu.Lock()
+	*s.active++
+	if *s.active > *s.maxSeen {
+		*s.maxSeen = *s.active
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		*s.active--
+		s.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if s.err != nil {
+		return s.err
+	}
+	if s.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	*s.order = append(*s.order, s.name)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parallelSeeder) DependsOn() []string { return s.deps }
+
+type parallelFixture struct {
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	order   []string
+	reg     *Registry
+}
+
+func newParallelFixture() *parallelFixture {
+	return &parallelFixture{reg: NewRegistry()}
+}
+
+func (f *parallelFixture) add(t *testing.T, s *parallelSeeder) {
+	t.Helper()
+	s.mu, s.active, s.maxSeen, s.order = &f.mu, &f.active, &f.maxSeen, &f.order
+	require.NoError(t, f.reg.Register(s.name, s))
+}
+
+func TestRunner_RunAllParallel(t *testing.T) {
+	f := newParallelFixture()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		f.add(t, &parallelSeeder{name: name})
+	}
+	f.add(t, &parallelSeeder{name: "z", deps: []string{"a", "b", "c", "d", "e"}})
+
+	timings, err := NewRunner(f.reg, nil, nil).RunAllParallel(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.maxSeen, "at most n seeders run at a time")
+	require.Len(t, f.order, 6)
+	assert.Equal(t, "z", f.order[5], "a seeder starts after its dependencies completed")
+	require.Len(t, timings, 6)
+	for _, timing := range timings {
+		assert.GreaterOrEqual(t, timing.Duration, 10*time.Millisecond)
+	}
+	assert.Equal(t, "z", timings[5].Name)
+}
+
+func TestRunner_RunAllParallelCancelsOnFailure(t *testing.T) {
+	f := newParallelFixture()
+	seedErr := errors.New("seed failed")
+	f.add(t, &parallelSeeder{name: "a", err: seedErr})
+	f.add(t, &parallelSeeder{name: "b", block: true})
+	f.add(t, &parallelSeeder{name: "c", deps: []string{"b"}})
+
+	timings, err := NewRunner(f.reg, nil, nil).RunAllParallel(2)
+	assert.ErrorIs(t, err, seedErr)
+	var se *SeederError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, "a", se.Seeder, "the first failure is returned")
+	assert.Empty(t, timings)
+	assert.Empty(t, f.order, "in-flight seeders are cancelled and no further seeders start")
+}
+
+func TestRunner_RunAllParallelSkipsSeedersThatRan(t *testing.T) {
+	db := openSQLite(t)
+	f := newParallelFixture()
+	f.add(t, &parallelSeeder{name: "users"})
+	f.add(t, &parallelSeeder{name: "posts", deps: []string{"users"}})
+	tracker := NewTrackerWithDialect(db, "seeders", DialectSQLite)
+
+	require.NoError(t, NewRunner(f.reg, db, nil, WithTracker(tracker)).Run("users"))
+	f.order = nil
+
+	timings, err := NewRunner(f.reg, db, nil, WithTracker(tracker)).RunAllParallel(4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"posts"}, f.order)
+	require.Len(t, timings, 1)
+	assert.Equal(t, "posts", timings[0].Name)
+}
+
+func TestRunner_RunAllParallelRejectsTxAll(t *testing.T) {
+	_, err := NewRunner(NewRegistry(), nil, nil, WithTransactions(TxAll)).RunAllParallel(2)
+	assert.ErrorContains(t, err, "cannot run in parallel in a single transaction")
+}
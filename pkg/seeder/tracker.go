@@ -0,0 +1,208 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SeederRecord represents a single row in the seeder tracking table.
+// Version is the Version of a VersionedSeeder when it ran, or empty.
+type SeederRecord struct {
+	Name    string
+	Batch   int
+	RanAt   time.Time
+	Version string
+}
+
+// Tracker manages the seeder tracking table, which records the seeders a
+// Runner has run so that they are not run again.
+type Tracker struct {
+	db        Executor
+	tableName string
+	dialect   Dialect
+}
+
+// NewTracker creates a Tracker that stores records in the specified table,
+// using PostgreSQL SQL.
+func NewTracker(db *sql.DB, tableName string) *Tracker {
+	return NewTrackerWithDialect(db, tableName, DialectPostgres)
+}
+
+// NewTrackerWithDialect creates a Tracker that generates its SQL with the
+// given dialect.
+func NewTrackerWithDialect(db *sql.DB, tableName string, dialect Dialect) *Tracker {
+	return &Tracker{
+		db:        db,
+		tableName: tableName,
+		dialect:   dialect,
+	}
+}
+
+// on returns a copy of t that executes through exec, such as the
+// transaction a seeder runs in. It returns nil for a nil t.
+func (t *Tracker) on(exec Executor) *Tracker {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.db = exec
+	return &c
+}
+
+// table returns the quoted tracking table name.
+func (t *Tracker) table() string {
+	return t.dialect.quoteIdent(t.tableName)
+}
+
+// EnsureTable creates the seeder tracking table if it does not already exist.
+func (t *Tracker) EnsureTable() error {
+	return t.EnsureTableContext(context.Background())
+}
+
+// EnsureTableContext is like EnsureTable but executes with ctx.
+func (t *Tracker) EnsureTableContext(ctx context.Context) error {
+	id := "SERIAL PRIMARY KEY"
+	switch t.dialect {
+	case DialectMySQL:
+		id = "INT UNSIGNED AUTO_INCREMENT PRIMARY KEY"
+	case DialectSQLite:
+		id = "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id      %s,
+		seeder  VARCHAR(255) NOT NULL UNIQUE,
+		batch   INTEGER NOT NULL,
+		version VARCHAR(64),
+		ran_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`, t.table(), id)
+
+	if _, err := t.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ensure seeder table %q: %w", t.tableName, ErrTrackingTable)
+	}
+	return nil
+}
+
+// GetRan returns all seeder records ordered by name ascending.
+func (t *Tracker) GetRan() ([]SeederRecord, error) {
+	return t.GetRanContext(context.Background())
+}
+
+// GetRanContext is like GetRan but executes with ctx.
+func (t *Tracker) GetRanContext(ctx context.Context) ([]SeederRecord, error) {
+	query := fmt.Sprintf(`SELECT seeder, batch, version, ran_at FROM %s ORDER BY seeder ASC`, t.table())
+	return t.query(ctx, query)
+}
+
+// GetByBatchContext returns the seeder records of the given batch, ordered
+// by name ascending.
+func (t *Tracker) GetByBatchContext(ctx context.Context, batch int) ([]SeederRecord, error) {
+	query := fmt.Sprintf(
+		`SELECT seeder, batch, version, ran_at FROM %s WHERE batch = %s ORDER BY seeder ASC`,
+		t.table(), t.dialect.placeholder(1),
+	)
+	return t.query(ctx, query, batch)
+}
+
+// GetLastBatchNumberContext returns the highest batch number in the
+// tracking table, or 0 if no records exist.
+func (t *Tracker) GetLastBatchNumberContext(ctx context.Context) (int, error) {
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(batch), 0) FROM %s`, t.table())
+
+	var batch int
+	if err := t.db.QueryRowContext(ctx, query).Scan(&batch); err != nil {
+		return 0, fmt.Errorf("get last seeder batch: %w", ErrTrackingTable)
+	}
+	return batch, nil
+}
+
+// RecordContext records that the named seeder ran in batch, replacing an
+// earlier record of it. An empty version is stored as NULL.
+func (t *Tracker) RecordContext(ctx context.Context, name string, batch int, version string) error {
+	if err := t.RemoveContext(ctx, name); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(
+		`INSERT INTO %s (seeder, batch, version) VALUES (%s, %s, %s)`,
+		t.table(), t.dialect.placeholder(1), t.dialect.placeholder(2), t.dialect.placeholder(3),
+	)
+
+	v := sql.NullString{String: version, Valid: version != ""}
+	if _, err := t.db.ExecContext(ctx, query, name, batch, v); err != nil {
+		return fmt.Errorf("record seeder %q: %w", name, ErrTrackingTable)
+	}
+	return nil
+}
+
+// RemoveContext deletes the record of the named seeder.
+func (t *Tracker) RemoveContext(ctx context.Context, name string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE seeder = %s`, t.table(), t.dialect.placeholder(1))
+
+	if _, err := t.db.ExecContext(ctx, query, name); err != nil {
+		return fmt.Errorf("remove seeder %q: %w", name, ErrTrackingTable)
+	}
+	return nil
+}
+
+// query runs a SELECT of seeder, batch, version, ran_at.
+func (t *Tracker) query(ctx context.Context, query string, args ...any) ([]SeederRecord, error) {
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get seeder records: %w", ErrTrackingTable)
+	}
+	defer rows.Close()
+
+	var records []SeederRecord
+	for rows.Next() {
+		var r SeederRecord
+		var version sql.NullString
+		var ranAt any
+		if err := rows.Scan(&r.Name, &r.Batch, &version, &ranAt); err != nil {
+			return nil, fmt.Errorf("scan seeder record: %w", ErrTrackingTable)
+		}
+		if r.RanAt, err = parseRanAt(ranAt); err != nil {
+			return nil, fmt.Errorf("scan seeder record %q: %v: %w", r.Name, err, ErrTrackingTable)
+		}
+		r.Version = version.String
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate seeder records: %w", ErrTrackingTable)
+	}
+	return records, nil
+}
+
+// ranAtLayouts are the textual timestamp formats drivers return for the
+// ran_at column.
+var ranAtLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
+
+// parseRanAt converts a scanned ran_at value to time.Time. Drivers differ
+// in how they return timestamps (MySQL returns text unless parseTime=true
+// is set in the DSN).
+func parseRanAt(v any) (time.Time, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case nil:
+		return time.Time{}, nil
+	case []byte:
+		return parseRanAt(string(x))
+	case string:
+		for _, layout := range ranAtLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognized timestamp %q", x)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", v)
+	}
+}
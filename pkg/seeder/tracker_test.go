@@ -0,0 +1,136 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "seeders.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// rollbackSeeder records its runs and rollbacks in a shared log.
+type rollbackSeeder struct {
+	name    string
+	deps    []string
+	version string
+	log     *[]string
+}
+
+func (s *rollbackSeeder) Run(*sql.DB) error {
+	*s.log = append(*s.log, "run "+s.name)
+	return nil
+}
+
+func (s *rollbackSeeder) Rollback(*sql.DB) error {
+	*s.log = append(*s.log, "rollback "+s.name)
+	return nil
+}
+
+func (s *rollbackSeeder) DependsOn() []string { return s.deps }
+
+func (s *rollbackSeeder) Version() string { return s.version }
+
+func TestTracker_Lifecycle(t *testing.T) {
+	db := openSQLite(t)
+	tracker := NewTrackerWithDialect(db, "seeders", DialectSQLite)
+	require.NoError(t, tracker.EnsureTable())
+	require.NoError(t, tracker.EnsureTable(), "EnsureTable is idempotent")
+
+	ctx := context.Background()
+	require.NoError(t, tracker.RecordContext(ctx, "users", 1, "v1"))
+	require.NoError(t, tracker.RecordContext(ctx, "posts", 1, ""))
+	require.NoError(t, tracker.RecordContext(ctx, "users", 2, "v2"))
+
+	records, err := tracker.GetRan()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "posts", records[0].Name)
+	assert.Empty(t, records[0].Version)
+	assert.Equal(t, "users", records[1].Name)
+	assert.Equal(t, 2, records[1].Batch, "recording again replaces the record")
+	assert.Equal(t, "v2", records[1].Version)
+	assert.False(t, records[1].RanAt.IsZero())
+
+	last, err := tracker.GetLastBatchNumberContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, last)
+
+	require.NoError(t, tracker.RemoveContext(ctx, "users"))
+	records, err = tracker.GetByBatchContext(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestRunner_TrackerSkipsSeedersThatRan(t *testing.T) {
+	db := openSQLite(t)
+	var log []string
+	users := &rollbackSeeder{name: "users", version: "v1", log: &log}
+	reg := NewRegistry()
+	require.NoError(t, reg.Register("users", users))
+	require.NoError(t, reg.Register("posts", &rollbackSeeder{name: "posts", deps: []string{"users"}, log: &log}))
+	tracker := NewTrackerWithDialect(db, "seeders", DialectSQLite)
+
+	require.NoError(t, NewRunner(reg, db, nil, WithTracker(tracker)).RunAll())
+	assert.Equal(t, []string{"run users", "run posts"}, log)
+
+	log = nil
+	require.NoError(t, NewRunner(reg, db, nil, WithTracker(tracker)).RunAll())
+	assert.Empty(t, log, "seeders that already ran are skipped")
+
+	users.version = "v2"
+	require.NoError(t, NewRunner(reg, db, nil, WithTracker(tracker)).RunAll())
+	assert.Equal(t, []string{"run users"}, log, "a changed version runs again")
+
+	log = nil
+	require.NoError(t, NewRunner(reg, db, nil, WithTracker(tracker), WithForce(true)).RunAll())
+	assert.Equal(t, []string{"run users", "run posts"}, log)
+}
+
+func TestRunner_RollbackLastBatch(t *testing.T) {
+	db := openSQLite(t)
+	var log []string
+	reg := NewRegistry()
+	require.NoError(t, reg.Register("roles", &rollbackSeeder{name: "roles", log: &log}))
+	require.NoError(t, reg.Register("users", &rollbackSeeder{name: "users", deps: []string{"roles"}, log: &log}))
+	require.NoError(t, reg.Register("posts", &rollbackSeeder{name: "posts", deps: []string{"users"}, log: &log}))
+	runner := NewRunner(reg, db, nil, WithTracker(NewTrackerWithDialect(db, "seeders", DialectSQLite)))
+
+	require.NoError(t, runner.Run("roles"))
+	require.NoError(t, runner.RunAll())
+
+	log = nil
+	require.NoError(t, runner.RollbackLastBatch())
+	assert.Equal(t, []string{"rollback posts", "rollback users"}, log, "the last batch is rolled back in reverse dependency order")
+
+	statuses, err := runner.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "roles", statuses[0].Name)
+	assert.True(t, statuses[0].Ran)
+	assert.Equal(t, 1, statuses[0].Batch)
+	assert.False(t, statuses[1].Ran)
+	assert.False(t, statuses[2].Ran)
+
+	log = nil
+	require.NoError(t, runner.RunAll())
+	assert.Equal(t, []string{"run users", "run posts"}, log)
+}
+
+func TestRunner_TrackingDisabled(t *testing.T) {
+	runner := NewRunner(NewRegistry(), nil, nil)
+
+	assert.ErrorIs(t, runner.RollbackLastBatch(), ErrTrackingDisabled)
+	_, err := runner.Status()
+	assert.ErrorIs(t, err, ErrTrackingDisabled)
+}